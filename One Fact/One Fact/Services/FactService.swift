@@ -89,7 +89,11 @@ class FactService: ObservableObject {
             return cachedFact.fact
         }
         
-        guard let url = URL(string: "\(baseURL)/daily") else {
+        // The server picks the fact for the date in the user's time zone
+        var urlComponents = URLComponents(string: "\(baseURL)/daily")
+        urlComponents?.queryItems = [URLQueryItem(name: "tz", value: TimeZone.current.identifier)]
+
+        guard let url = urlComponents?.url else {
             throw APIError.invalidURL
         }
         
@@ -0,0 +1,97 @@
+import React, { useEffect, useState } from 'react';
+import { Card, CardContent, Chip, Stack, Typography, Box } from '@mui/material';
+import { api } from '../services/api';
+import { CollectionEvent, COLLECTION_EVENT_TYPES, SourceStats } from '../types/collection';
+
+const MAX_LOG_LINES = 50;
+
+interface CollectionLogProps {
+  runId: string;
+}
+
+const describe = (event: CollectionEvent): string => {
+  switch (event.type) {
+    case 'run_started':
+      return 'Run started';
+    case 'source_started':
+      return `${event.source}: started`;
+    case 'page_fetched':
+      return `${event.source}: fetched ${event.title}${event.error ? ` (${event.error})` : ''}`;
+    case 'source_fetched':
+      return `${event.source}: ${event.count} candidate facts`;
+    case 'fact_accepted':
+      return `Accepted ${event.title}`;
+    case 'fact_rejected':
+      return `Rejected ${event.title || 'fact'}: ${event.reason}`;
+    case 'fact_stored':
+      return `Stored ${event.title}`;
+    case 'fact_duplicate':
+      return `Skipped duplicate ${event.title}`;
+    case 'source_failed':
+    case 'store_failed':
+      return `${event.source}: ${event.error}`;
+    case 'batch_written':
+      return `Wrote ${event.batch?.size} facts in ${event.batch?.duration_ms} ms (${event.batch?.inserted} new, ${event.batch?.duplicates} duplicates, ${event.batch?.failed} failed)`;
+    case 'inventory_alert':
+      return `${event.inventory?.category} is running low: ${event.inventory?.available} facts available, ${event.inventory?.days_left?.toFixed(1)} days left`;
+    case 'events_dropped':
+      return `${event.count} events missed`;
+    case 'run_finished':
+      return `Run ${event.run?.status}`;
+    default:
+      return event.type;
+  }
+};
+
+export const CollectionLog: React.FC<CollectionLogProps> = ({ runId }) => {
+  const [lines, setLines] = useState<string[]>([]);
+  const [stats, setStats] = useState<Record<string, SourceStats>>({});
+
+  useEffect(() => {
+    setLines([]);
+    setStats({});
+
+    const source = api.collectionEvents(runId);
+    const onEvent = (message: MessageEvent) => {
+      const event: CollectionEvent = JSON.parse(message.data);
+      setLines((prev) => [describe(event), ...prev].slice(0, MAX_LOG_LINES));
+      if (event.stats) {
+        setStats((prev) => ({ ...prev, [event.stats!.source]: event.stats! }));
+      }
+      if (event.type === 'run_finished') {
+        source.close();
+      }
+    };
+    COLLECTION_EVENT_TYPES.forEach((type) => source.addEventListener(type, onEvent));
+
+    return () => source.close();
+  }, [runId]);
+
+  return (
+    <Card sx={{ mb: 4 }}>
+      <CardContent>
+        <Typography variant="h6" color="text.secondary" gutterBottom>
+          Collection Progress
+        </Typography>
+        {Object.values(stats).map((s) => (
+          <Stack key={s.source} direction="row" spacing={1} sx={{ mb: 1 }} alignItems="center">
+            <Typography variant="subtitle2" sx={{ minWidth: 100 }}>{s.source}</Typography>
+            <Chip size="small" label={`${s.fetched} fetched`} />
+            <Chip size="small" color="success" label={`${s.inserted} stored`} />
+            <Chip size="small" label={`${s.duplicates} duplicates`} />
+            <Chip
+              size="small"
+              color="warning"
+              label={`${Object.values(s.rejected ?? {}).reduce((a, b) => a + b, 0)} rejected`}
+            />
+          </Stack>
+        ))}
+        <Box sx={{ maxHeight: 240, overflowY: 'auto', fontFamily: 'monospace', fontSize: 13 }}>
+          {lines.map((line, i) => (
+            <div key={i}>{line}</div>
+          ))}
+        </Box>
+      </CardContent>
+    </Card>
+  );
+};
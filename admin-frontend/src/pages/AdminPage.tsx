@@ -1,4 +1,5 @@
 import React, { useState, useEffect } from 'react';
+import axios from 'axios';
 import {
   Container,
   Typography,
@@ -24,12 +25,17 @@ import {
   Add as AddIcon,
   Refresh as RefreshIcon,
   CloudDownload as CloudDownloadIcon,
+  Cancel as CancelIcon,
   Search as SearchIcon,
 } from '@mui/icons-material';
 import { FactList } from '../components/FactList';
 import { FactForm } from '../components/FactForm';
+import { CollectionLog } from '../components/CollectionLog';
 import { api } from '../services/api';
 import { Fact, CATEGORIES } from '../types/fact';
+import { CollectionRun } from '../types/collection';
+
+const COLLECTION_POLL_MS = 2000;
 
 export const AdminPage: React.FC = () => {
   const [facts, setFacts] = useState<Fact[]>([]);
@@ -44,6 +50,7 @@ export const AdminPage: React.FC = () => {
     severity: 'success' | 'error';
   }>({ open: false, message: '', severity: 'success' });
   const [loading, setLoading] = useState(false);
+  const [collectionRun, setCollectionRun] = useState<CollectionRun | null>(null);
   const [stats, setStats] = useState({
     total: 0,
     verified: 0,
@@ -140,18 +147,53 @@ export const AdminPage: React.FC = () => {
 
   const handleCollectFacts = async () => {
     try {
-      setLoading(true);
-      await api.collectFacts();
+      setCollectionRun(await api.collectFacts());
       showSuccess('Fact collection started');
-      setTimeout(loadFacts, 2000); // Reload after 2 seconds
     } catch (error) {
       console.error('Error collecting facts:', error);
-      showError('Error collecting facts');
-    } finally {
-      setLoading(false);
+      if (axios.isAxiosError(error) && error.response?.status === 409) {
+        showError('A fact collection is already running');
+      } else {
+        showError('Error collecting facts');
+      }
+    }
+  };
+
+  const handleCancelCollection = async () => {
+    if (!collectionRun) return;
+    try {
+      setCollectionRun(await api.cancelCollection(collectionRun.id));
+    } catch (error) {
+      console.error('Error cancelling collection:', error);
+      showError('Error cancelling collection');
     }
   };
 
+  // Poll the running collection job until it finishes
+  useEffect(() => {
+    if (!collectionRun || collectionRun.status !== 'running') return;
+
+    const timer = setTimeout(async () => {
+      try {
+        const run = await api.getCollectionRun(collectionRun.id);
+        setCollectionRun(run);
+        if (run.status === 'running') return;
+
+        const inserted = run.sources.reduce((sum, s) => sum + s.inserted, 0);
+        if (run.status === 'succeeded') {
+          showSuccess(`Fact collection finished: ${inserted} new facts`);
+        } else {
+          showError(`Fact collection ${run.status}: ${inserted} new facts`);
+        }
+        loadFacts();
+      } catch (error) {
+        console.error('Error checking collection:', error);
+        setCollectionRun(null);
+      }
+    }, COLLECTION_POLL_MS);
+    return () => clearTimeout(timer);
+  }, [collectionRun]);
+
   const showSuccess = (message: string) => {
     setSnackbar({ open: true, message, severity: 'success' });
   };
@@ -171,14 +213,25 @@ export const AdminPage: React.FC = () => {
           <Typography variant="h6" component="div" sx={{ flexGrow: 1 }}>
             One Fact Admin
           </Typography>
-          <Button 
-            color="inherit" 
-            startIcon={<CloudDownloadIcon />}
-            onClick={handleCollectFacts}
-            disabled={loading}
-          >
-            Collect Facts
-          </Button>
+          {collectionRun?.status === 'running' ? (
+            <Button
+              color="inherit"
+              startIcon={<CancelIcon />}
+              onClick={handleCancelCollection}
+              disabled={collectionRun.cancel_requested}
+            >
+              Cancel Collection ({collectionRun.sources.reduce((sum, s) => sum + s.inserted, 0)} new)
+            </Button>
+          ) : (
+            <Button 
+              color="inherit" 
+              startIcon={<CloudDownloadIcon />}
+              onClick={handleCollectFacts}
+              disabled={loading}
+            >
+              Collect Facts
+            </Button>
+          )}
         </Toolbar>
       </AppBar>
 
@@ -190,6 +243,8 @@ export const AdminPage: React.FC = () => {
           height: '100%',
         }}
       >
+        {collectionRun && <CollectionLog runId={collectionRun.id} />}
+
         {/* Stats Cards */}
         <Grid container spacing={3} sx={{ mb: 4 }}>
           <Grid item xs={12} md={4}>
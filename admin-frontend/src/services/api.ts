@@ -1,5 +1,6 @@
 import axios from 'axios';
 import { Fact } from '../types/fact';
+import { CollectionRun } from '../types/collection';
 
 const API_URL = import.meta.env.VITE_API_BASE_URL;
 
@@ -34,9 +35,25 @@ export const api = {
     return response.data;
   },
 
-  // Trigger fact collection
-  collectFacts: async () => {
+  // Start a collection job; fails with 409 while one is running
+  collectFacts: async (): Promise<CollectionRun> => {
     const response = await axios.post(`${API_URL}/facts/collect`);
     return response.data;
   },
+
+  // Get the progress of a collection job
+  getCollectionRun: async (id: string): Promise<CollectionRun> => {
+    const response = await axios.get(`${API_URL}/facts/collect/${id}`);
+    return response.data;
+  },
+
+  // Stream the progress events of a collection run
+  collectionEvents: (runId: string) =>
+    new EventSource(`${API_URL}/facts/collect/events?run=${runId}`),
+
+  // Cancel a running collection job
+  cancelCollection: async (id: string): Promise<CollectionRun> => {
+    const response = await axios.delete(`${API_URL}/facts/collect/${id}`);
+    return response.data;
+  },
 };
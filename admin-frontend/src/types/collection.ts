@@ -0,0 +1,77 @@
+export interface SourceStats {
+  source: string;
+  fetched: number;
+  rejected?: Record<string, number>;
+  inserted: number;
+  held_for_review: number;
+  duplicates: number;
+  errors?: string[];
+}
+
+export interface CategoryInventory {
+  category: string;
+  available: number;
+  scheduled: number;
+  daily_use: number;
+  days_left?: number;
+  pages: number;
+  alert: boolean;
+}
+
+export interface BatchStats {
+  size: number;
+  inserted: number;
+  duplicates: number;
+  failed: number;
+  attempts: number;
+  duration_ms: number;
+  written_at: string;
+}
+
+export type RunStatus = 'running' | 'succeeded' | 'failed' | 'cancelled';
+
+export interface CollectionRun {
+  id: string;
+  trigger: 'schedule' | 'manual' | 'boot';
+  status: RunStatus;
+  started_at: string;
+  finished_at?: string;
+  sources: SourceStats[];
+  errors?: string[];
+  batches?: BatchStats[];
+  inventory?: CategoryInventory[];
+  heartbeat_at?: string;
+  cancel_requested?: boolean;
+}
+
+export interface CollectionEvent {
+  type: string;
+  run_id: string;
+  source?: string;
+  title?: string;
+  reason?: string;
+  error?: string;
+  count?: number;
+  stats?: SourceStats;
+  run?: CollectionRun;
+  batch?: BatchStats;
+  inventory?: CategoryInventory;
+  time: string;
+}
+
+export const COLLECTION_EVENT_TYPES = [
+  'run_started',
+  'source_started',
+  'page_fetched',
+  'source_fetched',
+  'source_failed',
+  'fact_accepted',
+  'fact_rejected',
+  'fact_stored',
+  'fact_duplicate',
+  'store_failed',
+  'batch_written',
+  'run_finished',
+  'inventory_alert',
+  'events_dropped',
+];
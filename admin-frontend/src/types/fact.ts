@@ -7,6 +7,7 @@ export interface Fact {
   verified: boolean;
   related_urls: string[];
   metadata: {
+    title?: string;
     language: string;
     difficulty: string;
     references: string[];
@@ -14,7 +15,10 @@ export interface Fact {
     popularity: number;
     last_served?: string;
     serve_count: number;
+    chat_opens?: number;
+    shares?: number;
   };
+  score?: number;
   created_at?: string;
   updated_at?: string;
   publish_date?: string;
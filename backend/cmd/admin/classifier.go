@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+)
+
+func runRetrainClassifier(ctx context.Context, cfg *config.Config, db *database.Database, args []string) error {
+	factService := services.NewFactService(db, nil)
+
+	model, err := factService.RetrainClassifier(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Trained category classifier on %d facts across %d categories (%d distinct features)",
+		model.Documents, len(model.Categories), model.Vocabulary)
+	for category, stats := range model.Categories {
+		log.Printf("  %-16s %d facts", category, stats.Documents)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+)
+
+func runCalibrateDifficulty(ctx context.Context, cfg *config.Config, db *database.Database, args []string) error {
+	flags := flag.NewFlagSet("calibrate-difficulty", flag.ExitOnError)
+	reclassify := flags.Bool("reclassify", false, "recompute the difficulty of facts not labeled by an editor")
+	flags.Parse(args)
+
+	factService := services.NewFactService(db, nil)
+
+	thresholds, err := factService.CalibrateDifficulty(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Calibrated on %d facts: medium at %.2f, hard at %.2f (accuracy %.0f%%)",
+		thresholds.SampleSize, thresholds.MediumAt, thresholds.HardAt, thresholds.Accuracy*100)
+
+	if *reclassify {
+		changed, err := factService.ReclassifyDifficulty(ctx, *thresholds)
+		if err != nil {
+			return err
+		}
+		log.Printf("Reclassified %d facts", changed)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scheduler"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+)
+
+func runExplain(ctx context.Context, cfg *config.Config, db *database.Database, args []string) error {
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	var req services.ExplainRequest
+	flags.StringVar(&req.Text, "text", "", "raw fact text to process")
+	flags.StringVar(&req.Title, "title", "", "Wikipedia page title to fetch and process")
+	flags.StringVar(&req.Category, "category", "", "source category of the fact")
+	rewrite := flags.Bool("rewrite", false, "include the LLM rewrite stage")
+	flags.Parse(args)
+
+	if req.Text == "" && req.Title == "" {
+		return errors.New("one of -text or -title is required")
+	}
+
+	// Mirror the server pipeline: the judge runs whenever the AI service is configured
+	aiService := services.NewAIService()
+	sched := scheduler.NewScheduler(db)
+	if *rewrite {
+		sched.EnableRewrite(aiService)
+	}
+	if aiService.Enabled() {
+		sched.EnableVerificationJudge(aiService)
+	}
+
+	factService := services.NewFactService(db, nil)
+	factService.SetScheduler(sched)
+
+	explanation, err := factService.ExplainFact(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(explanation)
+}
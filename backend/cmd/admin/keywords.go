@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+)
+
+func runBackfillKeywords(ctx context.Context, cfg *config.Config, db *database.Database, args []string) error {
+	flags := flag.NewFlagSet("backfill-keywords", flag.ExitOnError)
+	onlyMissing := flags.Bool("only-missing", false, "keep keywords on facts that already have them")
+	flags.Parse(args)
+
+	factService := services.NewFactService(db, nil)
+
+	updated, err := factService.BackfillKeywords(ctx, *onlyMissing)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Recomputed keywords for %d facts", updated)
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/joho/godotenv"
+)
+
+// command is an admin subcommand run against the configured database
+type command struct {
+	description string
+	run         func(ctx context.Context, cfg *config.Config, db *database.Database, args []string) error
+}
+
+var commands = map[string]command{
+	"backfill-keywords": {
+		description: "Rebuild keyword statistics and recompute keywords for stored facts",
+		run:         runBackfillKeywords,
+	},
+	"explain": {
+		description: "Dry-run the processing pipeline on raw text or a Wikipedia title",
+		run:         runExplain,
+	},
+	"migrate-facts": {
+		description: "Rewrite facts stored with an older schema into the current one",
+		run:         runMigrateFacts,
+	},
+	"migrate-storage": {
+		description: "Copy facts and chat sessions between MongoDB and the embedded data file",
+		run:         runMigrateStorage,
+	},
+	"retag-facts": {
+		description: "Remap the tags of stored facts through the tag taxonomy",
+		run:         runRetagFacts,
+	},
+	"retrain-scoring": {
+		description: "Retrain the scoring model from engagement and rescore stored facts",
+		run:         runRetrainScoring,
+	},
+	"retrain-classifier": {
+		description: "Retrain the category classifier from verified facts",
+		run:         runRetrainClassifier,
+	},
+	"calibrate-difficulty": {
+		description: "Recalibrate difficulty thresholds from editor-labeled facts",
+		run:         runCalibrateDifficulty,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Error loading .env file: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	if err := cmd.run(ctx, cfg, db, os.Args[2:]); err != nil {
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: admin <command> [flags]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-24s %s\n", name, commands[name].description)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+)
+
+func runMigrateFacts(ctx context.Context, cfg *config.Config, db *database.Database, args []string) error {
+	flags := flag.NewFlagSet("migrate-facts", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would change without writing")
+	flags.Parse(args)
+
+	factService := services.NewFactService(db, nil)
+
+	result, err := factService.MigrateFacts(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Migrated"
+	if *dryRun {
+		verb = "Would migrate"
+	}
+	log.Printf("%s %d of %d facts (%d failed)", verb, result.Migrated, result.Scanned, result.Failed)
+	if result.MissingKeywords > 0 {
+		log.Printf("%d facts have no keywords; run backfill-keywords -only-missing", result.MissingKeywords)
+	}
+	return nil
+}
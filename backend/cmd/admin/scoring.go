@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+)
+
+func runRetrainScoring(ctx context.Context, cfg *config.Config, db *database.Database, args []string) error {
+	factService := services.NewFactService(db, nil)
+
+	model, updated, err := factService.RetrainScoring(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Trained scoring model on %d served facts (fit %.2f, mean engagement %.3f per serve)",
+		model.Facts, model.Fit, model.MeanRate)
+	log.Printf("Rescored %d facts", updated)
+	return nil
+}
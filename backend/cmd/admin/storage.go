@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+)
+
+// mongoOnlyData lists the collections the embedded store has no place for,
+// which stay behind in MongoDB when migrating to it
+var mongoOnlyData = []struct {
+	collection  string
+	description string
+}{
+	{"calendar", "publishing calendar, including pinned slots"},
+	{"daily_archive", "daily fact archive and history"},
+	{"collection_runs", "collection run history"},
+	{"source_schedules", "per-source collection schedules"},
+	{"processor_settings", "processor settings: difficulty thresholds, tag taxonomy and the trained category and scoring models"},
+}
+
+// leftBehind returns the descriptions of the MongoDB-only data that is not
+// empty, so would be lost by moving to the embedded store
+func leftBehind(ctx context.Context, db *database.Database) ([]string, error) {
+	var lost []string
+	for _, data := range mongoOnlyData {
+		count, err := db.GetCollection(data.collection).EstimatedDocumentCount(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("counting %s: %w", data.collection, err)
+		}
+		if count > 0 {
+			lost = append(lost, fmt.Sprintf("%s (%d documents in %s)", data.description, count, data.collection))
+		}
+	}
+	return lost, nil
+}
+
+func runMigrateStorage(ctx context.Context, cfg *config.Config, db *database.Database, args []string) error {
+	flags := flag.NewFlagSet("migrate-storage", flag.ExitOnError)
+	to := flags.String("to", storage.BackendBolt, "storage to copy facts and chat sessions into: bolt or mongo")
+	file := flags.String("file", cfg.Storage.Path, "data file of the embedded store")
+	acceptLoss := flags.Bool("accept-loss", false, "copy into bolt even though MongoDB-only data is left behind")
+	flags.Parse(args)
+
+	// The embedded store only keeps facts and chat sessions; everything else
+	// stays in MongoDB and is unavailable in bolt mode
+	if *to == storage.BackendBolt {
+		lost, err := leftBehind(ctx, db)
+		if err != nil {
+			return err
+		}
+		if len(lost) > 0 {
+			log.Printf("The embedded store keeps only facts and chat sessions. Not carried over:")
+			for _, data := range lost {
+				log.Printf("  - %s", data)
+			}
+			log.Printf("These features answer 501 Not Implemented in bolt mode")
+			if !*acceptLoss {
+				return fmt.Errorf("not copying; rerun with -accept-loss to copy facts and chat sessions anyway")
+			}
+		}
+	}
+
+	store, err := storage.OpenBolt(*file)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", *file, err)
+	}
+	defer store.Close()
+
+	mongoFacts, mongoChats := storage.NewMongoFacts(db), storage.NewMongoChats(db)
+	var facts, duplicates, chats int
+	switch *to {
+	case storage.BackendBolt:
+		if facts, duplicates, err = storage.CopyFacts(ctx, store.Facts(), mongoFacts); err != nil {
+			return fmt.Errorf("copying facts: %w", err)
+		}
+		if chats, err = storage.CopyChats(ctx, store.Chats(), mongoChats); err != nil {
+			return fmt.Errorf("copying chat sessions: %w", err)
+		}
+		log.Printf("Copied %d facts and %d chat sessions from MongoDB to %s", facts, chats, *file)
+	case storage.BackendMongo:
+		// The data file has no unique indexes, so it may hold facts MongoDB
+		// refuses as duplicates
+		if facts, duplicates, err = storage.CopyFacts(ctx, mongoFacts, store.Facts()); err != nil {
+			return fmt.Errorf("copying facts: %w", err)
+		}
+		if chats, err = storage.CopyChats(ctx, mongoChats, store.Chats()); err != nil {
+			return fmt.Errorf("copying chat sessions: %w", err)
+		}
+		log.Printf("Copied %d facts and %d chat sessions from %s to MongoDB", facts, chats, *file)
+		log.Printf("Run backfill-keywords to rebuild the keyword statistics in MongoDB")
+	default:
+		return fmt.Errorf("unknown storage %q: want %s or %s", *to, storage.BackendBolt, storage.BackendMongo)
+	}
+	if duplicates > 0 {
+		log.Printf("Skipped %d facts duplicating another fact's source and title, or content without a title", duplicates)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+)
+
+func runRetagFacts(ctx context.Context, cfg *config.Config, db *database.Database, args []string) error {
+	factService := services.NewFactService(db, nil)
+
+	updated, err := factService.RetagFacts(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Remapped tags of %d facts through the tag taxonomy", updated)
+	return nil
+}
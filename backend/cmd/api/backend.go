@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/leader"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scheduler"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+)
+
+// backend is the fact service and scheduler running on one kind of storage
+type backend struct {
+	facts          *services.FactService
+	startScheduler func(ctx context.Context) error
+	stopScheduler  func(ctx context.Context) error
+	storageName    string // Named in shutdown logs
+	closeStorage   func(ctx context.Context) error
+}
+
+// newMongoBackend connects to MongoDB and sets up the full scheduler, with
+// per-source schedules, the publishing calendar and leader election
+func newMongoBackend(cfg *config.Config, cache database.Cache, aiService *services.AIService, textRules []processors.TextRule) *backend {
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	factService := services.NewFactService(db, cache)
+
+	// Initialize fact scheduler
+	storeConfig := scheduler.StoreConfig{
+		BatchSize:     cfg.Services.StoreBatchSize,
+		FlushInterval: cfg.Services.StoreFlush,
+		MaxRetries:    cfg.Services.StoreMaxRetries,
+		Writers:       cfg.Services.StoreWriters,
+	}
+	factScheduler := scheduler.NewScheduler(db)
+	factScheduler.SetTextRules(textRules)
+	if err := factScheduler.SetDefaultSchedule(cfg.Services.FactSchedule, cfg.Services.CatchUp); err != nil {
+		log.Fatalf("Invalid FACT_SCHEDULE or FACT_CATCH_UP: %v", err)
+	}
+	if cfg.Services.RewriteFacts {
+		factScheduler.EnableRewrite(aiService)
+	}
+	if aiService.Enabled() {
+		factScheduler.EnableVerificationJudge(aiService)
+	}
+	factScheduler.SetCalendarDays(cfg.Services.CalendarDays)
+	factScheduler.SetInventoryPolicy(cfg.Services.InventoryTarget, cfg.Services.AlertDays)
+	factScheduler.SetStoreConfig(storeConfig)
+	factService.SetScheduler(factScheduler)
+	if err := factService.EnsureCalendar(context.Background()); err != nil {
+		log.Printf("Failed to set up the publishing calendar: %v", err)
+	}
+	if err := factScheduler.EnsureRunHistory(context.Background(), cfg.Services.RunRetention); err != nil {
+		log.Printf("Failed to set up collection run history: %v", err)
+	}
+	if err := factScheduler.EnsureFactIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create the indexes collected facts are deduplicated on: %v", err)
+	}
+
+	// Only the replica holding the leader lease runs scheduled jobs. Every
+	// replica should keep the lease in the same store; an unreachable Redis
+	// falls back to MongoDB, where this replica may be elected alongside the
+	// leader holding the lease in Redis.
+	holder := cfg.Services.InstanceID
+	if holder == "" {
+		holder = leader.DefaultHolder()
+	}
+	closeStorage := db.Close
+	mongoLock := leader.NewMongoLock(db.GetCollection("leases"), "scheduler")
+	var lock leader.Lock
+	switch cfg.Services.LeaderLock {
+	case leader.LockMongo:
+		lock = mongoLock
+	case leader.LockRedis:
+		redisCache, ok := cache.(*database.RedisCache)
+		if !ok {
+			// The cache is kept in memory, so connect to Redis for the lease
+			if redisCache, err = database.NewRedisCache(cfg); err != nil {
+				log.Printf("Warning: failed to connect to Redis for leader election, keeping the leader lease in MongoDB: %v", err)
+				log.Printf("Warning: replicas holding the lease in Redis may run scheduled jobs alongside this one until it is restarted with Redis reachable")
+				lock = mongoLock
+				break
+			}
+			closeStorage = func(ctx context.Context) error {
+				return errors.Join(db.Close(ctx), redisCache.Close())
+			}
+		}
+		lock = leader.NewRedisLock(redisCache.Client(), "scheduler")
+	default:
+		log.Fatalf("Invalid LEADER_LOCK %q: want %q or %q", cfg.Services.LeaderLock, leader.LockMongo, leader.LockRedis)
+	}
+	factScheduler.SetElector(leader.NewElector(lock, holder, cfg.Services.LeaderLeaseTTL))
+
+	return &backend{
+		facts:          factService,
+		startScheduler: factScheduler.Start,
+		stopScheduler:  factScheduler.Stop,
+		storageName:    "MongoDB",
+		closeStorage:   closeStorage,
+	}
+}
+
+// newBoltBackend opens the data file and sets up the embedded scheduler, so
+// the API and fact collection run as one process without MongoDB
+func newBoltBackend(cfg *config.Config, cache database.Cache, aiService *services.AIService, textRules []processors.TextRule) *backend {
+	store, err := storage.OpenBolt(cfg.Storage.Path)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", cfg.Storage.Path, err)
+	}
+	log.Printf("Storing data in %s", cfg.Storage.Path)
+	factService := services.NewStorageFactService(store.Facts(), store.Chats(), cache)
+
+	// Keyword statistics are kept in memory, so rebuild them from the facts
+	if _, err := factService.BackfillKeywords(context.Background(), true); err != nil {
+		log.Printf("Failed to rebuild keyword statistics: %v", err)
+	}
+
+	factScheduler := scheduler.NewEmbeddedScheduler(store.Facts(), factService.KeywordStats())
+	factScheduler.SetTextRules(textRules)
+	if err := factScheduler.SetSchedule(cfg.Services.FactSchedule); err != nil {
+		log.Fatalf("Invalid FACT_SCHEDULE: %v", err)
+	}
+	if cfg.Services.RewriteFacts {
+		factScheduler.EnableRewrite(aiService)
+	}
+	if aiService.Enabled() {
+		factScheduler.EnableVerificationJudge(aiService)
+	}
+
+	return &backend{
+		facts:          factService,
+		startScheduler: factScheduler.Start,
+		stopScheduler:  factScheduler.Stop,
+		storageName:    "data file",
+		closeStorage: func(ctx context.Context) error {
+			return store.Close()
+		},
+	}
+}
@@ -2,15 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/ZigaoWang/one-fact-app/backend/internal/calendar"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/handlers"
-	"github.com/ZigaoWang/one-fact-app/backend/internal/scheduler"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/lifecycle"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -29,32 +36,46 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize MongoDB connection
-	db, err := database.NewDatabase(cfg)
+	// Initialize the cache, in memory when Redis is not configured or
+	// unreachable
+	cache, err := database.NewCache(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		log.Fatalf("Invalid CACHE_BACKEND: %v", err)
 	}
 
-	// Initialize Redis cache
-	cache, err := database.NewCache(cfg)
+	textRules, err := processors.TextRulesByName(cfg.Services.TextRules)
 	if err != nil {
-		log.Printf("Failed to connect to Redis: %v", err)
+		log.Fatalf("Invalid FACT_TEXT_RULES: %v", err)
 	}
-
-	// Create services
-	factService := services.NewFactService(db, cache)
 	aiService := services.NewAIService()
 
+	// Create services and the fact scheduler on the configured storage
+	var app *backend
+	switch cfg.Storage.Backend {
+	case storage.BackendMongo:
+		app = newMongoBackend(cfg, cache, aiService, textRules)
+	case storage.BackendBolt:
+		app = newBoltBackend(cfg, cache, aiService, textRules)
+	default:
+		log.Fatalf("Invalid STORAGE_BACKEND %q: want %q or %q", cfg.Storage.Backend, storage.BackendMongo, storage.BackendBolt)
+	}
+	factService := app.facts
+	if cfg.Services.DefaultTimezone != "" {
+		loc, err := calendar.ParseTimezone(cfg.Services.DefaultTimezone)
+		if err != nil {
+			log.Fatalf("Invalid DEFAULT_TIMEZONE: %v", err)
+		}
+		factService.SetDefaultTimezone(loc)
+	}
+
 	// Create handlers
 	factHandler := handlers.NewFactHandler(factService)
 	chatHandler := handlers.NewChatHandler(factService, aiService)
 
-	// Initialize fact scheduler
-	scheduler := scheduler.NewScheduler(db.GetCollection("facts"))
-
-	// Start scheduler in a goroutine
+	// Start scheduler in a goroutine. It runs until the lifecycle manager
+	// stops it on shutdown.
 	go func() {
-		if err := scheduler.Start(context.Background()); err != nil {
+		if err := app.startScheduler(context.Background()); err != nil && !errors.Is(err, context.Canceled) {
 			log.Printf("Scheduler error: %v", err)
 		}
 	}()
@@ -69,7 +90,7 @@ func main() {
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", "X-Chat-Session-ID"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -83,23 +104,35 @@ func main() {
 		chatHandler.RegisterRoutes(r)
 	})
 
-	// Trigger initial fact collection
-	go func() {
-		log.Println("Starting initial fact collection...")
-		if err := scheduler.CollectFacts(context.Background()); err != nil {
-			log.Printf("Error in initial fact collection: %v", err)
-		}
-		log.Println("Initial fact collection completed")
-	}()
-
 	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	server.RegisterOnShutdown(factHandler.CloseStreams)
+
+	// On SIGTERM, drain HTTP, then stop the scheduler and its jobs, then close
+	// the storage and the cache, all within SHUTDOWN_TIMEOUT
+	manager := lifecycle.New(server, cfg.Server.ShutdownTimeout, cfg.Server.DrainDelay)
+	r.Get("/healthz", manager.Health)
+	r.Get("/readyz", manager.Ready)
+	manager.OnShutdown("scheduler", app.stopScheduler)
+	manager.OnShutdown(app.storageName, app.closeStorage)
+	manager.OnShutdown("cache", func(ctx context.Context) error {
+		return cache.Close()
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	log.Printf("Starting server on port %s", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if err := manager.Run(ctx); err != nil {
+		log.Fatalf("Server stopped with errors: %v", err)
 	}
+	log.Println("Server stopped")
 }
@@ -8,7 +8,9 @@ require (
 	github.com/go-chi/render v1.0.3
 	github.com/go-redis/redis/v8 v8.11.5
 	github.com/joho/godotenv v1.5.1
+	go.etcd.io/bbolt v1.3.10
 	go.mongodb.org/mongo-driver v1.17.2
+	golang.org/x/text v0.21.0
 )
 
 require (
@@ -24,5 +26,5 @@ require (
 	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78 // indirect
 	golang.org/x/crypto v0.32.0 // indirect
 	golang.org/x/sync v0.10.0 // indirect
-	golang.org/x/text v0.21.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
 )
@@ -0,0 +1,147 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNotPublished is returned when reading the daily fact of a day that has
+// not begun yet
+var ErrNotPublished = errors.New("daily facts are not published before their day")
+
+// ArchiveEntry records the fact served as the daily fact of a category on a
+// date. Entries recorded before the archive was kept per slot also name the
+// time zone the date was reckoned in.
+type ArchiveEntry struct {
+	Date      string             `bson:"date" json:"date"`
+	Category  string             `bson:"category" json:"category"`
+	Locale    string             `bson:"locale,omitempty" json:"-"`
+	FactID    primitive.ObjectID `bson:"fact_id" json:"fact_id"`
+	DecidedAt time.Time          `bson:"decided_at" json:"decided_at"`
+	Fact      *models.Fact       `bson:"-" json:"fact,omitempty"`
+}
+
+// Archive keeps every daily fact decision, so past daily facts can be read
+// again
+type Archive struct {
+	entries *mongo.Collection
+	facts   *mongo.Collection
+}
+
+// NewArchive creates an archive stored in the daily_archive collection
+func NewArchive(db *database.Database) *Archive {
+	return &Archive{
+		entries: db.GetCollection("daily_archive"),
+		facts:   db.GetCollection("facts"),
+	}
+}
+
+// EnsureIndexes creates the indexes that keep one entry per date and
+// category and serve history queries. Entries recorded per time zone before
+// are left out of the unique index.
+func (a *Archive) EnsureIndexes(ctx context.Context) error {
+	_, err := a.entries.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "date", Value: 1}, {Key: "category", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"locale": bson.M{"$exists": false}}),
+		},
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "date", Value: -1}}},
+	})
+	return err
+}
+
+// Record saves the daily fact served for a category on a date, replacing an
+// earlier decision when the calendar changed since
+func (a *Archive) Record(ctx context.Context, date, category string, factID primitive.ObjectID) error {
+	_, err := a.entries.UpdateOne(ctx,
+		bson.M{"date": date, "category": category, "locale": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"fact_id": factID, "decided_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Entry returns the daily fact of a category on a date with its fact
+func (a *Archive) Entry(ctx context.Context, date, category string) (*ArchiveEntry, error) {
+	entries, err := a.History(ctx, date, date, category)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &entries[0], nil
+}
+
+// History lists the daily facts from one date to another, both included,
+// most recent first, with their facts. A category may be given to list only
+// its facts. For each date and category the entry recorded per slot is
+// preferred over ones recorded per time zone before.
+func (a *Archive) History(ctx context.Context, from, to, category string) ([]ArchiveEntry, error) {
+	if _, err := dateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"date": bson.M{"$gte": from, "$lte": to}}
+	if category != "" {
+		filter["category"] = category
+	}
+	cursor, err := a.entries.Find(ctx, filter, options.Find().SetSort(bson.D{
+		{Key: "date", Value: -1},
+		{Key: "category", Value: 1},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var found []ArchiveEntry
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	// Keep one entry per date and category
+	type day struct{ date, category string }
+	picked := make(map[day]int)
+	entries := []ArchiveEntry{}
+	for _, entry := range found {
+		key := day{entry.Date, entry.Category}
+		i, ok := picked[key]
+		if !ok {
+			picked[key] = len(entries)
+			entries = append(entries, entry)
+		} else if entry.Locale == "" {
+			entries[i] = entry
+		}
+	}
+
+	ids := make([]primitive.ObjectID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.FactID
+	}
+	cursor, err = a.facts.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var facts []models.Fact
+	if err := cursor.All(ctx, &facts); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]*models.Fact, len(facts))
+	for i := range facts {
+		byID[facts[i].ID] = &facts[i]
+	}
+	for i := range entries {
+		entries[i].Fact = byID[entries[i].FactID]
+	}
+	return entries, nil
+}
@@ -0,0 +1,681 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DateLayout is the format of calendar dates
+const DateLayout = "2006-01-02"
+
+// candidates is how many of the best scored facts an empty slot is filled
+// from, so the choice favours engaging facts without always taking the best
+const candidates = 20
+
+// reuseDays is how long a fact is kept out of automatically filled slots
+// after the day it was scheduled for
+const reuseDays = 90
+
+// maxRange is the most days listed or filled at once
+const maxRange = 366
+
+var (
+	// ErrInvalidDate is returned for dates not in DateLayout
+	ErrInvalidDate = errors.New("invalid date, expected YYYY-MM-DD")
+
+	// ErrPastDate is returned when changing a day that has already passed
+	ErrPastDate = errors.New("past days cannot be changed")
+
+	// ErrNoFacts is returned when no fact can fill a slot
+	ErrNoFacts = errors.New("no facts available")
+
+	// ErrUnsuitableFact is returned when pinning a fact that cannot be served
+	// in the slot, because it is not verified or has another category
+	ErrUnsuitableFact = errors.New("fact cannot be scheduled in this slot")
+
+	// ErrInvalidTimezone is returned for time zones that are neither IANA
+	// names nor UTC offsets
+	ErrInvalidTimezone = errors.New("invalid time zone, expected an IANA name such as Asia/Tokyo or a UTC offset such as +09:00")
+
+	// ErrAlreadyPinned is returned when pinning a fact that is pinned to
+	// another upcoming day
+	ErrAlreadyPinned = errors.New("fact is already pinned to another day")
+)
+
+// Slot assigns the fact served for a category on a day
+type Slot struct {
+	Date       string               `bson:"date" json:"date"`
+	Category   string               `bson:"category" json:"category"`
+	FactID     primitive.ObjectID   `bson:"fact_id" json:"fact_id"`
+	Pinned     bool                 `bson:"pinned" json:"pinned"` // Chosen by an editor rather than filled from the pool
+	AssignedAt time.Time            `bson:"assigned_at" json:"assigned_at"`
+	Served     []primitive.ObjectID `bson:"served,omitempty" json:"-"` // Facts whose serve in the slot was counted
+	Fact       *models.Fact         `bson:"-" json:"fact,omitempty"`
+}
+
+// Calendar is the publishing calendar of daily facts, with one slot per date
+// and category. Empty slots are filled from the best scored verified facts
+// when they are first needed.
+type Calendar struct {
+	slots *mongo.Collection
+	facts *mongo.Collection
+	now   func() time.Time
+}
+
+// New creates a calendar stored in the calendar collection
+func New(db *database.Database) *Calendar {
+	return &Calendar{
+		slots: db.GetCollection("calendar"),
+		facts: db.GetCollection("facts"),
+		now:   time.Now,
+	}
+}
+
+// EnsureIndexes creates the indexes that keep one slot per date and category
+func (c *Calendar) EnsureIndexes(ctx context.Context) error {
+	_, err := c.slots.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "date", Value: 1}, {Key: "category", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "fact_id", Value: 1}}},
+	})
+	return err
+}
+
+// Today returns today's date
+func (c *Calendar) Today() string {
+	return c.now().Format(DateLayout)
+}
+
+// Day returns the date of t in its location and the time that day ends
+func Day(t time.Time) (string, time.Time) {
+	year, month, day := t.Date()
+	return t.Format(DateLayout), time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+}
+
+// utcOffset matches UTC offsets such as +09:00, -0530, +8 or UTC+05:45
+var utcOffset = regexp.MustCompile(`^(?:UTC|GMT)?([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// ParseTimezone returns the location of an IANA time zone name or a UTC
+// offset. Offsets are at most 14 hours, in whole minutes.
+func ParseTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if match := utcOffset.FindStringSubmatch(name); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes := 0
+		if match[3] != "" {
+			minutes, _ = strconv.Atoi(match[3])
+		}
+		if hours > 14 || minutes >= 60 || hours == 14 && minutes > 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+		}
+
+		offset := hours*3600 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+		if offset == 0 {
+			return time.UTC, nil
+		}
+		return time.FixedZone(fmt.Sprintf("UTC%s%02d:%02d", match[1], hours, minutes), offset), nil
+	}
+
+	// Local is the server's own zone, which clients cannot know
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	return loc, nil
+}
+
+// ParseDate checks that a date is in DateLayout and returns it normalized
+func ParseDate(date string) (string, error) {
+	t, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDate, date)
+	}
+	return t.Format(DateLayout), nil
+}
+
+// AddDays returns the date n days after date
+func AddDays(date string, n int) string {
+	t, _ := time.Parse(DateLayout, date)
+	return t.AddDate(0, 0, n).Format(DateLayout)
+}
+
+// dateRange returns every date from from to to, both included
+func dateRange(from, to string) ([]string, error) {
+	var err error
+	if from, err = ParseDate(from); err != nil {
+		return nil, err
+	}
+	if to, err = ParseDate(to); err != nil {
+		return nil, err
+	}
+
+	var dates []string
+	for date := from; date <= to; date = AddDays(date, 1) {
+		if len(dates) == maxRange {
+			return nil, fmt.Errorf("%w: at most %d days at once", ErrInvalidDate, maxRange)
+		}
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+// publishTime is the time stored as the publish date of a fact scheduled
+// for date: its midnight in the server's time zone
+func publishTime(date string) time.Time {
+	t, _ := time.ParseInLocation(DateLayout, date, time.Local)
+	return t
+}
+
+// PublishedDate returns the date a fact with the publish date is scheduled for
+func PublishedDate(t time.Time) string {
+	return t.In(time.Local).Format(DateLayout)
+}
+
+// checkChangeable returns an error unless date is today or later
+func (c *Calendar) checkChangeable(date string) (string, error) {
+	date, err := ParseDate(date)
+	if err != nil {
+		return "", err
+	}
+	if date < c.Today() {
+		return "", fmt.Errorf("%w: %s", ErrPastDate, date)
+	}
+	return date, nil
+}
+
+// Fact returns the fact scheduled for a category on a day, filling the slot
+// from the pool when it is empty
+func (c *Calendar) Fact(ctx context.Context, date, category string) (*models.Fact, error) {
+	slot, _, err := c.slot(ctx, date, category)
+	if err != nil {
+		return nil, err
+	}
+	return slot.Fact, nil
+}
+
+// MarkServed records that the fact was served in the slot of a category on a
+// day and reports whether it was the first time, so a serve is counted once
+// per slot however many time zones and replicas serve the day
+func (c *Calendar) MarkServed(ctx context.Context, date, category string, factID primitive.ObjectID) (bool, error) {
+	result, err := c.slots.UpdateOne(ctx,
+		bson.M{"date": date, "category": category, "fact_id": factID, "served": bson.M{"$ne": factID}},
+		bson.M{"$addToSet": bson.M{"served": factID}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// slot returns the slot of a category on a day with its fact, filling it
+// when it is empty or its fact can no longer be served. It reports whether
+// the slot was filled.
+func (c *Calendar) slot(ctx context.Context, date, category string) (*Slot, bool, error) {
+	var slot Slot
+	err := c.slots.FindOne(ctx, bson.M{"date": date, "category": category}).Decode(&slot)
+	if err == nil {
+		fact, err := c.fact(ctx, slot.FactID)
+		if err == nil && fact.Verified && fact.Category == category {
+			slot.Fact = fact
+			return &slot, false, nil
+		}
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, false, err
+		}
+
+		// The fact was deleted, rejected or recategorized since it was
+		// scheduled, so the slot is filled again
+		log.Printf("Calendar fact %s for %s on %s can no longer be served, refilling", slot.FactID.Hex(), category, date)
+		if _, err := c.slots.DeleteOne(ctx, bson.M{"date": date, "category": category, "fact_id": slot.FactID}); err != nil {
+			return nil, false, err
+		}
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, err
+	}
+
+	return c.fill(ctx, date, category)
+}
+
+// fill assigns a fact from the pool to an empty slot. When another replica
+// filled the slot first, its assignment is returned instead.
+func (c *Calendar) fill(ctx context.Context, date, category string) (*Slot, bool, error) {
+	fact, err := c.pickUnscheduled(ctx, date, category)
+	if err != nil {
+		return nil, false, err
+	}
+
+	slot := &Slot{
+		Date:       date,
+		Category:   category,
+		FactID:     fact.ID,
+		AssignedAt: c.now(),
+	}
+	if _, err := c.slots.InsertOne(ctx, slot); mongo.IsDuplicateKeyError(err) {
+		var existing Slot
+		if err := c.slots.FindOne(ctx, bson.M{"date": date, "category": category}).Decode(&existing); err != nil {
+			return nil, false, err
+		}
+		if existing.Fact, err = c.fact(ctx, existing.FactID); err != nil {
+			return nil, false, err
+		}
+		return &existing, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	if err := c.setPublishDate(ctx, fact, date); err != nil {
+		return nil, false, err
+	}
+	slot.Fact = fact
+	return slot, true, nil
+}
+
+// pickUnscheduled picks a fact for a slot among the best scored verified
+// facts of the category, leaving out facts scheduled in the reuse window
+// around the date. When every fact was scheduled recently, only facts
+// scheduled for upcoming days are left out.
+func (c *Calendar) pickUnscheduled(ctx context.Context, date, category string) (*models.Fact, error) {
+	window := AddDays(date, -reuseDays)
+	if today := c.Today(); window > today {
+		window = today
+	}
+	for _, since := range []string{window, c.Today()} {
+		scheduled, err := c.slots.Distinct(ctx, "fact_id", bson.M{"date": bson.M{"$gte": since}})
+		if err != nil {
+			return nil, err
+		}
+
+		fact, err := c.pick(ctx, bson.M{
+			"verified": true,
+			"category": category,
+			"_id":      bson.M{"$nin": scheduled},
+		})
+		if !errors.Is(err, ErrNoFacts) {
+			return fact, err
+		}
+	}
+	return nil, fmt.Errorf("%w for category: %s", ErrNoFacts, category)
+}
+
+// Pick picks a verified fact of the category at random among the best
+// scored, without scheduling it
+func (c *Calendar) Pick(ctx context.Context, category string) (*models.Fact, error) {
+	fact, err := c.pick(ctx, bson.M{"verified": true, "category": category})
+	if errors.Is(err, ErrNoFacts) {
+		return nil, fmt.Errorf("%w for category: %s", ErrNoFacts, category)
+	}
+	return fact, err
+}
+
+func (c *Calendar) pick(ctx context.Context, match bson.M) (*models.Fact, error) {
+	cursor, err := c.facts.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.M{"score": -1}}},
+		{{Key: "$limit", Value: candidates}},
+		{{Key: "$sample", Value: bson.M{"size": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var facts []models.Fact
+	if err := cursor.All(ctx, &facts); err != nil {
+		return nil, err
+	}
+	if len(facts) == 0 {
+		return nil, ErrNoFacts
+	}
+	return &facts[0], nil
+}
+
+func (c *Calendar) fact(ctx context.Context, id primitive.ObjectID) (*models.Fact, error) {
+	var fact models.Fact
+	if err := c.facts.FindOne(ctx, bson.M{"_id": id}).Decode(&fact); err != nil {
+		return nil, err
+	}
+	return &fact, nil
+}
+
+// setPublishDate records on the fact the day it is scheduled for
+func (c *Calendar) setPublishDate(ctx context.Context, fact *models.Fact, date string) error {
+	fact.PublishDate = publishTime(date)
+	_, err := c.facts.UpdateByID(ctx, fact.ID, bson.M{"$set": bson.M{"publish_date": fact.PublishDate}})
+	return err
+}
+
+// clearPublishDate removes the publish date of a fact taken off a day, unless
+// it was scheduled for another day since
+func (c *Calendar) clearPublishDate(ctx context.Context, id primitive.ObjectID, date string) error {
+	_, err := c.facts.UpdateOne(ctx,
+		bson.M{"_id": id, "publish_date": publishTime(date)},
+		bson.M{"$unset": bson.M{"publish_date": ""}},
+	)
+	return err
+}
+
+// Slots lists the slots from one date to another, both included, with their
+// facts. Slots that were not filled yet are left out.
+func (c *Calendar) Slots(ctx context.Context, from, to, category string) ([]Slot, error) {
+	if _, err := dateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"date": bson.M{"$gte": from, "$lte": to}}
+	if category != "" {
+		filter["category"] = category
+	}
+	cursor, err := c.slots.Find(ctx, filter, options.Find().SetSort(bson.D{
+		{Key: "date", Value: 1},
+		{Key: "category", Value: 1},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []Slot{}
+	if err := cursor.All(ctx, &slots); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(slots))
+	for i, slot := range slots {
+		ids[i] = slot.FactID
+	}
+	cursor, err = c.facts.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var facts []models.Fact
+	if err := cursor.All(ctx, &facts); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]*models.Fact, len(facts))
+	for i := range facts {
+		byID[facts[i].ID] = &facts[i]
+	}
+	for i := range slots {
+		slots[i].Fact = byID[slots[i].FactID]
+	}
+	return slots, nil
+}
+
+// Categories returns the categories that have verified facts
+func (c *Calendar) Categories(ctx context.Context) ([]string, error) {
+	values, err := c.facts.Distinct(ctx, "category", bson.M{"verified": true})
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]string, 0, len(values))
+	for _, value := range values {
+		if category, ok := value.(string); ok && category != "" {
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+// Fill fills the empty slots of the given categories for a number of days
+// from a date, or of every category with verified facts when none are given.
+// Categories without facts left are skipped. It returns the number of slots
+// filled.
+func (c *Calendar) Fill(ctx context.Context, from string, days int, categories []string) (int, error) {
+	if days < 1 {
+		return 0, nil
+	}
+	dates, err := dateRange(from, AddDays(from, days-1))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(categories) == 0 {
+		if categories, err = c.Categories(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	filled := 0
+	for _, category := range categories {
+		for _, date := range dates {
+			_, ok, err := c.slot(ctx, date, category)
+			if errors.Is(err, ErrNoFacts) {
+				log.Printf("Calendar: %v", err)
+				break
+			}
+			if err != nil {
+				return filled, err
+			}
+			if ok {
+				filled++
+			}
+		}
+	}
+	return filled, nil
+}
+
+// Pin schedules a fact for a category on a day, replacing the fact in the
+// slot. The fact is taken off other upcoming days it was filled into, which
+// are filled again when needed.
+func (c *Calendar) Pin(ctx context.Context, date, category string, factID primitive.ObjectID) (*Slot, error) {
+	date, err := c.checkChangeable(date)
+	if err != nil {
+		return nil, err
+	}
+
+	fact, err := c.fact(ctx, factID)
+	if err != nil {
+		return nil, err
+	}
+	if !fact.Verified || fact.Category != category {
+		return nil, fmt.Errorf("%w: it must be verified and in the %s category", ErrUnsuitableFact, category)
+	}
+
+	var other Slot
+	err = c.slots.FindOne(ctx, bson.M{
+		"fact_id": factID,
+		"pinned":  true,
+		"date":    bson.M{"$gte": c.Today(), "$ne": date},
+	}).Decode(&other)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyPinned, other.Date)
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	if _, err := c.slots.DeleteMany(ctx, bson.M{
+		"fact_id": factID,
+		"pinned":  false,
+		"date":    bson.M{"$gte": c.Today(), "$ne": date},
+	}); err != nil {
+		return nil, err
+	}
+
+	slot := &Slot{
+		Date:       date,
+		Category:   category,
+		FactID:     factID,
+		Pinned:     true,
+		AssignedAt: c.now(),
+	}
+	var previous Slot
+	err = c.slots.FindOneAndReplace(ctx,
+		bson.M{"date": date, "category": category},
+		slot,
+		options.FindOneAndReplace().SetUpsert(true),
+	).Decode(&previous)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if err == nil && previous.FactID != factID {
+		if err := c.clearPublishDate(ctx, previous.FactID, date); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.setPublishDate(ctx, fact, date); err != nil {
+		return nil, err
+	}
+	slot.Fact = fact
+	return slot, nil
+}
+
+// Clear empties a slot so that it is filled from the pool again
+func (c *Calendar) Clear(ctx context.Context, date, category string) error {
+	date, err := c.checkChangeable(date)
+	if err != nil {
+		return err
+	}
+
+	var slot Slot
+	err = c.slots.FindOneAndDelete(ctx, bson.M{"date": date, "category": category}).Decode(&slot)
+	if err != nil {
+		return err
+	}
+	return c.clearPublishDate(ctx, slot.FactID, date)
+}
+
+// Swap exchanges the facts of a category on two days, filling either slot
+// first when it is empty. Both slots end up pinned.
+func (c *Calendar) Swap(ctx context.Context, category, date, other string) ([]Slot, error) {
+	date, err := c.checkChangeable(date)
+	if err != nil {
+		return nil, err
+	}
+	if other, err = c.checkChangeable(other); err != nil {
+		return nil, err
+	}
+
+	first, _, err := c.slot(ctx, date, category)
+	if err != nil {
+		return nil, err
+	}
+	if date == other {
+		return []Slot{*first}, nil
+	}
+	second, _, err := c.slot(ctx, other, category)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	first.FactID, second.FactID = second.FactID, first.FactID
+	first.Fact, second.Fact = second.Fact, first.Fact
+	for _, slot := range []*Slot{first, second} {
+		slot.Pinned = true
+		slot.AssignedAt = now
+		_, err := c.slots.UpdateOne(ctx,
+			bson.M{"date": slot.Date, "category": category},
+			bson.M{"$set": bson.M{"fact_id": slot.FactID, "pinned": true, "assigned_at": now}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.setPublishDate(ctx, slot.Fact, slot.Date); err != nil {
+			return nil, err
+		}
+	}
+	return []Slot{*first, *second}, nil
+}
+
+// usageDays is how many past days the daily use of a category is averaged over
+const usageDays = 14
+
+// Stock is what the calendar has left to fill the slots of a category with
+type Stock struct {
+	Available int     `json:"available"` // Verified facts not scheduled in the reuse window
+	Scheduled int     `json:"scheduled"` // Slots filled from today on
+	DailyUse  float64 `json:"daily_use"` // Slots filled per day lately
+}
+
+// Stock counts, per category, the facts left to fill slots with and how
+// fast slots have been used up
+func (c *Calendar) Stock(ctx context.Context) (map[string]*Stock, error) {
+	today := c.Today()
+	stock := make(map[string]*Stock)
+	get := func(category string) *Stock {
+		if stock[category] == nil {
+			stock[category] = &Stock{}
+		}
+		return stock[category]
+	}
+
+	scheduled, err := c.slots.Distinct(ctx, "fact_id", bson.M{"date": bson.M{"$gte": AddDays(today, -reuseDays)}})
+	if err != nil {
+		return nil, err
+	}
+	available, err := c.countBy(ctx, c.facts, bson.M{"verified": true, "_id": bson.M{"$nin": scheduled}})
+	if err != nil {
+		return nil, err
+	}
+	for category, n := range available {
+		get(category).Available = n
+	}
+
+	upcoming, err := c.countBy(ctx, c.slots, bson.M{"date": bson.M{"$gte": today}})
+	if err != nil {
+		return nil, err
+	}
+	for category, n := range upcoming {
+		get(category).Scheduled = n
+	}
+
+	used, err := c.countBy(ctx, c.slots, bson.M{"date": bson.M{"$gte": AddDays(today, -usageDays), "$lt": today}})
+	if err != nil {
+		return nil, err
+	}
+	for category, n := range used {
+		get(category).DailyUse = float64(n) / usageDays
+	}
+
+	return stock, nil
+}
+
+// countBy counts the documents matching filter per category
+func (c *Calendar) countBy(ctx context.Context, collection *mongo.Collection, filter bson.M) (map[string]int, error) {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{"_id": "$category", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []struct {
+		Category string `bson:"_id"`
+		Count    int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(groups))
+	for _, group := range groups {
+		if group.Category != "" {
+			counts[group.Category] = group.Count
+		}
+	}
+	return counts, nil
+}
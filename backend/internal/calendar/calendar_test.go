@@ -0,0 +1,105 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	if date, err := ParseDate("2024-02-29"); err != nil || date != "2024-02-29" {
+		t.Errorf("ParseDate(2024-02-29) = %q, %v", date, err)
+	}
+	for _, date := range []string{"", "2023-02-29", "2024-2-3", "29/02/2024"} {
+		if _, err := ParseDate(date); !errors.Is(err, ErrInvalidDate) {
+			t.Errorf("ParseDate(%q) error = %v, want ErrInvalidDate", date, err)
+		}
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	dates, err := dateRange("2024-02-27", "2024-03-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2024-02-27", "2024-02-28", "2024-02-29", "2024-03-01", "2024-03-02"}
+	if len(dates) != len(want) {
+		t.Fatalf("dateRange() = %v, want %v", dates, want)
+	}
+	for i := range want {
+		if dates[i] != want[i] {
+			t.Errorf("dateRange()[%d] = %s, want %s", i, dates[i], want[i])
+		}
+	}
+
+	if dates, err := dateRange("2024-03-02", "2024-03-01"); err != nil || len(dates) != 0 {
+		t.Errorf("dateRange() backwards = %v, %v, want no dates", dates, err)
+	}
+	if _, err := dateRange("2024-01-01", "2026-01-01"); !errors.Is(err, ErrInvalidDate) {
+		t.Errorf("dateRange() over two years error = %v, want ErrInvalidDate", err)
+	}
+}
+
+func TestCheckChangeable(t *testing.T) {
+	c := &Calendar{now: func() time.Time { return time.Date(2024, 3, 10, 23, 30, 0, 0, time.Local) }}
+
+	if _, err := c.checkChangeable("2024-03-09"); !errors.Is(err, ErrPastDate) {
+		t.Errorf("checkChangeable(yesterday) error = %v, want ErrPastDate", err)
+	}
+	for _, date := range []string{"2024-03-10", "2024-03-11"} {
+		if _, err := c.checkChangeable(date); err != nil {
+			t.Errorf("checkChangeable(%s) error = %v", date, err)
+		}
+	}
+}
+
+func TestDay(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// The day the clocks go forward ends at local midnight, 23 hours in
+	date, ends := Day(time.Date(2024, 3, 31, 0, 30, 0, 0, berlin))
+	if date != "2024-03-31" || ends.Sub(time.Date(2024, 3, 31, 0, 0, 0, 0, berlin)) != 23*time.Hour {
+		t.Errorf("Day() = %s, %v", date, ends)
+	}
+
+	// The same instant is already the next day further east
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	date, ends = Day(time.Date(2024, 3, 30, 23, 59, 0, 0, berlin).In(tokyo))
+	if date != "2024-03-31" || !ends.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, tokyo)) {
+		t.Errorf("Day() in Tokyo = %s, %v", date, ends)
+	}
+}
+
+func TestParseTimezone(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		offset int
+	}{
+		{"+09:00", "UTC+09:00", 9 * 3600},
+		{"-0530", "UTC-05:30", -(5*3600 + 30*60)},
+		{"UTC+8", "UTC+08:00", 8 * 3600},
+		{"+00:00", "UTC", 0},
+		{"Asia/Tokyo", "Asia/Tokyo", 9 * 3600},
+	}
+	for _, tt := range tests {
+		loc, err := ParseTimezone(tt.name)
+		if err != nil {
+			t.Errorf("ParseTimezone(%q) error = %v", tt.name, err)
+			continue
+		}
+		_, offset := time.Date(2024, 1, 15, 12, 0, 0, 0, loc).Zone()
+		if loc.String() != tt.want || offset != tt.offset {
+			t.Errorf("ParseTimezone(%q) = %s (%d), want %s (%d)", tt.name, loc, offset, tt.want, tt.offset)
+		}
+	}
+
+	for _, name := range []string{"", "Local", "+15:00", "+05:75", "Mars/Olympus", "9"} {
+		if _, err := ParseTimezone(name); !errors.Is(err, ErrInvalidTimezone) {
+			t.Errorf("ParseTimezone(%q) error = %v, want ErrInvalidTimezone", name, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package collectors
+
+import "context"
+
+// Plan is how many pages a source should fetch for each category, so that
+// collection targets the categories running low
+type Plan map[string]int
+
+type planKey struct{}
+
+// WithPlan returns a context that makes sources fetch pages per category as
+// planned
+func WithPlan(ctx context.Context, plan Plan) context.Context {
+	return context.WithValue(ctx, planKey{}, plan)
+}
+
+// pagesFor returns how many pages to fetch for a category: the number in the
+// context's plan, or def when the category is not planned
+func pagesFor(ctx context.Context, category string, def int) int {
+	if plan, ok := ctx.Value(planKey{}).(Plan); ok {
+		if pages, ok := plan[category]; ok {
+			return pages
+		}
+	}
+	return def
+}
+
+// StoredFunc reports which of the page titles of a source are stored
+// already
+type StoredFunc func(ctx context.Context, source string, titles []string) (map[string]bool, error)
+
+type storedFuncKey struct{}
+
+// WithStoredFunc returns a context that makes sources skip the pages fn
+// reports as stored, so the pages planned for a category bring new facts
+func WithStoredFunc(ctx context.Context, fn StoredFunc) context.Context {
+	return context.WithValue(ctx, storedFuncKey{}, fn)
+}
+
+// storedPages returns the titles the context's StoredFunc reports as stored.
+// Without one, or when it fails, no page is skipped.
+func storedPages(ctx context.Context, source string, titles []string) map[string]bool {
+	fn, ok := ctx.Value(storedFuncKey{}).(StoredFunc)
+	if !ok {
+		return nil
+	}
+	stored, err := fn(ctx, source, titles)
+	if err != nil {
+		return nil
+	}
+	return stored
+}
+
+// CategorySource is a source that collects from a fixed list of categories
+type CategorySource interface {
+	Source
+	Categories() []string
+}
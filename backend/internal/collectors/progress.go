@@ -0,0 +1,22 @@
+package collectors
+
+import "context"
+
+// PageFunc is told about every page a source fetches, with the error when
+// the fetch failed
+type PageFunc func(title string, err error)
+
+type pageFuncKey struct{}
+
+// WithPageFunc returns a context that makes sources report each page they
+// fetch to fn
+func WithPageFunc(ctx context.Context, fn PageFunc) context.Context {
+	return context.WithValue(ctx, pageFuncKey{}, fn)
+}
+
+// reportPage tells the context's PageFunc, if any, about a fetched page
+func reportPage(ctx context.Context, title string, err error) {
+	if fn, ok := ctx.Value(pageFuncKey{}).(PageFunc); ok {
+		fn(title, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -39,29 +40,90 @@ func (w *WikipediaSource) Name() string {
 	return "Wikipedia"
 }
 
-// GetFacts fetches random facts from Wikipedia
-func (w *WikipediaSource) GetFacts(ctx context.Context) ([]RawFact, error) {
-	// Define categories we want to fetch from
-	categories := []string{
-		"Science",
-		"Technology",
-		"History",
-		"Geography",
-		"Arts",
-		"Culture",
-		"Sports",
-		"Entertainment",
-		"Politics",
-		"Business",
-		"Education",
-		"Health",
-		"Environment",
-	}
+// wikipediaCategories are the categories pages are collected from
+var wikipediaCategories = []string{
+	"Science",
+	"Technology",
+	"History",
+	"Geography",
+	"Arts",
+	"Culture",
+	"Sports",
+	"Entertainment",
+	"Politics",
+	"Business",
+	"Education",
+	"Health",
+	"Environment",
+}
+
+// maxCategoryMembers caps how many pages of a category are listed to pick
+// from, which takes two requests at the API's limit of 500 per request
+const maxCategoryMembers = 1000
+
+// Categories returns the categories pages are collected from
+func (w *WikipediaSource) Categories() []string {
+	return append([]string(nil), wikipediaCategories...)
+}
 
+// GetFacts fetches random facts from Wikipedia. The number of pages per
+// category follows the context's plan, if any, and pages the context reports
+// as stored are skipped.
+func (w *WikipediaSource) GetFacts(ctx context.Context) ([]RawFact, error) {
 	var facts []RawFact
-	for _, cat := range categories {
+	for _, cat := range wikipediaCategories {
+		// Get 2-3 random pages from each category unless planned otherwise
+		numPages := pagesFor(ctx, cat, 2+rand.Intn(2))
+		if numPages <= 0 {
+			continue
+		}
+
 		// First, get pages from the category
+		titles, err := w.categoryMembers(ctx, cat)
+		if err != nil {
+			continue // Skip this category if there's an error
+		}
+		stored := storedPages(ctx, w.Name(), titles)
+
+		// Get random pages from this category that were not collected yet
+		rand.Shuffle(len(titles), func(i, j int) { titles[i], titles[j] = titles[j], titles[i] })
+		fetched := 0
+		for _, title := range titles {
+			if fetched == numPages {
+				break
+			}
+			if stored[title] {
+				continue
+			}
+			fetched++
+
+			fact, err := w.GetPage(ctx, title, cat) // Use the main category we're currently processing
+			reportPage(ctx, title, err)
+			if err != nil {
+				continue
+			}
+
+			// Skip if extract is too short or too long
+			if len(fact.Content) < 50 || len(fact.Content) > 500 {
+				continue
+			}
+			facts = append(facts, *fact)
+		}
+	}
+
+	return facts, nil
+}
+
+// categoryMembers lists the titles of the pages in a category, following the
+// API's continuation up to maxCategoryMembers
+func (w *WikipediaSource) categoryMembers(ctx context.Context, category string) ([]string, error) {
+	var titles []string
+	next := ""
+	for len(titles) < maxCategoryMembers {
 		var catResponse struct {
+			Continue struct {
+				Cmcontinue string `json:"cmcontinue"`
+			} `json:"continue"`
 			Query struct {
 				Categorymembers []struct {
 					Title string `json:"title"`
@@ -69,70 +131,74 @@ func (w *WikipediaSource) GetFacts(ctx context.Context) ([]RawFact, error) {
 			} `json:"query"`
 		}
 
-		catURL := fmt.Sprintf("%s?action=query&format=json&list=categorymembers&cmtitle=Category:%s&cmlimit=50",
-			w.baseURL, cat)
-
+		catURL := fmt.Sprintf("%s?action=query&format=json&list=categorymembers&cmtitle=Category:%s&cmtype=page&cmlimit=500",
+			w.baseURL, url.QueryEscape(strings.ReplaceAll(category, " ", "_")))
+		if next != "" {
+			catURL += "&cmcontinue=" + url.QueryEscape(next)
+		}
 		if err := w.FetchJSON(ctx, catURL, &catResponse); err != nil {
-			continue // Skip this category if there's an error
+			if len(titles) > 0 {
+				break // Pick from the pages listed so far
+			}
+			return nil, err
+		}
+
+		for _, member := range catResponse.Query.Categorymembers {
+			titles = append(titles, member.Title)
+		}
+		if next = catResponse.Continue.Cmcontinue; next == "" {
+			break
+		}
+	}
+	return titles, nil
+}
+
+// GetPage fetches the introduction of a single Wikipedia page as a raw fact
+func (w *WikipediaSource) GetPage(ctx context.Context, title, category string) (*RawFact, error) {
+	var pageResponse wikipediaResponse
+	pageURL := fmt.Sprintf("%s?action=query&format=json&prop=extracts|categories&exintro=1&explaintext=1&titles=%s",
+		w.baseURL, url.QueryEscape(strings.ReplaceAll(title, " ", "_")))
+
+	if err := w.FetchJSON(ctx, pageURL, &pageResponse); err != nil {
+		return nil, err
+	}
+
+	for _, pageContent := range pageResponse.Query.Pages {
+		if pageContent.Extract == "" {
+			continue
+		}
+
+		// Clean up the extract
+		content := strings.TrimSpace(pageContent.Extract)
+		if !strings.HasSuffix(content, ".") {
+			content += "."
 		}
 
-		// Get random pages from this category
-		if len(catResponse.Query.Categorymembers) > 0 {
-			// Get 2-3 random pages from each category
-			numPages := 2 + rand.Intn(2) // 2 or 3 pages
-			for i := 0; i < numPages && i < len(catResponse.Query.Categorymembers); i++ {
-				page := catResponse.Query.Categorymembers[i]
-
-				// Get page content
-				var pageResponse wikipediaResponse
-				pageURL := fmt.Sprintf("%s?action=query&format=json&prop=extracts|categories&exintro=1&explaintext=1&titles=%s",
-					w.baseURL, strings.ReplaceAll(page.Title, " ", "_"))
-
-				if err := w.FetchJSON(ctx, pageURL, &pageResponse); err != nil {
-					continue
-				}
-
-				// Process each page
-				for _, pageContent := range pageResponse.Query.Pages {
-					// Skip if extract is too short or too long
-					if len(pageContent.Extract) < 50 || len(pageContent.Extract) > 500 {
-						continue
-					}
-
-					// Clean up the extract
-					content := strings.TrimSpace(pageContent.Extract)
-					if !strings.HasSuffix(content, ".") {
-						content += "."
-					}
-
-					// Extract categories
-					pageCats := make([]string, 0)
-					for _, pageCat := range pageContent.Categories {
-						catName := strings.TrimPrefix(pageCat.Title, "Category:")
-						catName = strings.Trim(catName, " ")
-						if catName != "" {
-							pageCats = append(pageCats, catName)
-						}
-					}
-
-					fact := RawFact{
-						Content:  content,
-						Source:   "Wikipedia",
-						Category: cat, // Use the main category we're currently processing
-						Tags:     pageCats,
-						URLs: []string{
-							fmt.Sprintf("https://en.wikipedia.org/wiki/%s", strings.ReplaceAll(pageContent.Title, " ", "_")),
-						},
-						Metadata: map[string]string{
-							"title": pageContent.Title,
-						},
-						CollectedAt: time.Now(),
-					}
-					facts = append(facts, fact)
-				}
+		// Extract categories
+		pageCats := make([]string, 0)
+		for _, pageCat := range pageContent.Categories {
+			catName := strings.TrimPrefix(pageCat.Title, "Category:")
+			catName = strings.Trim(catName, " ")
+			if catName != "" {
+				pageCats = append(pageCats, catName)
 			}
 		}
+
+		return &RawFact{
+			Content:  content,
+			Source:   "Wikipedia",
+			Category: category,
+			Tags:     pageCats,
+			URLs: []string{
+				fmt.Sprintf("https://en.wikipedia.org/wiki/%s", strings.ReplaceAll(pageContent.Title, " ", "_")),
+			},
+			Metadata: map[string]string{
+				"title":    pageContent.Title,
+				"language": "English",
+			},
+			CollectedAt: time.Now(),
+		}, nil
 	}
 
-	return facts, nil
+	return nil, fmt.Errorf("wikipedia page %q not found", title)
 }
@@ -0,0 +1,86 @@
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// wikipediaServer serves a Science category whose members are listed over
+// two continued requests, and an introduction for every page
+func wikipediaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	members := map[string][]string{
+		"":      {"Atom", "Cell", "Gravity"},
+		"page2": {"Magnetism", "Photon", "Quark"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		response := map[string]interface{}{}
+		switch {
+		case query.Get("list") == "categorymembers":
+			if query.Get("cmtitle") != "Category:Science" {
+				break
+			}
+			var list []map[string]string
+			for _, title := range members[query.Get("cmcontinue")] {
+				list = append(list, map[string]string{"title": title})
+			}
+			response["query"] = map[string]interface{}{"categorymembers": list}
+			if query.Get("cmcontinue") == "" {
+				response["continue"] = map[string]string{"cmcontinue": "page2"}
+			}
+		default:
+			title := query.Get("titles")
+			response["query"] = map[string]interface{}{"pages": map[string]interface{}{
+				"1": map[string]string{
+					"title":   title,
+					"extract": title + " is a subject of science that has been studied for a very long time",
+				},
+			}}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWikipediaSkipsStoredPages(t *testing.T) {
+	source := &WikipediaSource{BaseSource: NewBaseSource(wikipediaServer(t).URL, "")}
+
+	// Science runs low, so it gets more pages than most of its first listing
+	// holds, and those pages are stored already
+	plan := Plan{}
+	for _, category := range source.Categories() {
+		plan[category] = 0
+	}
+	plan["Science"] = 2
+	stored := map[string]bool{"Atom": true, "Cell": true, "Gravity": true, "Magnetism": true}
+	ctx := WithStoredFunc(WithPlan(context.Background(), plan),
+		func(ctx context.Context, source string, titles []string) (map[string]bool, error) {
+			found := make(map[string]bool)
+			for _, title := range titles {
+				found[title] = stored[title]
+			}
+			return found, nil
+		})
+
+	facts, err := source.GetFacts(ctx)
+	if err != nil {
+		t.Fatalf("GetFacts: %v", err)
+	}
+	if len(facts) != 2 {
+		t.Fatalf("got %d facts, want 2", len(facts))
+	}
+	for _, fact := range facts {
+		title := fact.Metadata["title"]
+		if title != "Photon" && title != "Quark" {
+			t.Errorf("got %q, want a page not stored yet", title)
+		}
+		if fact.Category != "Science" {
+			t.Errorf("%s category = %q, want Science", title, fact.Category)
+		}
+	}
+}
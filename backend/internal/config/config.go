@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -10,14 +12,18 @@ import (
 type Config struct {
 	Server   ServerConfig
 	MongoDB  MongoDBConfig
+	Storage  StorageConfig
 	Redis    RedisConfig
+	Cache    CacheConfig
 	API      APIConfig
 	Services ServiceConfig
 }
 
 type ServerConfig struct {
-	Port string
-	Env  string
+	Port            string
+	Env             string
+	ShutdownTimeout time.Duration
+	DrainDelay      time.Duration
 }
 
 type MongoDBConfig struct {
@@ -25,6 +31,13 @@ type MongoDBConfig struct {
 	Database string
 }
 
+// StorageConfig selects where facts and chat sessions are kept: "mongo", or
+// "bolt" for an embedded data file at Path
+type StorageConfig struct {
+	Backend string
+	Path    string
+}
+
 type RedisConfig struct {
 	Host     string
 	Port     string
@@ -32,6 +45,13 @@ type RedisConfig struct {
 	DB       int
 }
 
+// CacheConfig selects where cached values are kept: "redis", falling back to
+// memory when Redis is unreachable, or "memory"
+type CacheConfig struct {
+	Backend    string
+	MaxEntries int // Values held by the in-memory cache
+}
+
 type APIConfig struct {
 	Secret           string
 	AllowedOrigins   string
@@ -39,7 +59,22 @@ type APIConfig struct {
 
 type ServiceConfig struct {
 	FactFetchInterval time.Duration
-	CacheTTL         time.Duration
+	RewriteFacts     bool
+	TextRules        []string
+	FactSchedule     string
+	CatchUp          string
+	InstanceID       string
+	LeaderLeaseTTL   time.Duration
+	LeaderLock       string // Store of the leader lease: "mongo" or "redis"
+	RunRetention     time.Duration
+	CalendarDays     int
+	InventoryTarget  int
+	AlertDays        int
+	StoreBatchSize   int
+	StoreFlush       time.Duration
+	StoreMaxRetries  int
+	StoreWriters     int
+	DefaultTimezone  string
 }
 
 func Load() (*Config, error) {
@@ -55,33 +90,113 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
-	cacheTTL, err := time.ParseDuration(getEnv("CACHE_TTL", "24h"))
+	leaderLeaseTTL, err := time.ParseDuration(getEnv("LEADER_LEASE_TTL", "30s"))
+	if err != nil {
+		return nil, err
+	}
+
+	runRetention, err := time.ParseDuration(getEnv("COLLECTION_RUN_RETENTION", "720h"))
+	if err != nil {
+		return nil, err
+	}
+
+	calendarDays, err := strconv.Atoi(getEnv("CALENDAR_FILL_DAYS", "7"))
+	if err != nil {
+		return nil, err
+	}
+
+	inventoryTarget, err := strconv.Atoi(getEnv("INVENTORY_TARGET", "30"))
+	if err != nil {
+		return nil, err
+	}
+
+	alertDays, err := strconv.Atoi(getEnv("INVENTORY_ALERT_DAYS", "3"))
+	if err != nil {
+		return nil, err
+	}
+
+	storeBatchSize, err := strconv.Atoi(getEnv("STORE_BATCH_SIZE", "50"))
+	if err != nil {
+		return nil, err
+	}
+
+	storeFlush, err := time.ParseDuration(getEnv("STORE_FLUSH_INTERVAL", "1s"))
+	if err != nil {
+		return nil, err
+	}
+
+	storeMaxRetries, err := strconv.Atoi(getEnv("STORE_MAX_RETRIES", "3"))
+	if err != nil {
+		return nil, err
+	}
+
+	storeWriters, err := strconv.Atoi(getEnv("STORE_WRITERS", "2"))
+	if err != nil {
+		return nil, err
+	}
+
+	cacheEntries, err := strconv.Atoi(getEnv("CACHE_MAX_ENTRIES", "10000"))
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownTimeout, err := time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "25s"))
+	if err != nil {
+		return nil, err
+	}
+
+	drainDelay, err := time.ParseDuration(getEnv("SHUTDOWN_DRAIN_DELAY", "3s"))
 	if err != nil {
 		return nil, err
 	}
 
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port:            getEnv("PORT", "8080"),
+			Env:             getEnv("ENV", "development"),
+			ShutdownTimeout: shutdownTimeout,
+			DrainDelay:      drainDelay,
 		},
 		MongoDB: MongoDBConfig{
 			URI:      getEnv("MONGODB_URI", "mongodb://localhost:27017"),
 			Database: getEnv("MONGODB_DATABASE", "one_fact"),
 		},
+		Storage: StorageConfig{
+			Backend: strings.ToLower(getEnv("STORAGE_BACKEND", "mongo")),
+			Path:    getEnv("STORAGE_PATH", "one_fact.db"),
+		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
 			Port:     getEnv("REDIS_PORT", "6379"),
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       0,
 		},
+		Cache: CacheConfig{
+			Backend:    strings.ToLower(getEnv("CACHE_BACKEND", "redis")),
+			MaxEntries: cacheEntries,
+		},
 		API: APIConfig{
 			Secret:         getEnv("API_SECRET", "your_secret_key_here"),
 			AllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "*"),
 		},
 		Services: ServiceConfig{
 			FactFetchInterval: factFetchInterval,
-			CacheTTL:         cacheTTL,
+			RewriteFacts:     getEnv("FACT_REWRITE_ENABLED", "false") == "true",
+			TextRules:        splitList(getEnv("FACT_TEXT_RULES", "")),
+			FactSchedule:     getEnv("FACT_SCHEDULE", "@every "+getEnv("FACT_FETCH_INTERVAL", "24h")),
+			CatchUp:          getEnv("FACT_CATCH_UP", "once"),
+			InstanceID:       getEnv("FLY_ALLOC_ID", ""),
+			LeaderLeaseTTL:   leaderLeaseTTL,
+			LeaderLock:       strings.ToLower(getEnv("LEADER_LOCK", "mongo")),
+			RunRetention:     runRetention,
+			CalendarDays:     calendarDays,
+			InventoryTarget:  inventoryTarget,
+			AlertDays:        alertDays,
+			StoreBatchSize:   storeBatchSize,
+			StoreFlush:       storeFlush,
+			StoreMaxRetries:  storeMaxRetries,
+			StoreWriters:     storeWriters,
+			DefaultTimezone:  getEnv("DEFAULT_TIMEZONE", ""),
 		},
 	}, nil
 }
@@ -92,3 +207,14 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// splitList splits a comma-separated setting, ignoring empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -2,84 +2,74 @@ package database
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"log"
 	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
 )
 
-type Cache struct {
-	client *redis.Client
-	ttl    time.Duration
-}
-
-func NewCache(cfg *config.Config) (*Cache, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	})
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, err
-	}
-
-	return &Cache{
-		client: client,
-		ttl:    cfg.Services.CacheTTL,
-	}, nil
-}
-
-func (c *Cache) Close() error {
-	return c.client.Close()
-}
-
-func (c *Cache) SetDailyFact(ctx context.Context, fact *models.Fact) error {
-	data, err := json.Marshal(fact)
-	if err != nil {
-		return err
-	}
+// Cache backends selectable with CACHE_BACKEND
+const (
+	CacheRedis  = "redis"
+	CacheMemory = "memory"
+)
 
-	key := c.getDailyFactKey()
-	return c.client.Set(ctx, key, data, c.ttl).Err()
+// Cache keeps values that are expensive to compute for a while. Values are
+// stored as JSON, so readers get their own copy.
+type Cache interface {
+	// SetDailyFact caches the daily fact until its day ends
+	SetDailyFact(ctx context.Context, key DailyFactKey, fact *models.Fact, expiresAt time.Time) error
+	// GetDailyFact returns the cached daily fact, or nil when it is not cached
+	GetDailyFact(ctx context.Context, key DailyFactKey) (*models.Fact, error)
+	// ClearDailyFacts forgets the daily fact of a category on a date in
+	// every time zone, so the next requests read it from the publishing calendar
+	ClearDailyFacts(ctx context.Context, date, category string) error
+	// GetJSON decodes the value cached under key into v, and reports whether
+	// there was one
+	GetJSON(ctx context.Context, key string, v interface{}) (bool, error)
+	// SetJSON caches v under key for ttl
+	SetJSON(ctx context.Context, key string, v interface{}, ttl time.Duration) error
+	// IncrementFactServeCount increments the serve count for a fact
+	IncrementFactServeCount(ctx context.Context, factID string) error
+	// GetFactServeCount gets the serve count for a fact
+	GetFactServeCount(ctx context.Context, factID string) (int64, error)
+	Close() error
 }
 
-func (c *Cache) GetDailyFact(ctx context.Context) (*models.Fact, error) {
-	key := c.getDailyFactKey()
-	data, err := c.client.Get(ctx, key).Bytes()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil
+// NewCache creates the configured cache backend. When Redis is configured
+// but unreachable it falls back to an in-memory cache, which is only shared
+// within this process.
+func NewCache(cfg *config.Config) (Cache, error) {
+	switch cfg.Cache.Backend {
+	case CacheRedis:
+		cache, err := NewRedisCache(cfg)
+		if err == nil {
+			return cache, nil
 		}
-		return nil, err
+		log.Printf("Failed to connect to Redis, caching in memory instead: %v", err)
+		return NewMemoryCache(cfg.Cache.MaxEntries), nil
+	case CacheMemory:
+		return NewMemoryCache(cfg.Cache.MaxEntries), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Cache.Backend)
 	}
-
-	var fact models.Fact
-	if err := json.Unmarshal(data, &fact); err != nil {
-		return nil, err
-	}
-
-	return &fact, nil
 }
 
-func (c *Cache) getDailyFactKey() string {
-	return fmt.Sprintf("daily_fact:%s", time.Now().Format("2006-01-02"))
+// DailyFactKey identifies the daily fact of a category on a local date, as
+// cached for the time zone the date was reckoned in, so users whose day
+// starts at the same time share the cached fact.
+type DailyFactKey struct {
+	Date     string
+	Category string
+	Timezone string
 }
 
-// IncrementFactServeCount increments the serve count for a fact
-func (c *Cache) IncrementFactServeCount(ctx context.Context, factID string) error {
-	key := fmt.Sprintf("fact_serve_count:%s", factID)
-	return c.client.Incr(ctx, key).Err()
+func (k DailyFactKey) String() string {
+	return fmt.Sprintf("daily_fact:%s:%s:%s", k.Timezone, k.Date, k.Category)
 }
 
-// GetFactServeCount gets the serve count for a fact
-func (c *Cache) GetFactServeCount(ctx context.Context, factID string) (int64, error) {
-	key := fmt.Sprintf("fact_serve_count:%s", factID)
-	return c.client.Get(ctx, key).Int64()
+func factServeCountKey(factID string) string {
+	return fmt.Sprintf("fact_serve_count:%s", factID)
 }
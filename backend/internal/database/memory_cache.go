@@ -0,0 +1,207 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+// DefaultMemoryCacheEntries is how many values an in-memory cache holds
+// unless configured
+const DefaultMemoryCacheEntries = 10000
+
+// MemoryCache is a Cache kept in the process, for development and single
+// replica deployments without Redis. It holds a bounded number of values and
+// evicts the least recently used one when full. Expired values are dropped
+// when read or evicted.
+type MemoryCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	recency    *list.List // Most recently used first
+	now        func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // Never when zero
+}
+
+// NewMemoryCache creates an in-memory cache of at most maxEntries values, or
+// DefaultMemoryCacheEntries when maxEntries is not positive
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		recency:    list.New(),
+		now:        time.Now,
+	}
+}
+
+// Len returns the number of values held, including expired ones not yet
+// dropped
+func (c *MemoryCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.entries)
+}
+
+func (c *MemoryCache) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.recency.Init()
+	return nil
+}
+
+// get returns the value under key unless it expired, marking it used
+func (c *MemoryCache) get(key string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoryEntry)
+	if c.expired(entry) {
+		c.remove(element)
+		return nil, false
+	}
+	c.recency.MoveToFront(element)
+	return entry.value, true
+}
+
+// set stores a value under key for ttl, or without expiry when ttl is zero
+func (c *MemoryCache) set(key string, value []byte, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.store(key, value, ttl)
+}
+
+// store sets a value with the mutex held, evicting the least recently used
+// values while the cache is full
+func (c *MemoryCache) store(key string, value []byte, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.recency.MoveToFront(element)
+		return
+	}
+
+	for len(c.entries) >= c.maxEntries {
+		c.remove(c.recency.Back())
+	}
+	c.entries[key] = c.recency.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+}
+
+func (c *MemoryCache) expired(entry *memoryEntry) bool {
+	return !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt)
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	c.recency.Remove(element)
+	delete(c.entries, element.Value.(*memoryEntry).key)
+}
+
+// SetDailyFact caches the daily fact until its day ends
+func (c *MemoryCache) SetDailyFact(ctx context.Context, key DailyFactKey, fact *models.Fact, expiresAt time.Time) error {
+	ttl := expiresAt.Sub(c.now())
+	if ttl <= 0 {
+		return nil
+	}
+	return c.SetJSON(ctx, key.String(), fact, ttl)
+}
+
+// GetDailyFact returns the cached daily fact, or nil when it is not cached
+func (c *MemoryCache) GetDailyFact(ctx context.Context, key DailyFactKey) (*models.Fact, error) {
+	var fact models.Fact
+	found, err := c.GetJSON(ctx, key.String(), &fact)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &fact, nil
+}
+
+// ClearDailyFacts forgets the daily fact of a category on a date in every
+// time zone, so the next requests read it from the publishing calendar
+func (c *MemoryCache) ClearDailyFacts(ctx context.Context, date, category string) error {
+	prefix := "daily_fact:"
+	suffix := ":" + date + ":" + category
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, suffix) {
+			c.remove(element)
+		}
+	}
+	return nil
+}
+
+// GetJSON decodes the value cached under key into v, and reports whether
+// there was one
+func (c *MemoryCache) GetJSON(ctx context.Context, key string, v interface{}) (bool, error) {
+	data, ok := c.get(key)
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+// SetJSON caches v under key for ttl
+func (c *MemoryCache) SetJSON(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.set(key, data, ttl)
+	return nil
+}
+
+// IncrementFactServeCount increments the serve count for a fact
+func (c *MemoryCache) IncrementFactServeCount(ctx context.Context, factID string) error {
+	key := factServeCountKey(factID)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, ok := c.entries[key]; ok && !c.expired(element.Value.(*memoryEntry)) {
+		entry := element.Value.(*memoryEntry)
+		count, err := strconv.ParseInt(string(entry.value), 10, 64)
+		if err != nil {
+			return err
+		}
+		entry.value = []byte(strconv.FormatInt(count+1, 10))
+		c.recency.MoveToFront(element)
+		return nil
+	}
+
+	c.store(key, []byte("1"), 0)
+	return nil
+}
+
+// GetFactServeCount gets the serve count for a fact, zero when it was not
+// served
+func (c *MemoryCache) GetFactServeCount(ctx context.Context, factID string) (int64, error) {
+	data, ok := c.get(factServeCountKey(factID))
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
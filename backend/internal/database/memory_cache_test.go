@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	key := DailyFactKey{Date: "2024-03-01", Category: "Science", Timezone: "UTC"}
+	if err := cache.SetDailyFact(ctx, key, &models.Fact{Content: "fact"}, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if fact, err := cache.GetDailyFact(ctx, key); err != nil || fact == nil || fact.Content != "fact" {
+		t.Fatalf("GetDailyFact() = %v, %v, want the cached fact", fact, err)
+	}
+
+	now = now.Add(time.Hour)
+	if fact, err := cache.GetDailyFact(ctx, key); err != nil || fact != nil {
+		t.Errorf("GetDailyFact() after expiry = %v, %v, want nil", fact, err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d, want the expired fact dropped", cache.Len())
+	}
+
+	if err := cache.SetDailyFact(ctx, key, &models.Fact{}, now.Add(-time.Minute)); err != nil || cache.Len() != 0 {
+		t.Errorf("SetDailyFact(past expiry) = %v with %d values, want nothing cached", err, cache.Len())
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(2)
+
+	cache.SetJSON(ctx, "a", 1, 0)
+	cache.SetJSON(ctx, "b", 2, 0)
+	var v int
+	if found, _ := cache.GetJSON(ctx, "a", &v); !found || v != 1 {
+		t.Fatalf("GetJSON(a) = %v, %d, want 1", found, v)
+	}
+	cache.SetJSON(ctx, "c", 3, 0)
+
+	if found, _ := cache.GetJSON(ctx, "b", &v); found {
+		t.Error("b was kept, want it evicted as the least recently used")
+	}
+	for _, key := range []string{"a", "c"} {
+		if found, _ := cache.GetJSON(ctx, key, &v); !found {
+			t.Errorf("%s was evicted, want it kept", key)
+		}
+	}
+}
+
+func TestMemoryCacheClearDailyFacts(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	expires := time.Now().Add(time.Hour)
+
+	keys := []DailyFactKey{
+		{Date: "2024-03-01", Category: "Science", Timezone: "Asia/Tokyo"},
+		{Date: "2024-03-01", Category: "Science", Timezone: "UTC+05:30"},
+		{Date: "2024-03-01", Category: "History", Timezone: "Asia/Tokyo"},
+		{Date: "2024-03-02", Category: "Science", Timezone: "Asia/Tokyo"},
+	}
+	for _, key := range keys {
+		cache.SetDailyFact(ctx, key, &models.Fact{}, expires)
+	}
+
+	if err := cache.ClearDailyFacts(ctx, "2024-03-01", "Science"); err != nil {
+		t.Fatal(err)
+	}
+	for i, key := range keys {
+		fact, _ := cache.GetDailyFact(ctx, key)
+		if cleared := fact == nil; cleared != (i < 2) {
+			t.Errorf("%s cleared = %v, want %v", key, cleared, i < 2)
+		}
+	}
+}
+
+func TestMemoryCacheServeCount(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+
+	if count, err := cache.GetFactServeCount(ctx, "f1"); err != nil || count != 0 {
+		t.Fatalf("GetFactServeCount() = %d, %v, want 0", count, err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := cache.IncrementFactServeCount(ctx, "f1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if count, err := cache.GetFactServeCount(ctx, "f1"); err != nil || count != 3 {
+		t.Errorf("GetFactServeCount() = %d, %v, want 3", count, err)
+	}
+}
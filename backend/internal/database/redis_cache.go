@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/config"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+// RedisCache is a Cache kept in Redis and shared by every replica
+type RedisCache struct {
+	client *redis.Client
+}
+
+func NewRedisCache(cfg *config.Config) (*RedisCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &RedisCache{
+		client: client,
+	}, nil
+}
+
+// Client returns the underlying Redis client
+func (c *RedisCache) Client() *redis.Client {
+	return c.client
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// SetDailyFact caches the daily fact until its day ends
+func (c *RedisCache) SetDailyFact(ctx context.Context, key DailyFactKey, fact *models.Fact, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(fact)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(ctx, key.String(), data, ttl).Err()
+}
+
+// GetDailyFact returns the cached daily fact, or nil when it is not cached
+func (c *RedisCache) GetDailyFact(ctx context.Context, key DailyFactKey) (*models.Fact, error) {
+	data, err := c.client.Get(ctx, key.String()).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var fact models.Fact
+	if err := json.Unmarshal(data, &fact); err != nil {
+		return nil, err
+	}
+
+	return &fact, nil
+}
+
+// ClearDailyFacts forgets the daily fact of a category on a date in every
+// time zone, so the next requests read it from the publishing calendar
+func (c *RedisCache) ClearDailyFacts(ctx context.Context, date, category string) error {
+	pattern := DailyFactKey{Date: date, Category: category, Timezone: "*"}.String()
+	iter := c.client.Scan(ctx, 0, pattern, 100).Iterator()
+
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// GetJSON decodes the value cached under key into v, and reports whether
+// there was one
+func (c *RedisCache) GetJSON(ctx context.Context, key string, v interface{}) (bool, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+// SetJSON caches v under key for ttl
+func (c *RedisCache) SetJSON(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, key, data, ttl).Err()
+}
+
+// IncrementFactServeCount increments the serve count for a fact
+func (c *RedisCache) IncrementFactServeCount(ctx context.Context, factID string) error {
+	return c.client.Incr(ctx, factServeCountKey(factID)).Err()
+}
+
+// GetFactServeCount gets the serve count for a fact
+func (c *RedisCache) GetFactServeCount(ctx context.Context, factID string) (int64, error) {
+	return c.client.Get(ctx, factServeCountKey(factID)).Int64()
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/calendar"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetDailyHistory lists the past daily facts, optionally of one category and
+// between two dates, reckoned in the client's time zone
+func (h *FactHandler) GetDailyHistory(w http.ResponseWriter, r *http.Request) {
+	loc, err := timezoneParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	entries, err := h.factService.GetDailyHistory(r.Context(), query.Get("from"), query.Get("to"), query.Get("category"), loc)
+	if err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+
+	respondJSON(w, entries)
+}
+
+// GetDailyFactOn returns the daily fact of a category on a past date or today
+func (h *FactHandler) GetDailyFactOn(w http.ResponseWriter, r *http.Request) {
+	loc, err := timezoneParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	category := r.URL.Query().Get("category")
+	if category == "" {
+		category = "Technology" // Default category
+	}
+
+	entry, err := h.factService.GetArchivedDailyFact(r.Context(), chi.URLParam(r, "date"), category, loc)
+	if err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+
+	respondJSON(w, entry)
+}
+
+// timezoneParam parses the client's IANA time zone or UTC offset from the tz
+// query parameter, or returns nil when it sends none
+func timezoneParam(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return nil, nil
+	}
+	return calendar.ParseTimezone(tz)
+}
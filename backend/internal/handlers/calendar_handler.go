@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/calendar"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetCalendar lists the facts scheduled in the publishing calendar
+func (h *FactHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	slots, err := h.factService.GetCalendar(r.Context(), query.Get("from"), query.Get("to"), query.Get("category"))
+	if err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+
+	respondJSON(w, slots)
+}
+
+// FillCalendar fills the empty slots of the coming days from the pool
+func (h *FactHandler) FillCalendar(w http.ResponseWriter, r *http.Request) {
+	var fill services.CalendarFill
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&fill); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	filled, err := h.factService.FillCalendar(r.Context(), fill)
+	if err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+
+	respondJSON(w, map[string]int{"filled": filled})
+}
+
+// PinCalendarFact schedules a fact for a category on a day
+func (h *FactHandler) PinCalendarFact(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		FactID string `json:"fact_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	factID, err := primitive.ObjectIDFromHex(request.FactID)
+	if err != nil {
+		http.Error(w, "Invalid fact ID", http.StatusBadRequest)
+		return
+	}
+
+	slot, err := h.factService.PinCalendarFact(r.Context(), chi.URLParam(r, "date"), chi.URLParam(r, "category"), factID)
+	if err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+
+	respondJSON(w, slot)
+}
+
+// ClearCalendarSlot empties a slot so that it is filled from the pool again
+func (h *FactHandler) ClearCalendarSlot(w http.ResponseWriter, r *http.Request) {
+	if err := h.factService.ClearCalendarSlot(r.Context(), chi.URLParam(r, "date"), chi.URLParam(r, "category")); err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SwapCalendarFacts exchanges the facts of a category on two days
+func (h *FactHandler) SwapCalendarFacts(w http.ResponseWriter, r *http.Request) {
+	var swap services.CalendarSwap
+	if err := json.NewDecoder(r.Body).Decode(&swap); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if swap.Category == "" || swap.Date == "" || swap.With == "" {
+		http.Error(w, "category, date and with are required", http.StatusBadRequest)
+		return
+	}
+
+	slots, err := h.factService.SwapCalendarFacts(r.Context(), swap)
+	if err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+
+	respondJSON(w, slots)
+}
+
+// writeCalendarError maps publishing calendar errors to status codes
+func writeCalendarError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, calendar.ErrInvalidDate), errors.Is(err, calendar.ErrPastDate), errors.Is(err, calendar.ErrUnsuitableFact):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, mongo.ErrNoDocuments), errors.Is(err, calendar.ErrNoFacts), errors.Is(err, calendar.ErrNotPublished):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, calendar.ErrAlreadyPinned):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeServiceError(w, err)
+	}
+}
@@ -3,13 +3,17 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ChatHandler struct {
@@ -27,12 +31,15 @@ func NewChatHandler(factService *services.FactService, aiService *services.AISer
 func (h *ChatHandler) RegisterRoutes(r chi.Router) {
 	r.Post("/", h.HandleChat)
 	r.Post("/stream", h.HandleStreamChat)
+	r.Get("/sessions", h.GetChatSessions)
+	r.Get("/sessions/{id}", h.GetChatSession)
 }
 
 // ChatRequest represents the incoming chat request
 type ChatRequest struct {
-	FactID   string            `json:"fact_id"`
-	Messages []models.Message  `json:"messages"`
+	FactID    string           `json:"fact_id"`
+	SessionID string           `json:"session_id,omitempty"` // Session the conversation is saved in; a new one when empty
+	Messages  []models.Message `json:"messages"`
 }
 
 // HandleChat processes AI chat interactions
@@ -55,6 +62,7 @@ func (h *ChatHandler) HandleChat(w http.ResponseWriter, r *http.Request) {
 
 	// Log the fact being used for context
 	fmt.Printf("Using fact for context: %+v\n", fact)
+	h.recordChatOpen(r.Context(), chatRequest, fact)
 
 	// Process the chat interaction
 	response, err := h.processChatWithAI(r.Context(), chatRequest.Messages, fact)
@@ -67,11 +75,31 @@ func (h *ChatHandler) HandleChat(w http.ResponseWriter, r *http.Request) {
 	// Log successful response
 	fmt.Printf("Generated AI response: %s\n", response.Content)
 
+	// Clients continue the session by sending its ID back
+	session, err := h.factService.SaveChat(r.Context(), chatRequest.SessionID, fact, chatRequest.Messages, response)
+	if err != nil {
+		fmt.Printf("Error saving chat session: %v\n", err)
+	} else {
+		w.Header().Set("X-Chat-Session-ID", session.ID.Hex())
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*") // Ensure CORS is enabled
 	json.NewEncoder(w).Encode(response)
 }
 
+// recordChatOpen counts the first message of a chat about a stored fact as
+// engagement with that fact
+func (h *ChatHandler) recordChatOpen(ctx context.Context, req ChatRequest, fact *models.Fact) {
+	// Facts picked as a fallback were not chosen by the user
+	if fact.ID.IsZero() || fact.ID.Hex() != req.FactID || len(req.Messages) != 1 {
+		return
+	}
+	if err := h.factService.RecordEngagement(ctx, fact.ID, models.EngagementChatOpen); err != nil {
+		fmt.Printf("Error recording chat open: %v\n", err)
+	}
+}
+
 // fetchFactForContext retrieves the fact to use as context for the AI
 func (h *ChatHandler) fetchFactForContext(ctx context.Context, factID string) (*models.Fact, error) {
 	// Debug logging
@@ -94,7 +122,7 @@ func (h *ChatHandler) fetchFactForContext(ctx context.Context, factID string) (*
 		fmt.Printf("Error getting random fact: %v\n", err)
 		
 		// Try daily fact as a fallback
-		fact, err = h.factService.GetDailyFact(ctx, "", true)
+		fact, err = h.factService.PickFact(ctx, "")
 		if err != nil {
 			fmt.Printf("Error getting daily fact: %v\n", err)
 			
@@ -157,3 +185,42 @@ func (h *ChatHandler) processChatWithAI(ctx context.Context, messages []models.M
 		Timestamp: time.Now(),
 	}, nil
 }
+
+// GetChatSessions lists saved chat sessions, optionally about one fact or of
+// one user
+func (h *ChatHandler) GetChatSessions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	sessions, err := h.factService.GetChatSessions(r.Context(), storage.ChatFilter{
+		FactID: query.Get("fact_id"),
+		UserID: query.Get("user_id"),
+		Limit:  limit,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, sessions)
+}
+
+// GetChatSession shows a saved chat session with its messages
+func (h *ChatHandler) GetChatSession(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.factService.GetChatSession(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Chat session not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, session)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestChatHandlerSessions(t *testing.T) {
+	service, repo := newTestService(t, models.Fact{Content: "Cats have five toes in front", Category: "Nature", Verified: true})
+	router := chi.NewRouter()
+	NewChatHandler(service, nil).RegisterRoutes(router)
+
+	facts, _ := service.SearchFacts(context.Background(), models.FactQuery{})
+	factID := facts[0].ID.Hex()
+
+	body := `{"fact_id":"` + factID + `","messages":[{"role":"user","content":"Why?"}]}`
+	rec := serve(router, http.MethodPost, "/", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST / = %d %s", rec.Code, rec.Body)
+	}
+	var reply models.Message
+	decode(t, rec, &reply)
+	if reply.Role != "assistant" || !strings.Contains(reply.Content, "Nature") {
+		t.Errorf("reply = %+v, want the fallback answer about the Nature fact", reply)
+	}
+	sessionID := rec.Header().Get("X-Chat-Session-ID")
+	if sessionID == "" {
+		t.Fatal("POST / did not return a session ID")
+	}
+	if fact, _ := repo.Get(context.Background(), facts[0].ID); fact.Metadata.ChatOpens != 1 {
+		t.Errorf("chat opens = %d, want the first message counted", fact.Metadata.ChatOpens)
+	}
+
+	// Continuing the session appends to it
+	body = `{"fact_id":"` + factID + `","session_id":"` + sessionID + `","messages":[{"role":"user","content":"Why?"},{"role":"assistant","content":"Because"},{"role":"user","content":"And?"}]}`
+	if rec := serve(router, http.MethodPost, "/", body); rec.Header().Get("X-Chat-Session-ID") != sessionID {
+		t.Errorf("continued chat saved in %q, want %q", rec.Header().Get("X-Chat-Session-ID"), sessionID)
+	}
+
+	rec = serve(router, http.MethodGet, "/sessions/"+sessionID, "")
+	var session models.ChatSession
+	decode(t, rec, &session)
+	if session.FactID != factID || len(session.Messages) != 4 {
+		t.Errorf("session = %s with %d messages, want %s with 4", session.FactID, len(session.Messages), factID)
+	}
+
+	rec = serve(router, http.MethodGet, "/sessions?fact_id="+factID, "")
+	var sessions []models.ChatSession
+	decode(t, rec, &sessions)
+	if len(sessions) != 1 {
+		t.Errorf("GET /sessions?fact_id = %d sessions, want 1", len(sessions))
+	}
+
+	if rec := serve(router, http.MethodGet, "/sessions/nope", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /sessions/nope = %d, want 400", rec.Code)
+	}
+	if rec := serve(router, http.MethodGet, "/sessions/"+primitive.NewObjectID().Hex(), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET a missing session = %d, want 404", rec.Code)
+	}
+	if rec := serve(router, http.MethodPost, "/", `{`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST / with bad JSON = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scheduler"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// sseKeepAlive is how often an idle event stream is kept alive
+const sseKeepAlive = 15 * time.Second
+
+// TriggerCollection starts a collection job and returns its run right away.
+// Its progress is available from GetCollectionRun.
+func (h *FactHandler) TriggerCollection(w http.ResponseWriter, r *http.Request) {
+	run, err := h.factService.StartCollection(r.Context())
+	if errors.Is(err, scheduler.ErrCollectionRunning) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, scheduler.ErrStopped) {
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", r.URL.Path+"/"+run.ID.Hex())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(run)
+}
+
+// CancelCollection cancels a running collection job
+func (h *FactHandler) CancelCollection(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.factService.CancelCollection(r.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "Run not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, scheduler.ErrNotCancellable) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, run)
+}
+
+// StreamCollectionEvents streams collection progress as server-sent events
+// named after the event type. With a run query parameter only that run's
+// events are sent, and the stream ends when it finishes.
+func (h *FactHandler) StreamCollectionEvents(w http.ResponseWriter, r *http.Request) {
+	var runID primitive.ObjectID
+	if run := r.URL.Query().Get("run"); run != "" {
+		id, err := primitive.ObjectIDFromHex(run)
+		if err != nil {
+			http.Error(w, "Invalid run ID", http.StatusBadRequest)
+			return
+		}
+		runID = id
+	}
+
+	events, unsubscribe, err := h.factService.SubscribeCollectionEvents()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	defer unsubscribe()
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// A run that already finished only gets its outcome
+	if !runID.IsZero() {
+		run, err := h.factService.GetCollectionRun(r.Context(), runID)
+		if err == nil && run.Status != scheduler.RunRunning {
+			sse.Event(scheduler.EventRunFinished, scheduler.Event{
+				Type:  scheduler.EventRunFinished,
+				RunID: run.ID,
+				Run:   run,
+				Time:  run.FinishedAt,
+			})
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	sse.KeepAlive()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.closing:
+			return
+		case <-keepAlive.C:
+			sse.KeepAlive()
+		case event := <-events:
+			if !runID.IsZero() && event.RunID != runID && event.Type != scheduler.EventEventsDropped {
+				continue
+			}
+			if err := sse.Event(event.Type, event); err != nil {
+				return
+			}
+			if !runID.IsZero() && event.Type == scheduler.EventRunFinished {
+				return
+			}
+		}
+	}
+}
+
+// GetCollectionRuns lists collection runs, optionally filtered by trigger,
+// status and source
+func (h *FactHandler) GetCollectionRuns(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, _ := strconv.ParseInt(query.Get("limit"), 10, 64)
+
+	runs, err := h.factService.GetCollectionRuns(r.Context(), scheduler.RunFilter{
+		Trigger: query.Get("trigger"),
+		Status:  query.Get("status"),
+		Source:  query.Get("source"),
+		Limit:   limit,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, runs)
+}
+
+// GetCollectionRun shows one collection run
+func (h *FactHandler) GetCollectionRun(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.factService.GetCollectionRun(r.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "Run not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, run)
+}
+
+// GetInventory lists the stock of facts of every category
+func (h *FactHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
+	inventory, err := h.factService.GetInventory(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, inventory)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DifficultyOverrideRequest is the body of a difficulty override
+type DifficultyOverrideRequest struct {
+	Difficulty string `json:"difficulty"`
+}
+
+// OverrideDifficulty lets an editor correct the computed difficulty of a fact
+func (h *FactHandler) OverrideDifficulty(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req DifficultyOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.factService.OverrideDifficulty(r.Context(), id, req.Difficulty)
+	switch {
+	case errors.Is(err, services.ErrInvalidDifficulty):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, mongo.ErrNoDocuments):
+		http.Error(w, "Fact not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CalibrateDifficulty recalibrates difficulty thresholds from editor labels
+func (h *FactHandler) CalibrateDifficulty(w http.ResponseWriter, r *http.Request) {
+	thresholds, err := h.factService.CalibrateDifficulty(r.Context())
+	if errors.Is(err, processors.ErrNotEnoughSamples) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, thresholds)
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+)
+
+// ExplainFact dry-runs the processing pipeline on raw text or a Wikipedia
+// title and returns the rule hits, score breakdown and stage changes
+func (h *FactHandler) ExplainFact(w http.ResponseWriter, r *http.Request) {
+	var req services.ExplainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	explanation, err := h.factService.ExplainFact(r.Context(), req)
+	if errors.Is(err, services.ErrEmptyExplainRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, explanation)
+}
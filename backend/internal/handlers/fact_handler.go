@@ -2,7 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
@@ -12,17 +18,28 @@ import (
 
 type FactHandler struct {
 	factService *services.FactService
+	closing     chan struct{} // Closed to end open event streams
+	closeOnce   sync.Once
 }
 
 func NewFactHandler(factService *services.FactService) *FactHandler {
 	return &FactHandler{
 		factService: factService,
+		closing:     make(chan struct{}),
 	}
 }
 
+// CloseStreams ends open event streams so the server can shut down without
+// waiting for clients to disconnect
+func (h *FactHandler) CloseStreams() {
+	h.closeOnce.Do(func() { close(h.closing) })
+}
+
 func (h *FactHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/", h.GetAllFacts)
 	r.Get("/daily", h.GetDailyFact)
+	r.Get("/daily/history", h.GetDailyHistory)
+	r.Get("/daily/{date}", h.GetDailyFactOn)
 	r.Get("/random", h.GetRandomFact)
 	r.Get("/search", h.SearchFacts)
 	r.Get("/categories", h.GetCategories)
@@ -31,6 +48,31 @@ func (h *FactHandler) RegisterRoutes(r chi.Router) {
 	r.Put("/{id}", h.UpdateFact)
 	r.Delete("/{id}", h.DeleteFact)
 	r.Post("/collect", h.TriggerCollection)
+	r.Get("/collect/events", h.StreamCollectionEvents)
+	r.Get("/collect/{id}", h.GetCollectionRun)
+	r.Delete("/collect/{id}", h.CancelCollection)
+	r.Put("/{id}/difficulty", h.OverrideDifficulty)
+	r.Post("/difficulty/calibrate", h.CalibrateDifficulty)
+	r.Get("/review", h.GetReviewQueue)
+	r.Post("/{id}/review", h.ResolveReview)
+	r.Post("/classifier/retrain", h.RetrainClassifier)
+	r.Post("/explain", h.ExplainFact)
+	r.Get("/tags/taxonomy", h.GetTagTaxonomy)
+	r.Put("/tags/taxonomy", h.UpdateTagTaxonomy)
+	r.Post("/tags/retag", h.RetagFacts)
+	r.Post("/{id}/engagement", h.RecordEngagement)
+	r.Get("/{id}/score", h.GetScoreBreakdown)
+	r.Post("/scoring/retrain", h.RetrainScoring)
+	r.Get("/sources/schedules", h.GetSourceSchedules)
+	r.Put("/sources/{source}/schedule", h.UpdateSourceSchedule)
+	r.Get("/runs", h.GetCollectionRuns)
+	r.Get("/runs/{id}", h.GetCollectionRun)
+	r.Get("/inventory", h.GetInventory)
+	r.Get("/calendar", h.GetCalendar)
+	r.Post("/calendar/fill", h.FillCalendar)
+	r.Post("/calendar/swap", h.SwapCalendarFacts)
+	r.Put("/calendar/{date}/{category}", h.PinCalendarFact)
+	r.Delete("/calendar/{date}/{category}", h.ClearCalendarSlot)
 }
 
 func (h *FactHandler) GetDailyFact(w http.ResponseWriter, r *http.Request) {
@@ -39,21 +81,82 @@ func (h *FactHandler) GetDailyFact(w http.ResponseWriter, r *http.Request) {
 		category = "Technology" // Default category
 	}
 
+	// The client's IANA time zone or UTC offset decides which day it is
+	loc, err := timezoneParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Secret test mode parameter
 	isTest := r.URL.Query().Get("test_mode") == "true"
 
-	fact, err := h.factService.GetDailyFact(r.Context(), category, isTest)
+	daily, err := h.factService.GetDailyFact(r.Context(), category, loc, isTest)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	respondJSON(w, fact)
+	// Clients keep the fact briefly and then revalidate it, so an editor's
+	// change to the slot reaches them without waiting for the rotation
+	if !isTest {
+		maxAge := min(dailyFactMaxAge, time.Until(daily.NextRotation))
+		etag := dailyFactETag(daily)
+		w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d, must-revalidate", int(maxAge.Seconds())))
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	respondJSON(w, daily)
+}
+
+// dailyFactMaxAge is how long clients may show a daily fact before checking
+// it is still the one scheduled
+const dailyFactMaxAge = 5 * time.Minute
+
+// dailyFactETag identifies a daily fact response, changing when another fact
+// is scheduled or the fact is edited. Both clear the server's cached copy, so
+// the next revalidation sees the change.
+func dailyFactETag(daily *services.DailyFact) string {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%s|%s|%s|%d", daily.ID.Hex(), daily.Date, daily.Timezone, daily.UpdatedAt.UnixNano())
+	return fmt.Sprintf(`"%x"`, hash.Sum64())
+}
+
+// etagMatches reports whether an If-None-Match header matches the entity
+// tag: it is * or a comma-separated list holding the tag, compared weakly so
+// a W/ prefix is ignored. A malformed list matches nothing.
+func etagMatches(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for {
+		header = strings.TrimLeft(header, " \t,")
+		if header == "" {
+			return false
+		}
+		header = strings.TrimPrefix(header, "W/")
+		if !strings.HasPrefix(header, `"`) {
+			return false
+		}
+		end := strings.IndexByte(header[1:], '"')
+		if end < 0 {
+			return false
+		}
+		if header[:end+2] == etag {
+			return true
+		}
+		header = header[end+2:]
+	}
 }
 
 func (h *FactHandler) GetRandomFact(w http.ResponseWriter, r *http.Request) {
 	category := r.URL.Query().Get("category")
-	fact, err := h.factService.GetDailyFact(r.Context(), category, true) // Use test mode to get random fact
+	fact, err := h.factService.PickFact(r.Context(), category)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -180,16 +283,17 @@ func (h *FactHandler) GetAllFacts(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, facts)
 }
 
+// writeServiceError answers 501 for features the storage backend lacks and
+// 500 for any other error
+func writeServiceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrNeedsMongoDB) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
 func respondJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
-
-func (h *FactHandler) TriggerCollection(w http.ResponseWriter, r *http.Request) {
-    ctx := r.Context()
-    if err := h.factService.CollectFacts(ctx); err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
-        return
-    }
-    w.WriteHeader(http.StatusOK)
-}
\ No newline at end of file
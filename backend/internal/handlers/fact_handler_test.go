@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestService creates a fact service over in-memory storage and cache,
+// holding the given facts
+func newTestService(t *testing.T, facts ...models.Fact) (*services.FactService, *storage.MemoryFacts) {
+	t.Helper()
+	repo := storage.NewMemoryFacts()
+	for i := range facts {
+		if err := repo.Insert(context.Background(), &facts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return services.NewStorageFactService(repo, storage.NewMemoryChats(), database.NewMemoryCache(100)), repo
+}
+
+// newFactRouter routes fact requests to a handler over the given facts
+func newFactRouter(t *testing.T, facts ...models.Fact) (http.Handler, *storage.MemoryFacts) {
+	t.Helper()
+	service, repo := newTestService(t, facts...)
+	r := chi.NewRouter()
+	NewFactHandler(service).RegisterRoutes(r)
+	return r, repo
+}
+
+func serve(router http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+}
+
+func TestGetDailyFactHandler(t *testing.T) {
+	router, _ := newFactRouter(t,
+		models.Fact{Content: "Light from the Sun takes 8 minutes", Category: "Space", Verified: true},
+	)
+
+	rec := serve(router, http.MethodGet, "/daily?category=Space&tz=Europe/Paris", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /daily = %d %s", rec.Code, rec.Body)
+	}
+	if cache := rec.Header().Get("Cache-Control"); !strings.HasPrefix(cache, "private, max-age=") || !strings.HasSuffix(cache, "must-revalidate") {
+		t.Errorf("Cache-Control = %q, want a private max-age with revalidation", cache)
+	}
+	etag := rec.Header().Get("ETag")
+	req := httptest.NewRequest(http.MethodGet, "/daily?category=Space&tz=Europe/Paris", nil)
+	req.Header.Set("If-None-Match", etag)
+	revalidated := httptest.NewRecorder()
+	router.ServeHTTP(revalidated, req)
+	if etag == "" || revalidated.Code != http.StatusNotModified {
+		t.Errorf("revalidating ETag %q = %d, want 304", etag, revalidated.Code)
+	}
+	var daily services.DailyFact
+	decode(t, rec, &daily)
+	if daily.Category != "Space" || daily.Timezone != "Europe/Paris" {
+		t.Errorf("daily fact = %s in %s, want Space in Europe/Paris", daily.Category, daily.Timezone)
+	}
+
+	// Editing the fact clears the cached copy, so revalidation sees the edit
+	edit := `{"content": "Light from the Sun takes about 8 minutes to reach the Earth", "category": "Space", "verified": true}`
+	if rec := serve(router, http.MethodPut, "/"+daily.ID.Hex(), edit); rec.Code != http.StatusOK {
+		t.Fatalf("PUT = %d %s", rec.Code, rec.Body)
+	}
+	edited := httptest.NewRecorder()
+	router.ServeHTTP(edited, req)
+	if edited.Code != http.StatusOK || edited.Header().Get("ETag") == etag {
+		t.Errorf("revalidating after an edit = %d with ETag %q, want the edited fact", edited.Code, edited.Header().Get("ETag"))
+	}
+
+	if rec := serve(router, http.MethodGet, "/daily?category=Space&test_mode=true", ""); rec.Header().Get("Cache-Control") != "" {
+		t.Error("test mode response is cacheable")
+	}
+	if rec := serve(router, http.MethodGet, "/daily?tz=Mars/Olympus", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /daily with an unknown zone = %d, want 400", rec.Code)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	const etag = `"5e8f"`
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"5e8f"`, true},
+		{`W/"5e8f"`, true},
+		{`"a", "5e8f"`, true},
+		{`"a",W/"5e8f"`, true},
+		{`*`, true},
+		{``, false},
+		{`"5e8f0"`, false},
+		{`"x5e8f"`, false},
+		{`"a,5e8f"`, false},
+		{`5e8f`, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSearchAndRandomFactHandlers(t *testing.T) {
+	router, _ := newFactRouter(t,
+		models.Fact{Content: "Koalas sleep 20 hours", Category: "Nature", Verified: true, Tags: []string{"animals"}},
+		models.Fact{Content: "The Eiffel Tower grows in summer", Category: "Science", Verified: true},
+		models.Fact{Content: "Pending", Category: "Nature"},
+	)
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"/", 2},
+		{"/search?q=koala", 1},
+		{"/search?tag=animals", 1},
+		{"/search?category=science", 1},
+		{"/category/Nature", 1},
+	}
+	for _, tt := range tests {
+		rec := serve(router, http.MethodGet, tt.target, "")
+		var facts []models.Fact
+		decode(t, rec, &facts)
+		if len(facts) != tt.want {
+			t.Errorf("GET %s = %d facts, want %d", tt.target, len(facts), tt.want)
+		}
+	}
+
+	rec := serve(router, http.MethodGet, "/random?category=Science", "")
+	var fact models.Fact
+	decode(t, rec, &fact)
+	if fact.Content != "The Eiffel Tower grows in summer" {
+		t.Errorf("GET /random = %q, want the verified Science fact", fact.Content)
+	}
+	if rec := serve(router, http.MethodGet, "/random?category=Sports", ""); rec.Code != http.StatusInternalServerError {
+		t.Errorf("GET /random without facts = %d, want 500", rec.Code)
+	}
+}
+
+func TestFactCRUDHandlers(t *testing.T) {
+	router, repo := newFactRouter(t)
+
+	rec := serve(router, http.MethodPost, "/", `{"content":"Bees can recognise faces","category":"Nature","verified":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST / = %d %s", rec.Code, rec.Body)
+	}
+	var added models.Fact
+	decode(t, rec, &added)
+	if added.ID.IsZero() {
+		t.Fatal("POST / returned a fact without an ID")
+	}
+
+	rec = serve(router, http.MethodPut, "/"+added.ID.Hex(), `{"content":"Bees recognise faces","category":"Nature","verified":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT /{id} = %d %s", rec.Code, rec.Body)
+	}
+	if stored, _ := repo.Get(context.Background(), added.ID); stored.Content != "Bees recognise faces" {
+		t.Errorf("stored content = %q after PUT", stored.Content)
+	}
+
+	if rec := serve(router, http.MethodPost, "/", `{`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST / with bad JSON = %d, want 400", rec.Code)
+	}
+	if rec := serve(router, http.MethodPut, "/nope", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT /nope = %d, want 400", rec.Code)
+	}
+	if rec := serve(router, http.MethodDelete, "/nope", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("DELETE /nope = %d, want 400", rec.Code)
+	}
+
+	if rec := serve(router, http.MethodDelete, "/"+added.ID.Hex(), ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE /{id} = %d", rec.Code)
+	}
+	if _, err := repo.Get(context.Background(), added.ID); err != storage.ErrNotFound {
+		t.Errorf("fact still stored after DELETE: %v", err)
+	}
+}
+
+func TestEngagementHandler(t *testing.T) {
+	router, repo := newFactRouter(t, models.Fact{Content: "Otters hold hands", Verified: true})
+	facts, _ := repo.Find(context.Background(), storage.FactFilter{}, storage.FindOptions{})
+	id := facts[0].ID.Hex()
+
+	tests := []struct {
+		target string
+		body   string
+		want   int
+	}{
+		{"/" + id + "/engagement", `{"event":"like"}`, http.StatusNoContent},
+		{"/" + id + "/engagement", `{"event":"frown"}`, http.StatusBadRequest},
+		{"/" + id + "/engagement", `{`, http.StatusBadRequest},
+		{"/nope/engagement", `{"event":"like"}`, http.StatusBadRequest},
+		{"/" + primitive.NewObjectID().Hex() + "/engagement", `{"event":"like"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := serve(router, http.MethodPost, tt.target, tt.body); rec.Code != tt.want {
+			t.Errorf("POST %s %s = %d, want %d", tt.target, tt.body, rec.Code, tt.want)
+		}
+	}
+
+	if stored, _ := repo.Get(context.Background(), facts[0].ID); stored.Metadata.Popularity != 1 {
+		t.Errorf("popularity = %d, want 1", stored.Metadata.Popularity)
+	}
+}
+
+func TestReviewHandlers(t *testing.T) {
+	router, repo := newFactRouter(t,
+		models.Fact{Content: "Held", NeedsReview: true},
+		models.Fact{Content: "Live", Verified: true},
+	)
+
+	rec := serve(router, http.MethodGet, "/review", "")
+	var queue []models.Fact
+	decode(t, rec, &queue)
+	if len(queue) != 1 || queue[0].Content != "Held" {
+		t.Fatalf("GET /review = %+v, want the held fact", queue)
+	}
+
+	target := "/" + queue[0].ID.Hex() + "/review"
+	if rec := serve(router, http.MethodPost, target, `{"approve":true,"category":"History"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("POST %s = %d %s", target, rec.Code, rec.Body)
+	}
+	if stored, _ := repo.Get(context.Background(), queue[0].ID); !stored.Verified || stored.Category != "History" {
+		t.Errorf("approved fact = %+v, want it verified in History", stored)
+	}
+
+	if rec := serve(router, http.MethodPost, "/"+primitive.NewObjectID().Hex()+"/review", `{"approve":true}`); rec.Code != http.StatusNotFound {
+		t.Errorf("reviewing a missing fact = %d, want 404", rec.Code)
+	}
+	if rec := serve(router, http.MethodPost, "/nope/review", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("reviewing an invalid ID = %d, want 400", rec.Code)
+	}
+}
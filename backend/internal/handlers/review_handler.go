@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetReviewQueue lists the facts held for editor review
+func (h *FactHandler) GetReviewQueue(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	facts, err := h.factService.GetReviewQueue(r.Context(), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, facts)
+}
+
+// ResolveReview approves or rejects a fact held for review
+func (h *FactHandler) ResolveReview(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var decision services.ReviewDecision
+	if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.factService.ResolveReview(r.Context(), id, decision)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "Fact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RetrainClassifier retrains the category classifier from verified facts
+func (h *FactHandler) RetrainClassifier(w http.ResponseWriter, r *http.Request) {
+	model, err := h.factService.RetrainClassifier(r.Context())
+	if errors.Is(err, processors.ErrNoTrainingData) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, model)
+}
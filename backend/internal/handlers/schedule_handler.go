@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scheduler"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetSourceSchedules lists the collection schedule of every source
+func (h *FactHandler) GetSourceSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.factService.GetSourceSchedules(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, schedules)
+}
+
+// UpdateSourceSchedule changes the cron expression and catch-up policy of a source
+func (h *FactHandler) UpdateSourceSchedule(w http.ResponseWriter, r *http.Request) {
+	var update services.ScheduleUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.factService.UpdateSourceSchedule(r.Context(), chi.URLParam(r, "source"), update)
+	if errors.Is(err, scheduler.ErrUnknownSource) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, scheduler.ErrInvalidSchedule) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, schedule)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scoring"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RecordEngagement counts a like, chat open or share of a fact
+func (h *FactHandler) RecordEngagement(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Event string `json:"event"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.factService.RecordEngagement(r.Context(), id, req.Event)
+	if errors.Is(err, services.ErrUnknownEngagementEvent) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "Fact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetScoreBreakdown returns the feature contributions to a fact's learned score
+func (h *FactHandler) GetScoreBreakdown(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	breakdown, err := h.factService.GetScoreBreakdown(r.Context(), id)
+	if errors.Is(err, services.ErrNoScoringModel) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "Fact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, breakdown)
+}
+
+// RetrainScoring retrains the scoring model and rescores stored facts
+func (h *FactHandler) RetrainScoring(w http.ResponseWriter, r *http.Request) {
+	model, updated, err := h.factService.RetrainScoring(r.Context())
+	if errors.Is(err, scoring.ErrNotEnoughEngagement) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, map[string]interface{}{
+		"model":    model,
+		"rescored": updated,
+	})
+}
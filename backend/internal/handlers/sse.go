@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// sseWriter writes server-sent events, flushing each one to the client
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newSSEWriter sets the event stream headers. It fails if the connection
+// cannot be flushed.
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("streaming is not supported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	return &sseWriter{w: w, flusher: flusher}, nil
+}
+
+// Data sends an unnamed event with a line of text
+func (s *sseWriter) Data(data string) {
+	fmt.Fprintf(s.w, "data: %s\n\n", data)
+	s.flusher.Flush()
+}
+
+// Event sends a named event with a JSON payload
+func (s *sseWriter) Event(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data)
+	s.flusher.Flush()
+	return nil
+}
+
+// KeepAlive sends a comment so proxies do not close an idle stream
+func (s *sseWriter) KeepAlive() {
+	fmt.Fprint(s.w, ": keep-alive\n\n")
+	s.flusher.Flush()
+}
@@ -27,12 +27,14 @@ func (h *ChatHandler) HandleStreamChat(w http.ResponseWriter, r *http.Request) {
 
 	// Log the fact being used for context
 	fmt.Printf("Using fact for streaming context: %+v\n", fact)
+	h.recordChatOpen(r.Context(), chatRequest, fact)
 
 	// Set headers for SSE
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	// Process the chat interaction with streaming
 	if h.aiService == nil {
@@ -40,17 +42,14 @@ func (h *ChatHandler) HandleStreamChat(w http.ResponseWriter, r *http.Request) {
 		fmt.Printf("AI service is nil, using fallback for streaming\n")
 		
 		// Send a simple message
-		fmt.Fprintf(w, "data: I'm sorry, but the AI service is currently unavailable. \n\n")
-		w.(http.Flusher).Flush()
+		sse.Data("I'm sorry, but the AI service is currently unavailable. ")
 		
 		time.Sleep(500 * time.Millisecond)
 		
-		fmt.Fprintf(w, "data: Please try again later or contact support if this issue persists.\n\n")
-		w.(http.Flusher).Flush()
+		sse.Data("Please try again later or contact support if this issue persists.")
 		
 		// Send completion signal
-		fmt.Fprintf(w, "data: [DONE]\n\n")
-		w.(http.Flusher).Flush()
+		sse.Data("[DONE]")
 		return
 	}
 
@@ -60,11 +59,9 @@ func (h *ChatHandler) HandleStreamChat(w http.ResponseWriter, r *http.Request) {
 		fmt.Printf("Error in AI streaming: %v\n", err)
 		
 		// Try to send an error message if possible
-		fmt.Fprintf(w, "data: I encountered an error while processing your request: %s\n\n", err.Error())
-		w.(http.Flusher).Flush()
+		sse.Data("I encountered an error while processing your request: " + err.Error())
 		
 		// Send completion signal
-		fmt.Fprintf(w, "data: [DONE]\n\n")
-		w.(http.Flusher).Flush()
+		sse.Data("[DONE]")
 	}
 }
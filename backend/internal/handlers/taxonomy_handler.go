@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+)
+
+// GetTagTaxonomy returns the curated tag taxonomy
+func (h *FactHandler) GetTagTaxonomy(w http.ResponseWriter, r *http.Request) {
+	taxonomy, err := h.factService.GetTagTaxonomy(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, taxonomy)
+}
+
+// UpdateTagTaxonomy replaces the curated tag taxonomy
+func (h *FactHandler) UpdateTagTaxonomy(w http.ResponseWriter, r *http.Request) {
+	var taxonomy processors.TagTaxonomy
+	if err := json.NewDecoder(r.Body).Decode(&taxonomy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.factService.UpdateTagTaxonomy(r.Context(), &taxonomy)
+	if errors.Is(err, processors.ErrInvalidTaxonomy) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, taxonomy)
+}
+
+// RetagFacts remaps the tags of stored facts through the current taxonomy
+func (h *FactHandler) RetagFacts(w http.ResponseWriter, r *http.Request) {
+	updated, err := h.factService.RetagFacts(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, map[string]int{"updated": updated})
+}
@@ -0,0 +1,136 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// releaseTimeout bounds releasing the lease after the leader's context ended
+const releaseTimeout = 5 * time.Second
+
+// Elector campaigns for a lease and runs a job while it holds it
+type Elector struct {
+	lock   Lock
+	holder string
+	ttl    time.Duration
+	mutex  sync.Mutex
+	token  int64
+}
+
+// NewElector creates an elector for the lock. The lease is renewed every
+// third of ttl, so a leader that stops renewing is replaced within ttl.
+func NewElector(lock Lock, holder string, ttl time.Duration) *Elector {
+	return &Elector{lock: lock, holder: holder, ttl: ttl}
+}
+
+// DefaultHolder identifies this process among the replicas
+func DefaultHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Holder returns the name this elector campaigns under
+func (e *Elector) Holder() string {
+	return e.holder
+}
+
+// Token returns the fencing token of the lease, or 0 when this replica is
+// not the leader
+func (e *Elector) Token() int64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.token
+}
+
+func (e *Elector) setToken(token int64) {
+	e.mutex.Lock()
+	e.token = token
+	e.mutex.Unlock()
+}
+
+// Run campaigns for the lease until the context is cancelled. Each time the
+// lease is won, lead runs with a context that is cancelled when the lease is
+// lost. The lease is released once lead has returned, so another replica can
+// take over right away on shutdown.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context, token int64)) error {
+	interval := e.ttl / 3
+	for {
+		token, err := e.lock.Acquire(ctx, e.holder, e.ttl)
+		if err == nil {
+			log.Printf("%s became leader with token %d", e.holder, token)
+			e.lead(ctx, token, lead)
+		} else if !errors.Is(err, ErrNotAcquired) && ctx.Err() == nil {
+			log.Printf("Error campaigning for leadership: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// lead runs the job and renews the lease until the job returns, the lease is
+// lost or the context is cancelled
+func (e *Elector) lead(ctx context.Context, token int64, lead func(ctx context.Context, token int64)) {
+	e.setToken(token)
+	defer e.setToken(0)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leaderCtx, token)
+	}()
+
+	interval := e.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+renew:
+	for {
+		select {
+		case <-done:
+			break renew
+		case <-ctx.Done():
+			break renew
+		case <-ticker.C:
+			err := e.lock.Renew(ctx, e.holder, token, e.ttl)
+			if err == nil {
+				renewed = time.Now()
+				continue
+			}
+			if errors.Is(err, ErrLeaseLost) {
+				log.Printf("%s lost leadership", e.holder)
+				break renew
+			}
+			// Step down before the lease can expire under a running job
+			if time.Since(renewed) >= e.ttl-interval {
+				log.Printf("%s stepping down, lease not renewed: %v", e.holder, err)
+				break renew
+			}
+			log.Printf("Error renewing leadership: %v", err)
+		}
+	}
+
+	cancel()
+	<-done
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancelRelease()
+	if err := e.lock.Release(releaseCtx, e.holder, token); err != nil {
+		log.Printf("Error releasing leadership: %v", err)
+		return
+	}
+	log.Printf("%s released leadership", e.holder)
+}
@@ -0,0 +1,178 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryLock is a Lock kept in memory for tests
+type memoryLock struct {
+	mutex     sync.Mutex
+	holder    string
+	token     int64
+	expiresAt time.Time
+	renewErr  error
+}
+
+func (l *memoryLock) Acquire(ctx context.Context, holder string, ttl time.Duration) (int64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.holder != holder && l.holder != "" && time.Now().Before(l.expiresAt) {
+		return 0, ErrNotAcquired
+	}
+	if l.holder != holder {
+		l.token++
+		l.holder = holder
+	}
+	l.expiresAt = time.Now().Add(ttl)
+	return l.token, nil
+}
+
+func (l *memoryLock) Renew(ctx context.Context, holder string, token int64, ttl time.Duration) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.renewErr != nil {
+		return l.renewErr
+	}
+	if l.holder != holder || l.token != token {
+		return ErrLeaseLost
+	}
+	l.expiresAt = time.Now().Add(ttl)
+	return nil
+}
+
+func (l *memoryLock) Release(ctx context.Context, holder string, token int64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.holder == holder && l.token == token {
+		l.holder = ""
+	}
+	return nil
+}
+
+func (l *memoryLock) steal(holder string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.token++
+	l.holder = holder
+	l.expiresAt = time.Now().Add(time.Hour)
+}
+
+const testTTL = 30 * time.Millisecond
+
+func TestElectorRunsOneLeaderAndHandsOver(t *testing.T) {
+	lock := &memoryLock{}
+	leading := make(chan int64, 4)
+
+	var mutex sync.Mutex
+	active := 0
+	job := func(ctx context.Context, token int64) {
+		mutex.Lock()
+		active++
+		if active > 1 {
+			t.Error("two leaders at once")
+		}
+		mutex.Unlock()
+		leading <- token
+		<-ctx.Done()
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+	}
+
+	ctxA, stopA := context.WithCancel(context.Background())
+	doneA := make(chan struct{})
+	a := NewElector(lock, "a", testTTL)
+	go func() { a.Run(ctxA, job); close(doneA) }()
+
+	first := <-leading
+	if a.Token() != first {
+		t.Errorf("token = %d, want %d", a.Token(), first)
+	}
+
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	b := NewElector(lock, "b", testTTL)
+	go b.Run(ctxB, job)
+
+	// b must not lead while a renews its lease
+	select {
+	case <-leading:
+		t.Fatal("b became leader while a held the lease")
+	case <-time.After(3 * testTTL):
+	}
+
+	// Shutting a down hands over to b
+	stopA()
+	<-doneA
+	if a.Token() != 0 {
+		t.Errorf("token after shutdown = %d, want 0", a.Token())
+	}
+
+	select {
+	case second := <-leading:
+		if second <= first {
+			t.Errorf("fencing token %d after %d", second, first)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("b did not take over")
+	}
+}
+
+func TestElectorStepsDownWhenLeaseIsLost(t *testing.T) {
+	lock := &memoryLock{}
+	stopped := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{}, 1)
+	var once sync.Once
+	go NewElector(lock, "a", testTTL).Run(ctx, func(ctx context.Context, token int64) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		once.Do(func() { close(stopped) })
+	})
+
+	<-started
+	lock.steal("b")
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("leader kept running after losing its lease")
+	}
+}
+
+func TestElectorStepsDownWhenRenewalFails(t *testing.T) {
+	lock := &memoryLock{}
+	stopped := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{}, 1)
+	var once sync.Once
+	go NewElector(lock, "a", testTTL).Run(ctx, func(ctx context.Context, token int64) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		once.Do(func() { close(stopped) })
+	})
+
+	<-started
+	lock.mutex.Lock()
+	lock.renewErr = context.DeadlineExceeded
+	lock.mutex.Unlock()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("leader kept running without renewing its lease")
+	}
+}
@@ -0,0 +1,41 @@
+// Package leader elects a single replica to run background jobs. Replicas
+// compete for a lease that expires unless its holder renews it, and every
+// acquisition hands out a fencing token that is larger than any token handed
+// out before, so writes from a leader that lost its lease can be refused.
+package leader
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Stores a lease can be kept in. Every replica must use the same one.
+const (
+	LockMongo = "mongo"
+	LockRedis = "redis"
+)
+
+var (
+	// ErrNotAcquired is returned when another holder has the lease
+	ErrNotAcquired = errors.New("lease held by another replica")
+
+	// ErrLeaseLost is returned when renewing a lease that expired and was
+	// taken over, or that was released
+	ErrLeaseLost = errors.New("lease lost")
+)
+
+// Lock is a named lease shared by all replicas
+type Lock interface {
+	// Acquire takes the lease for ttl if it is free or expired and returns
+	// its fencing token. A holder acquiring a lease it already has keeps its
+	// token.
+	Acquire(ctx context.Context, holder string, ttl time.Duration) (int64, error)
+
+	// Renew extends the lease held with the given token by ttl
+	Renew(ctx context.Context, holder string, token int64, ttl time.Duration) error
+
+	// Release frees the lease held with the given token so another replica
+	// can take over without waiting for it to expire
+	Release(ctx context.Context, holder string, token int64) error
+}
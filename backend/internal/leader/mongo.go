@@ -0,0 +1,91 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoLock is a lease stored as a document in a leases collection. It is
+// the fallback when Redis is unavailable. Expiry uses the MongoDB server's
+// clock, so replica clocks do not need to agree.
+type MongoLock struct {
+	collection *mongo.Collection
+	name       string
+}
+
+// NewMongoLock creates the lease with the given name
+func NewMongoLock(collection *mongo.Collection, name string) *MongoLock {
+	return &MongoLock{collection: collection, name: name}
+}
+
+// Acquire takes the lease if it is expired or already held by the holder.
+// The lease document is kept after release so tokens keep increasing.
+func (l *MongoLock) Acquire(ctx context.Context, holder string, ttl time.Duration) (int64, error) {
+	filter := bson.M{
+		"_id": l.name,
+		"$or": bson.A{
+			bson.M{"holder": holder},
+			bson.M{"$expr": bson.M{"$lte": bson.A{"$expires_at", "$$NOW"}}},
+		},
+	}
+	// A new token is the larger of the last token plus one and the server
+	// clock in milliseconds, matching the Redis lock
+	newToken := bson.M{"$max": bson.A{
+		bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$token", 0}}, 1}},
+		bson.M{"$toLong": "$$NOW"},
+	}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"token":      bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$holder", holder}}, "$token", newToken}},
+		"holder":     holder,
+		"expires_at": bson.M{"$add": bson.A{"$$NOW", ttl.Milliseconds()}},
+	}}}}
+
+	var lease struct {
+		Token int64 `bson:"token"`
+	}
+	err := l.collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&lease)
+	if mongo.IsDuplicateKeyError(err) {
+		// The lease exists and is held by someone else
+		return 0, ErrNotAcquired
+	}
+	if err != nil {
+		return 0, fmt.Errorf("acquiring lease %s: %w", l.name, err)
+	}
+	return lease.Token, nil
+}
+
+// Renew extends the lease if it is still held with the token
+func (l *MongoLock) Renew(ctx context.Context, holder string, token int64, ttl time.Duration) error {
+	result, err := l.collection.UpdateOne(ctx,
+		bson.M{"_id": l.name, "holder": holder, "token": token},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"expires_at": bson.M{"$add": bson.A{"$$NOW", ttl.Milliseconds()}},
+		}}}},
+	)
+	if err != nil {
+		return fmt.Errorf("renewing lease %s: %w", l.name, err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release expires the lease if it is still held with the token
+func (l *MongoLock) Release(ctx context.Context, holder string, token int64) error {
+	_, err := l.collection.UpdateOne(ctx,
+		bson.M{"_id": l.name, "holder": holder, "token": token},
+		bson.M{"$set": bson.M{"holder": "", "expires_at": time.Unix(0, 0)}},
+	)
+	if err != nil {
+		return fmt.Errorf("releasing lease %s: %w", l.name, err)
+	}
+	return nil
+}
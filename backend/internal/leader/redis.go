@@ -0,0 +1,95 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Tokens are the larger of the last token plus one and the Redis clock in
+// milliseconds, so they keep increasing when replicas switch between the
+// Redis and Mongo locks
+var acquireScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+	local sep = string.find(current, ':')
+	if string.sub(current, sep + 1) == ARGV[1] then
+		redis.call('PEXPIRE', KEYS[1], ARGV[2])
+		return tonumber(string.sub(current, 1, sep - 1))
+	end
+	return 0
+end
+local now = redis.call('TIME')
+local token = math.max((tonumber(redis.call('GET', KEYS[2])) or 0) + 1, now[1] * 1000 + math.floor(now[2] / 1000))
+redis.call('SET', KEYS[2], token)
+redis.call('SET', KEYS[1], token .. ':' .. ARGV[1], 'PX', ARGV[2])
+return token
+`)
+
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisLock is a lease stored in a Redis key that expires with the lease
+type RedisLock struct {
+	client   *redis.Client
+	key      string
+	tokenKey string
+}
+
+// NewRedisLock creates the lease with the given name
+func NewRedisLock(client *redis.Client, name string) *RedisLock {
+	return &RedisLock{
+		client:   client,
+		key:      "leader:" + name,
+		tokenKey: "leader:" + name + ":token",
+	}
+}
+
+// Acquire takes the lease if no other holder has it
+func (l *RedisLock) Acquire(ctx context.Context, holder string, ttl time.Duration) (int64, error) {
+	token, err := acquireScript.Run(ctx, l.client, []string{l.key, l.tokenKey}, holder, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("acquiring %s: %w", l.key, err)
+	}
+	if token == 0 {
+		return 0, ErrNotAcquired
+	}
+	return token, nil
+}
+
+// Renew extends the lease if it is still held with the token
+func (l *RedisLock) Renew(ctx context.Context, holder string, token int64, ttl time.Duration) error {
+	renewed, err := renewScript.Run(ctx, l.client, []string{l.key}, leaseValue(holder, token), ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("renewing %s: %w", l.key, err)
+	}
+	if renewed == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release deletes the lease if it is still held with the token
+func (l *RedisLock) Release(ctx context.Context, holder string, token int64) error {
+	if err := releaseScript.Run(ctx, l.client, []string{l.key}, leaseValue(holder, token)).Err(); err != nil {
+		return fmt.Errorf("releasing %s: %w", l.key, err)
+	}
+	return nil
+}
+
+func leaseValue(holder string, token int64) string {
+	return fmt.Sprintf("%d:%s", token, holder)
+}
@@ -0,0 +1,163 @@
+// Package lifecycle runs the HTTP server until the process is told to stop,
+// then shuts the server and its dependencies down in order.
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Lifecycle states reported by the health endpoints
+const (
+	StateStarting = "starting"
+	StateServing  = "serving"
+	StateDraining = "draining"
+	StateStopped  = "stopped"
+)
+
+// hook is a named step of the shutdown
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Manager serves HTTP until its context is cancelled, then drains in-flight
+// requests and runs the shutdown hooks in the order they were added, all
+// within one deadline
+type Manager struct {
+	server     *http.Server
+	timeout    time.Duration
+	drainDelay time.Duration
+
+	mutex sync.Mutex
+	state string
+	hooks []hook
+}
+
+// New creates a manager for the server. On shutdown it reports draining for
+// drainDelay so load balancers stop sending traffic, then gives requests and
+// hooks until timeout to finish.
+func New(server *http.Server, timeout, drainDelay time.Duration) *Manager {
+	return &Manager{
+		server:     server,
+		timeout:    timeout,
+		drainDelay: drainDelay,
+		state:      StateStarting,
+	}
+}
+
+// OnShutdown adds a step to run after the HTTP server has drained
+func (m *Manager) OnShutdown(name string, stop func(ctx context.Context) error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// State returns the current lifecycle state
+func (m *Manager) State() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.state
+}
+
+func (m *Manager) setState(state string) {
+	m.mutex.Lock()
+	m.state = state
+	m.mutex.Unlock()
+	log.Printf("Server %s", state)
+}
+
+// Run listens on the server's address and serves until ctx is cancelled,
+// then shuts down
+func (m *Manager) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", m.server.Addr)
+	if err != nil {
+		return err
+	}
+	return m.Serve(ctx, listener)
+}
+
+// Serve serves on the listener until ctx is cancelled or the server fails,
+// then shuts down. It returns the first error met.
+func (m *Manager) Serve(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- m.server.Serve(listener)
+	}()
+	m.setState(StateServing)
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-serveErr:
+		log.Printf("Server failed: %v", err)
+	}
+
+	if shutdownErr := m.shutdown(); err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+// shutdown drains the server and runs the hooks within the timeout
+func (m *Manager) shutdown() error {
+	m.setState(StateDraining)
+	deadline, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	// Keep serving while load balancers notice the failing readiness check
+	select {
+	case <-time.After(m.drainDelay):
+	case <-deadline.Done():
+	}
+
+	var errs []error
+	if err := m.server.Shutdown(deadline); err != nil {
+		// Cut off the requests that did not finish in time
+		m.server.Close()
+		errs = append(errs, fmt.Errorf("draining HTTP: %w", err))
+	}
+
+	m.mutex.Lock()
+	hooks := append([]hook(nil), m.hooks...)
+	m.mutex.Unlock()
+
+	for _, h := range hooks {
+		log.Printf("Stopping %s", h.name)
+		if err := h.stop(deadline); err != nil {
+			errs = append(errs, fmt.Errorf("stopping %s: %w", h.name, err))
+		}
+	}
+
+	m.setState(StateStopped)
+	return errors.Join(errs...)
+}
+
+// Health reports the lifecycle state. It answers 200 while the process is
+// alive, draining included.
+func (m *Manager) Health(w http.ResponseWriter, r *http.Request) {
+	m.respond(w, http.StatusOK)
+}
+
+// Ready reports the lifecycle state and answers 503 unless the server is
+// serving, so load balancers stop routing to a draining replica
+func (m *Manager) Ready(w http.ResponseWriter, r *http.Request) {
+	status := http.StatusOK
+	if m.State() != StateServing {
+		status = http.StatusServiceUnavailable
+	}
+	m.respond(w, status)
+}
+
+func (m *Manager) respond(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"status": m.State()})
+}
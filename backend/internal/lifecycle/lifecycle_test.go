@@ -0,0 +1,142 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdownDrainsThenStopsInOrder(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	manager := New(&http.Server{Handler: mux}, 5*time.Second, 0)
+
+	var mutex sync.Mutex
+	var stopped []string
+	for _, name := range []string{"scheduler", "mongodb", "redis"} {
+		name := name
+		manager.OnShutdown(name, func(ctx context.Context) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			stopped = append(stopped, name)
+			return nil
+		})
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- manager.Serve(ctx, listener) }()
+
+	// Start a request, then ask the server to stop while it is in flight
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+	<-started
+	cancel()
+
+	waitForState(t, manager, StateDraining)
+	recorder := httptest.NewRecorder()
+	manager.Ready(recorder, httptest.NewRequest("GET", "/readyz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Ready while draining = %d", recorder.Code)
+	}
+	recorder = httptest.NewRecorder()
+	manager.Health(recorder, httptest.NewRequest("GET", "/healthz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Health while draining = %d", recorder.Code)
+	}
+
+	mutex.Lock()
+	if len(stopped) != 0 {
+		t.Errorf("hooks ran before HTTP drained: %v", stopped)
+	}
+	mutex.Unlock()
+
+	close(release)
+	if body := <-response; body != "done" {
+		t.Errorf("in-flight request got %q", body)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve() = %v", err)
+	}
+
+	if manager.State() != StateStopped {
+		t.Errorf("State() = %s", manager.State())
+	}
+	if len(stopped) != 3 || stopped[0] != "scheduler" || stopped[1] != "mongodb" || stopped[2] != "redis" {
+		t.Errorf("stopped = %v", stopped)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	started := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stuck", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	manager := New(&http.Server{Handler: mux}, 50*time.Millisecond, 0)
+	hookErr := errors.New("still running")
+	manager.OnShutdown("jobs", func(ctx context.Context) error {
+		<-ctx.Done()
+		return hookErr
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- manager.Serve(ctx, listener) }()
+
+	go http.Get("http://" + listener.Addr().String() + "/stuck")
+	<-started
+	cancel()
+
+	select {
+	case err := <-served:
+		if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, hookErr) {
+			t.Errorf("Serve() = %v, want the drain and hook errors", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shutdown did not respect its deadline")
+	}
+}
+
+func waitForState(t *testing.T, manager *Manager, state string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if manager.State() == state {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("state = %s, want %s", manager.State(), state)
+}
@@ -1,34 +1,132 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// CurrentFactSchemaVersion is the version of the fact schema written by the
+// API and the collection pipeline. Documents with a lower version are
+// rewritten by the migrate-facts admin command. Version 2 added the content
+// hash of facts without a title.
+const CurrentFactSchemaVersion = 2
+
+// Fact is the canonical fact document stored in the facts collection
 type Fact struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SchemaVersion int             `bson:"schema_version" json:"-"`
 	Content     string            `bson:"content" json:"content"`
+	ContentHash string            `bson:"content_hash,omitempty" json:"-"` // Identifies facts without a title, see SetContentHash
 	Category    string            `bson:"category" json:"category"`
 	Source      string            `bson:"source" json:"source"`
 	Tags        []string          `bson:"tags" json:"tags"`
 	Verified    bool              `bson:"verified" json:"verified"`
+	NeedsReview bool              `bson:"needs_review" json:"needs_review"`
+	ReviewReasons []string        `bson:"review_reasons,omitempty" json:"review_reasons,omitempty"`
+	CategoryScores map[string]float64 `bson:"category_scores,omitempty" json:"category_scores,omitempty"`
+	Verification *Verification    `bson:"verification,omitempty" json:"verification,omitempty"`
+	Score       float64           `bson:"score" json:"score"`
 	CreatedAt   time.Time         `bson:"created_at" json:"created_at"`
 	UpdatedAt   time.Time         `bson:"updated_at" json:"updated_at"`
+	PublishDate time.Time         `bson:"publish_date,omitempty" json:"publish_date,omitempty"` // Day the publishing calendar schedules the fact for
 	RelatedURLs []string          `bson:"related_urls" json:"related_urls"`
 	Metadata    FactMetadata      `bson:"metadata" json:"metadata"`
 }
 
 type FactMetadata struct {
+	Title       string   `bson:"title,omitempty" json:"title,omitempty"`
 	Language    string   `bson:"language" json:"language"`
 	Difficulty  string   `bson:"difficulty" json:"difficulty"`
+	DifficultySource string `bson:"difficulty_source,omitempty" json:"difficulty_source,omitempty"`
 	References  []string `bson:"references" json:"references"`
 	Keywords    []string `bson:"keywords" json:"keywords"`
-	Popularity  int      `bson:"popularity" json:"popularity"`
+	Popularity  int      `bson:"popularity" json:"popularity"` // Number of likes
 	LastServed  time.Time `bson:"last_served" json:"last_served"`
 	ServeCount  int      `bson:"serve_count" json:"serve_count"`
+	ServedDate  string   `bson:"served_date,omitempty" json:"-"` // Date of the daily fact slot last counted, without a calendar
+	ChatOpens   int      `bson:"chat_opens" json:"chat_opens"`
+	Shares      int      `bson:"shares" json:"shares"`
+	SourceCategory string `bson:"source_category,omitempty" json:"source_category,omitempty"` // Category the source collected the fact from
+	OriginalContent string `bson:"original_content,omitempty" json:"original_content,omitempty"`
+	RewriteRejected string `bson:"rewrite_rejected,omitempty" json:"rewrite_rejected,omitempty"`
+}
+
+// SetContentHash hashes the content of a fact without a title, which is how
+// collected duplicates of it are found, and clears the hash of one with a
+// title, since those are found by source and title
+func (f *Fact) SetContentHash() {
+	if f.Metadata.Title != "" {
+		f.ContentHash = ""
+		return
+	}
+	sum := sha256.Sum256([]byte(f.Content))
+	f.ContentHash = hex.EncodeToString(sum[:])
+}
+
+// SendToReview holds the fact back from serving until an editor approves it
+func (f *Fact) SendToReview(reason string) {
+	f.Verified = false
+	f.NeedsReview = true
+	f.ReviewReasons = append(f.ReviewReasons, reason)
 }
 
+// Verification records how a fact's claim was checked against its source
+type Verification struct {
+	Status     string        `bson:"status" json:"status"`
+	Method     string        `bson:"method" json:"method"`
+	Confidence float64       `bson:"confidence" json:"confidence"`
+	Rationale  string        `bson:"rationale" json:"rationale"`
+	Checks     []CheckResult `bson:"checks,omitempty" json:"checks,omitempty"`
+	CheckedAt  time.Time     `bson:"checked_at" json:"checked_at"`
+}
+
+// CheckResult is the outcome of one deterministic verification check
+type CheckResult struct {
+	Name   string `bson:"name" json:"name"`
+	Passed bool   `bson:"passed" json:"passed"`
+	Detail string `bson:"detail,omitempty" json:"detail,omitempty"`
+}
+
+// Verification statuses. Only passed and approved facts are verified.
+const (
+	VerificationPassed    = "passed"
+	VerificationFailed    = "failed"
+	VerificationUncertain = "uncertain"
+	VerificationApproved  = "approved"
+)
+
+// Verification methods
+const (
+	VerificationMethodVerbatim = "verbatim"
+	VerificationMethodJudge    = "llm_judge"
+	VerificationMethodChecks   = "checks"
+	VerificationMethodEditor   = "editor"
+)
+
+// Difficulty levels stored in FactMetadata.Difficulty
+const (
+	DifficultyEasy   = "Easy"
+	DifficultyMedium = "Medium"
+	DifficultyHard   = "Hard"
+)
+
+// Difficulty sources record whether a difficulty was computed by the
+// processor or set by an editor
+const (
+	DifficultySourceComputed = "computed"
+	DifficultySourceEditor   = "editor"
+)
+
+// Engagement events recorded against a fact
+const (
+	EngagementLike     = "like"
+	EngagementChatOpen = "chat_open"
+	EngagementShare    = "share"
+)
+
 type FactQuery struct {
 	Category    string    `json:"category"`
 	Tags        []string  `json:"tags"`
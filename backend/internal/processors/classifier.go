@@ -0,0 +1,241 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const categoryModelID = "category_model"
+
+// DefaultCategoryConfidence is the probability below which a predicted
+// category is sent to review
+const DefaultCategoryConfidence = 0.6
+
+// MinCategoryDocuments is the number of training facts a category needs to be
+// included in the model
+const MinCategoryDocuments = 3
+
+// categoryEvidence is how many words' worth of evidence a fact contributes.
+// Naive Bayes posteriors saturate quickly on long texts, so the average word
+// likelihood is scaled to this length to keep the confidence meaningful.
+const categoryEvidence = 10
+
+// ErrNoTrainingData is returned when no category has enough training facts
+var ErrNoTrainingData = errors.New("not enough verified facts to train the category classifier")
+
+// CategoryStats holds the word counts of one category in the model
+type CategoryStats struct {
+	Documents int            `json:"documents" bson:"documents"`
+	Words     int            `json:"words" bson:"words"`
+	Counts    map[string]int `json:"-" bson:"counts"`
+}
+
+// CategoryModel is a multinomial naive Bayes model over fact words and tags
+type CategoryModel struct {
+	Categories map[string]*CategoryStats `json:"categories" bson:"categories"`
+	Vocabulary int                       `json:"vocabulary" bson:"vocabulary"`
+	Documents  int                       `json:"documents" bson:"documents"`
+	TrainedAt  time.Time                 `json:"trained_at" bson:"trained_at"`
+}
+
+// CategoryDocument is a verified fact used to train the model
+type CategoryDocument struct {
+	Content  string
+	Tags     []string
+	Category string
+}
+
+// CategoryProbability is the predicted probability of one category
+type CategoryProbability struct {
+	Category    string  `json:"category"`
+	Probability float64 `json:"probability"`
+}
+
+// categoryFeatures turns a fact into classifier features: its content words
+// without stopwords, plus the words of its tags
+func categoryFeatures(content string, tags []string) []string {
+	stop := stopwords[DefaultLanguage]
+	split := func(text string) []string {
+		return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+	}
+
+	var features []string
+	for _, word := range split(content) {
+		if len(word) > 2 && !stop[word] {
+			features = append(features, word)
+		}
+	}
+	for _, tag := range tags {
+		for _, word := range split(tag) {
+			if len(word) > 2 && !stop[word] {
+				features = append(features, "tag_"+word)
+			}
+		}
+	}
+	return features
+}
+
+// TrainCategoryModel trains a model on the given documents. Categories with
+// fewer than MinCategoryDocuments documents are left out.
+func TrainCategoryModel(docs []CategoryDocument) (*CategoryModel, error) {
+	perCategory := make(map[string]int)
+	for _, doc := range docs {
+		perCategory[doc.Category]++
+	}
+
+	model := &CategoryModel{
+		Categories: make(map[string]*CategoryStats),
+		TrainedAt:  time.Now(),
+	}
+	vocabulary := make(map[string]bool)
+
+	for _, doc := range docs {
+		if doc.Category == "" || perCategory[doc.Category] < MinCategoryDocuments {
+			continue
+		}
+
+		stats, ok := model.Categories[doc.Category]
+		if !ok {
+			stats = &CategoryStats{Counts: make(map[string]int)}
+			model.Categories[doc.Category] = stats
+		}
+
+		stats.Documents++
+		model.Documents++
+		for _, feature := range categoryFeatures(doc.Content, doc.Tags) {
+			stats.Counts[feature]++
+			stats.Words++
+			vocabulary[feature] = true
+		}
+	}
+
+	if len(model.Categories) == 0 {
+		return nil, ErrNoTrainingData
+	}
+
+	model.Vocabulary = len(vocabulary)
+	return model, nil
+}
+
+// Predict returns the probability of each category for a fact, most likely first
+func (m *CategoryModel) Predict(content string, tags []string) []CategoryProbability {
+	features := categoryFeatures(content, tags)
+
+	logits := make(map[string]float64, len(m.Categories))
+	maxLogit := math.Inf(-1)
+	for category, stats := range m.Categories {
+		// Laplace-smoothed average log likelihood of the fact's words
+		var likelihood float64
+		for _, feature := range features {
+			likelihood += math.Log(float64(stats.Counts[feature]+1) / float64(stats.Words+m.Vocabulary))
+		}
+		if len(features) > 0 {
+			likelihood = likelihood / float64(len(features)) * categoryEvidence
+		}
+
+		logit := math.Log(float64(stats.Documents)/float64(m.Documents)) + likelihood
+		logits[category] = logit
+		maxLogit = math.Max(maxLogit, logit)
+	}
+
+	var total float64
+	probabilities := make([]CategoryProbability, 0, len(logits))
+	for category, logit := range logits {
+		p := math.Exp(logit - maxLogit)
+		total += p
+		probabilities = append(probabilities, CategoryProbability{Category: category, Probability: p})
+	}
+	for i := range probabilities {
+		probabilities[i].Probability /= total
+	}
+
+	sort.Slice(probabilities, func(i, j int) bool {
+		if probabilities[i].Probability == probabilities[j].Probability {
+			return probabilities[i].Category < probabilities[j].Category
+		}
+		return probabilities[i].Probability > probabilities[j].Probability
+	})
+	return probabilities
+}
+
+// LoadCategoryModel reads the trained model from the settings collection. It
+// returns nil if no model has been trained yet.
+func LoadCategoryModel(ctx context.Context, settings *mongo.Collection) (*CategoryModel, error) {
+	var model CategoryModel
+	err := settings.FindOne(ctx, bson.M{"_id": categoryModelID}).Decode(&model)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading category model: %w", err)
+	}
+	return &model, nil
+}
+
+// SaveCategoryModel stores a trained model in the settings collection
+func SaveCategoryModel(ctx context.Context, settings *mongo.Collection, model *CategoryModel) error {
+	_, err := settings.ReplaceOne(ctx,
+		bson.M{"_id": categoryModelID},
+		model,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("saving category model: %w", err)
+	}
+	return nil
+}
+
+// CategoryStage assigns the most likely category using the trained model and
+// sends facts below the confidence threshold to review. Without a model the
+// category normalized from the source is kept.
+type CategoryStage struct {
+	model      *CategoryModel
+	confidence float64
+}
+
+// NewCategoryStage creates a category stage. The model may be nil.
+func NewCategoryStage(model *CategoryModel, confidence float64) *CategoryStage {
+	return &CategoryStage{model: model, confidence: confidence}
+}
+
+// Name returns the stage name
+func (s *CategoryStage) Name() string {
+	return "category"
+}
+
+// Apply predicts the fact's category and records the probabilities
+func (s *CategoryStage) Apply(ctx context.Context, fact *models.Fact) error {
+	if s.model == nil {
+		return nil
+	}
+
+	probabilities := s.model.Predict(fact.Content, fact.Tags)
+	if len(probabilities) == 0 {
+		return nil
+	}
+
+	fact.CategoryScores = make(map[string]float64, len(probabilities))
+	for _, p := range probabilities {
+		fact.CategoryScores[p.Category] = p.Probability
+	}
+
+	best := probabilities[0]
+	fact.Category = best.Category
+	if best.Probability < s.confidence {
+		fact.SendToReview(fmt.Sprintf("low category confidence (%.0f%% %s)", best.Probability*100, best.Category))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+func trainingDocs() []CategoryDocument {
+	return []CategoryDocument{
+		{Category: "Science", Content: "Photosynthesis converts light energy into chemical energy in plant cells."},
+		{Category: "Science", Content: "Electrons orbit the atomic nucleus in quantized energy levels."},
+		{Category: "Science", Content: "Chemical reactions rearrange atoms into new molecules."},
+		{Category: "Science", Content: "Cells divide through mitosis to produce identical daughter cells."},
+		{Category: "Sports", Content: "The football team won the championship match in extra time."},
+		{Category: "Sports", Content: "The tennis player won the tournament final in straight sets."},
+		{Category: "Sports", Content: "The marathon runner set a world record at the championship."},
+		{Category: "Sports", Content: "The basketball team scored a record number of points in the match."},
+		{Category: "History", Content: "The treaty ended the war."},
+	}
+}
+
+func TestTrainCategoryModelSkipsSmallCategories(t *testing.T) {
+	model, err := TrainCategoryModel(trainingDocs())
+	if err != nil {
+		t.Fatalf("Error training model: %v", err)
+	}
+
+	if _, ok := model.Categories["History"]; ok {
+		t.Error("Expected History to be left out with a single training fact")
+	}
+	if model.Documents != 8 {
+		t.Errorf("Expected 8 training documents, got %d", model.Documents)
+	}
+}
+
+func TestCategoryModelPredict(t *testing.T) {
+	model, err := TrainCategoryModel(trainingDocs())
+	if err != nil {
+		t.Fatalf("Error training model: %v", err)
+	}
+
+	probabilities := model.Predict("The team won the championship final.", nil)
+	if probabilities[0].Category != "Sports" {
+		t.Errorf("Expected Sports, got %+v", probabilities)
+	}
+
+	var total float64
+	for _, p := range probabilities {
+		total += p.Probability
+	}
+	if total < 0.999 || total > 1.001 {
+		t.Errorf("Expected probabilities to sum to 1, got %f", total)
+	}
+}
+
+func TestCategoryStageSendsUncertainFactsToReview(t *testing.T) {
+	model, err := TrainCategoryModel(trainingDocs())
+	if err != nil {
+		t.Fatalf("Error training model: %v", err)
+	}
+
+	fact := &models.Fact{Content: "The museum opened in a historic building.", Verified: true}
+	if err := NewCategoryStage(model, 0.99).Apply(context.Background(), fact); err != nil {
+		t.Fatalf("Error applying stage: %v", err)
+	}
+
+	if !fact.NeedsReview || fact.Verified {
+		t.Error("Expected an uncertain fact to be held for review")
+	}
+	if len(fact.CategoryScores) != 2 {
+		t.Errorf("Expected scores for 2 categories, got %v", fact.CategoryScores)
+	}
+}
+
+func TestCategoryStageWithoutModel(t *testing.T) {
+	fact := &models.Fact{Content: "The team won.", Category: "Sports", Verified: true}
+	if err := NewCategoryStage(nil, DefaultCategoryConfidence).Apply(context.Background(), fact); err != nil {
+		t.Fatalf("Error applying stage: %v", err)
+	}
+
+	if fact.Category != "Sports" || fact.NeedsReview {
+		t.Errorf("Expected fact to be unchanged without a model, got %+v", fact)
+	}
+}
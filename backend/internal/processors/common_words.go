@@ -0,0 +1,56 @@
+package processors
+
+import "strings"
+
+// commonWords is a list of frequent English words. Words outside this list
+// count towards the rare-word ratio used for difficulty estimation.
+var commonWords = func() map[string]bool {
+	words := strings.Fields(`
+	a about above across act action actually add after again against age ago air
+	all almost alone along already also although always am among an and animal
+	another answer any anyone anything appear are area around art as ask at away
+	back bad base be became because become been before began begin behind being
+	believe below best better between big black blue body book both boy bring
+	brought build building built business but by call called came can car care
+	carry case cause center century certain change child children city class
+	clear close cold color come common company complete could country course
+	cover cross cut dark day decide deep did different do does dog done door
+	down draw during each early earth east easy eat end enough even ever every
+	example eye face fact family far farm fast father feel feet few field find
+	fine fire first fish five follow food foot for force form found four free
+	friend from front full game gave general get girl give go good got
+	government great green ground group grow had half hand happen hard has have
+	he head hear heard heart heat held help her here high him his history hold
+	home horse hot hour house how however human hundred idea if important in
+	include including inside into is island it its just keep kind king know
+	known land language large last late later lead learn least leave left less
+	let life light like line list little live long look lot love low made main
+	make man many map mark may me mean measure men might mile mind modern money
+	month more morning most mother mountain move much music must my name nation
+	national natural near need never new next night no north not nothing notice
+	now number of off often old on once one only open or order other our out
+	over own page paper part party pass past people perhaps person picture piece
+	place plan plant play point population power present problem produce public
+	pull put question quick quite rain ran reach read ready real reason record
+	red region remain remember rest result right river road rock room round rule
+	run said same saw say school science sea second see seem seen self sentence
+	serve set several shape she ship short should show side simple since sing
+	sit six size small so some something sometimes song soon sound south space
+	speak special stand star start state stay step still stop story street
+	strong study such sun sure system table take talk team tell ten than that
+	the their them then there these they thing think third this those though
+	thought thousand three through time to today together told too took top
+	toward town tree true try turn two under understand unit until up upon us
+	use used usually very voice wait walk wall want war warm was watch water way
+	we week well went were west what when where whether which while white who
+	whole why wide will wind with within without woman women wood word work
+	world would write written year yes yet you young your largest world's named
+	located capital county film album series player species genus born founded
+	established released
+`)
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}()
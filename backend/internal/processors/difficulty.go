@@ -0,0 +1,201 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const difficultyThresholdsID = "difficulty_thresholds"
+
+// MinCalibrationSamples is the smallest labeled sample we calibrate against
+const MinCalibrationSamples = 10
+
+// ErrNotEnoughSamples is returned when calibration has too few labeled facts
+var ErrNotEnoughSamples = errors.New("not enough labeled samples to calibrate difficulty")
+
+// DifficultyThresholds maps a readability index onto difficulty levels.
+// Facts with an index below MediumAt are Easy, below HardAt are Medium and
+// everything else is Hard.
+type DifficultyThresholds struct {
+	MediumAt     float64   `json:"medium_at" bson:"medium_at"`
+	HardAt       float64   `json:"hard_at" bson:"hard_at"`
+	SampleSize   int       `json:"sample_size" bson:"sample_size"`
+	Accuracy     float64   `json:"accuracy" bson:"accuracy"`
+	CalibratedAt time.Time `json:"calibrated_at" bson:"calibrated_at"`
+}
+
+// DefaultDifficultyThresholds are used until a calibration has been saved
+func DefaultDifficultyThresholds() DifficultyThresholds {
+	return DifficultyThresholds{
+		MediumAt: 12,
+		HardAt:   18,
+	}
+}
+
+// DifficultyIndex combines readability metrics into a single number. The grade
+// level dominates, with rare words and numbers pushing a fact harder.
+func DifficultyIndex(r Readability) float64 {
+	return r.FleschKincaidGrade + 10*r.RareWordRatio + 15*r.NumericDensity
+}
+
+// Classify returns the difficulty level for the given readability metrics
+func (t DifficultyThresholds) Classify(r Readability) string {
+	index := DifficultyIndex(r)
+	switch {
+	case index < t.MediumAt:
+		return models.DifficultyEasy
+	case index < t.HardAt:
+		return models.DifficultyMedium
+	default:
+		return models.DifficultyHard
+	}
+}
+
+// DifficultySample is a fact text with a difficulty assigned by an editor
+type DifficultySample struct {
+	Content    string
+	Difficulty string
+}
+
+// CalibrateDifficulty picks the thresholds that classify the most labeled
+// samples correctly. Candidate cut points are the midpoints between the
+// sorted sample indices.
+func CalibrateDifficulty(samples []DifficultySample) (DifficultyThresholds, error) {
+	type point struct {
+		index float64
+		level int
+	}
+
+	levels := map[string]int{
+		models.DifficultyEasy:   0,
+		models.DifficultyMedium: 1,
+		models.DifficultyHard:   2,
+	}
+
+	points := make([]point, 0, len(samples))
+	for _, sample := range samples {
+		level, ok := levels[sample.Difficulty]
+		if !ok {
+			continue
+		}
+		points = append(points, point{
+			index: DifficultyIndex(MeasureReadability(sample.Content)),
+			level: level,
+		})
+	}
+
+	if len(points) < MinCalibrationSamples {
+		return DifficultyThresholds{}, ErrNotEnoughSamples
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].index < points[j].index
+	})
+
+	// prefix[k][i] counts samples of level k among the first i points
+	n := len(points)
+	var prefix [3][]int
+	for k := range prefix {
+		prefix[k] = make([]int, n+1)
+	}
+	for i, p := range points {
+		for k := range prefix {
+			prefix[k][i+1] = prefix[k][i]
+		}
+		prefix[p.level][i+1]++
+	}
+
+	// Points before cut i are Easy, between cuts i and j are Medium and
+	// the rest are Hard
+	best, bestI, bestJ := -1, 0, 0
+	for i := 0; i <= n; i++ {
+		if i > 0 && i < n && points[i].index == points[i-1].index {
+			continue
+		}
+		for j := i; j <= n; j++ {
+			if j > 0 && j < n && points[j].index == points[j-1].index {
+				continue
+			}
+			correct := prefix[0][i] + (prefix[1][j] - prefix[1][i]) + (prefix[2][n] - prefix[2][j])
+			if correct > best {
+				best, bestI, bestJ = correct, i, j
+			}
+		}
+	}
+
+	cut := func(i int) float64 {
+		switch {
+		case i == 0:
+			return points[0].index - 1
+		case i == n:
+			return points[n-1].index + 1
+		default:
+			return (points[i-1].index + points[i].index) / 2
+		}
+	}
+
+	return DifficultyThresholds{
+		MediumAt:     cut(bestI),
+		HardAt:       cut(bestJ),
+		SampleSize:   n,
+		Accuracy:     float64(best) / float64(n),
+		CalibratedAt: time.Now(),
+	}, nil
+}
+
+// LoadDifficultyThresholds reads the calibrated thresholds from the settings
+// collection, falling back to the defaults if none have been saved
+func LoadDifficultyThresholds(ctx context.Context, settings *mongo.Collection) (DifficultyThresholds, error) {
+	var thresholds DifficultyThresholds
+	err := settings.FindOne(ctx, bson.M{"_id": difficultyThresholdsID}).Decode(&thresholds)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return DefaultDifficultyThresholds(), nil
+	}
+	if err != nil {
+		return DifficultyThresholds{}, fmt.Errorf("loading difficulty thresholds: %w", err)
+	}
+	return thresholds, nil
+}
+
+// SaveDifficultyThresholds stores calibrated thresholds in the settings collection
+func SaveDifficultyThresholds(ctx context.Context, settings *mongo.Collection, thresholds DifficultyThresholds) error {
+	_, err := settings.ReplaceOne(ctx,
+		bson.M{"_id": difficultyThresholdsID},
+		thresholds,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("saving difficulty thresholds: %w", err)
+	}
+	return nil
+}
+
+// DifficultyStage assigns a difficulty level based on readability metrics
+type DifficultyStage struct {
+	thresholds DifficultyThresholds
+}
+
+// NewDifficultyStage creates a difficulty stage using the given thresholds
+func NewDifficultyStage(thresholds DifficultyThresholds) *DifficultyStage {
+	return &DifficultyStage{thresholds: thresholds}
+}
+
+// Name returns the stage name
+func (s *DifficultyStage) Name() string {
+	return "difficulty"
+}
+
+// Apply classifies the fact content and records the difficulty in its metadata
+func (s *DifficultyStage) Apply(ctx context.Context, fact *models.Fact) error {
+	fact.Metadata.Difficulty = s.thresholds.Classify(MeasureReadability(fact.Content))
+	fact.Metadata.DifficultySource = models.DifficultySourceComputed
+	return nil
+}
@@ -0,0 +1,102 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+func TestMeasureReadability(t *testing.T) {
+	r := MeasureReadability("The cat sat on the mat. It was 3.5 years old.")
+
+	if r.Sentences != 2 {
+		t.Errorf("Expected 2 sentences, got %d", r.Sentences)
+	}
+	if r.Words != 11 {
+		t.Errorf("Expected 11 words, got %d", r.Words)
+	}
+	if r.NumericDensity == 0 {
+		t.Error("Expected numeric density for a text containing a number")
+	}
+}
+
+func TestDifficultyIndexOrdersTexts(t *testing.T) {
+	easy := MeasureReadability("The dog ran home. It was a good day.")
+	hard := MeasureReadability("Photosynthetic eukaryotes sequester atmospheric carbon through enzymatically mediated carboxylation, consuming 1,400 kilojoules.")
+
+	if DifficultyIndex(easy) >= DifficultyIndex(hard) {
+		t.Errorf("Expected easy text to score below hard text, got %.2f >= %.2f",
+			DifficultyIndex(easy), DifficultyIndex(hard))
+	}
+}
+
+func TestCalibrateDifficulty(t *testing.T) {
+	easy := []string{
+		"The sun is a star.",
+		"Dogs like to run and play.",
+		"The sea is blue and very big.",
+		"Cats can sleep for most of the day.",
+	}
+	medium := []string{
+		"The Amazon rainforest produces a significant share of the oxygen in the atmosphere.",
+		"Honeybees communicate the location of flowers through an elaborate waggle dance.",
+		"Mount Everest grows a few millimetres taller every year because of tectonic movement.",
+	}
+	hard := []string{
+		"Mitochondrial deoxyribonucleic acid is inherited exclusively through matrilineal descent in mammals.",
+		"Superconductivity manifests below critical temperatures, eliminating electrical resistivity entirely.",
+		"Quantum chromodynamics characterises interactions between quarks mediated by gluons.",
+	}
+
+	var samples []DifficultySample
+	for _, text := range easy {
+		samples = append(samples, DifficultySample{Content: text, Difficulty: models.DifficultyEasy})
+	}
+	for _, text := range medium {
+		samples = append(samples, DifficultySample{Content: text, Difficulty: models.DifficultyMedium})
+	}
+	for _, text := range hard {
+		samples = append(samples, DifficultySample{Content: text, Difficulty: models.DifficultyHard})
+	}
+
+	thresholds, err := CalibrateDifficulty(samples)
+	if err != nil {
+		t.Fatalf("Error calibrating: %v", err)
+	}
+
+	if thresholds.MediumAt > thresholds.HardAt {
+		t.Errorf("Expected MediumAt <= HardAt, got %.2f > %.2f", thresholds.MediumAt, thresholds.HardAt)
+	}
+	if thresholds.Accuracy < 0.8 {
+		t.Errorf("Expected accuracy of at least 80%%, got %.2f", thresholds.Accuracy)
+	}
+
+	for _, sample := range samples[:len(easy)] {
+		if got := thresholds.Classify(MeasureReadability(sample.Content)); got != models.DifficultyEasy {
+			t.Errorf("Expected %q to be Easy, got %s", sample.Content, got)
+		}
+	}
+}
+
+func TestCalibrateDifficultyNeedsSamples(t *testing.T) {
+	_, err := CalibrateDifficulty([]DifficultySample{{Content: "The sun is a star.", Difficulty: models.DifficultyEasy}})
+	if err != ErrNotEnoughSamples {
+		t.Errorf("Expected ErrNotEnoughSamples, got %v", err)
+	}
+}
+
+func TestDifficultyStage(t *testing.T) {
+	fact := &models.Fact{Content: "The sun is a star."}
+
+	if err := NewDifficultyStage(DefaultDifficultyThresholds()).Apply(context.Background(), fact); err != nil {
+		t.Fatalf("Error applying stage: %v", err)
+	}
+
+	if fact.Metadata.Difficulty != models.DifficultyEasy {
+		t.Errorf("Expected Easy, got %q", fact.Metadata.Difficulty)
+	}
+	if fact.Metadata.DifficultySource != models.DifficultySourceComputed {
+		t.Errorf("Expected computed difficulty source, got %q", fact.Metadata.DifficultySource)
+	}
+}
@@ -0,0 +1,140 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+// Explanation decisions
+const (
+	DecisionAccepted      = "accepted"
+	DecisionHeldForReview = "held_for_review"
+	DecisionRejected      = "rejected"
+	DecisionError         = "error"
+)
+
+// RuleHit is the outcome of one validation rule
+type RuleHit struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ScoreComponent is one part of the quality score
+type ScoreComponent struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// FieldChange is the value of a fact field before and after a stage
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// StageTrace records the fields a stage changed, keyed by their JSON path
+type StageTrace struct {
+	Stage   string                 `json:"stage"`
+	Changes map[string]FieldChange `json:"changes,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// Explanation describes how the processor handled a raw fact, for tuning the
+// pipeline without storing anything
+type Explanation struct {
+	Input          collectors.RawFact `json:"input"`
+	Normalized     string             `json:"normalized,omitempty"` // Content after the text rules, when they changed it
+	Rules          []RuleHit          `json:"rules"`
+	Score          float64            `json:"score"`
+	MinScore       float64            `json:"min_score"`
+	ScoreBreakdown []ScoreComponent   `json:"score_breakdown,omitempty"`
+	Stages         []StageTrace       `json:"stages,omitempty"`
+	Decision       string             `json:"decision"`
+	Reason         string             `json:"reason,omitempty"`
+	Fact           *models.Fact       `json:"fact,omitempty"`
+}
+
+// Explain runs a raw fact through the whole pipeline and reports every rule,
+// score component and stage change along with the final decision
+func (p *Processor) Explain(ctx context.Context, raw collectors.RawFact) *Explanation {
+	explanation := &Explanation{Input: raw, MinScore: p.minScore}
+
+	fact, _, err := p.process(ctx, raw, explanation)
+	switch {
+	case err != nil:
+		explanation.Decision = DecisionError
+		explanation.Reason = err.Error()
+	case fact == nil:
+		explanation.Decision = DecisionRejected
+		if explanation.Reason != "" {
+			break // Rejected by a stage
+		}
+		explanation.Reason = "score below minimum"
+		for _, rule := range explanation.Rules {
+			if !rule.Passed {
+				explanation.Reason = rule.Rule + ": " + rule.Detail
+				break
+			}
+		}
+	case fact.NeedsReview:
+		explanation.Decision = DecisionHeldForReview
+		explanation.Fact = fact
+	default:
+		explanation.Decision = DecisionAccepted
+		explanation.Fact = fact
+	}
+	return explanation
+}
+
+// snapshot flattens a fact into JSON paths so stage changes can be diffed
+func snapshot(fact *models.Fact) map[string]interface{} {
+	data, err := json.Marshal(fact)
+	if err != nil {
+		return nil
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	flat := make(map[string]interface{})
+	flatten("", doc, flat)
+	return flat
+}
+
+func flatten(prefix string, doc map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range doc {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && key != "category_scores" {
+			flatten(path, nested, flat)
+			continue
+		}
+		flat[path] = value
+	}
+}
+
+func diffSnapshots(before, after map[string]interface{}) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	for path, value := range after {
+		if old, ok := before[path]; !ok || !reflect.DeepEqual(old, value) {
+			changes[path] = FieldChange{Before: before[path], After: value}
+		}
+	}
+	for path, value := range before {
+		if _, ok := after[path]; !ok {
+			changes[path] = FieldChange{Before: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
@@ -0,0 +1,107 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+func TestExplainRejectedByRule(t *testing.T) {
+	raw := collectors.RawFact{Content: "The battle is remembered because the general was killed in the first hour of fighting."}
+
+	explanation := NewProcessor().Explain(context.Background(), raw)
+	if explanation.Decision != DecisionRejected {
+		t.Fatalf("Decision = %s, want %s", explanation.Decision, DecisionRejected)
+	}
+	if explanation.Reason != `banned_word: contains "killed"` {
+		t.Errorf("Reason = %q", explanation.Reason)
+	}
+	if explanation.Fact != nil {
+		t.Error("Rejected explanation should not include a fact")
+	}
+}
+
+func TestExplainTracesStages(t *testing.T) {
+	raw := collectors.RawFact{
+		Content:  "The Eiffel Tower was completed in 1889 and was the tallest man-made structure in the world for 41 years.",
+		Category: "History",
+		Tags:     []string{"Towers", "Paris"},
+		URLs:     []string{"https://en.wikipedia.org/wiki/Eiffel_Tower"},
+	}
+
+	processor := NewProcessor()
+	processor.Use(NewDifficultyStage(DefaultDifficultyThresholds()))
+
+	explanation := processor.Explain(context.Background(), raw)
+	if explanation.Decision != DecisionAccepted {
+		t.Fatalf("Decision = %s (%s), want %s", explanation.Decision, explanation.Reason, DecisionAccepted)
+	}
+
+	// base 1.0, length 0, category 0.1, tags 0.1, urls 0.2, metadata 0
+	var total float64
+	for _, c := range explanation.ScoreBreakdown {
+		total += c.Value
+	}
+	if total != explanation.Score || explanation.Score < 1.39 || explanation.Score > 1.41 {
+		t.Errorf("Score = %v, breakdown sums to %v", explanation.Score, total)
+	}
+
+	if len(explanation.Stages) != 1 || explanation.Stages[0].Stage != "difficulty" {
+		t.Fatalf("Stages = %+v", explanation.Stages)
+	}
+	change, ok := explanation.Stages[0].Changes["metadata.difficulty"]
+	if !ok || change.Before != "" || change.After != explanation.Fact.Metadata.Difficulty {
+		t.Errorf("difficulty change = %+v", change)
+	}
+	if _, ok := explanation.Stages[0].Changes["content"]; ok {
+		t.Error("Unchanged content should not be traced")
+	}
+}
+
+type rejectStage struct{}
+
+func (rejectStage) Name() string { return "reject" }
+
+func (rejectStage) Apply(ctx context.Context, fact *models.Fact) error {
+	return fmt.Errorf("%w: not wanted", ErrRejected)
+}
+
+func TestExplainRejectedByStage(t *testing.T) {
+	raw := collectors.RawFact{Content: "The Eiffel Tower was completed in 1889 and is located in Paris, France."}
+
+	processor := NewProcessor()
+	processor.Use(rejectStage{})
+
+	fact, err := processor.Process(context.Background(), raw)
+	if err != nil || fact != nil {
+		t.Fatalf("Process() = %v, %v, want a rejection", fact, err)
+	}
+
+	explanation := processor.Explain(context.Background(), raw)
+	if explanation.Decision != DecisionRejected || explanation.Reason != "reject stage: rejected: not wanted" {
+		t.Errorf("Decision = %s, Reason = %q", explanation.Decision, explanation.Reason)
+	}
+}
+
+func TestReviewReasons(t *testing.T) {
+	processor := NewProcessor()
+	processor.Use(rejectStage{})
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"Too short.", "length"},
+		{"The battle is remembered because the general was killed in the first hour of fighting.", "banned_word"},
+		{"The Eiffel Tower was completed in 1889 and is located in Paris, France.", "reject"},
+	}
+	for _, tt := range tests {
+		fact, reason, err := processor.Review(context.Background(), collectors.RawFact{Content: tt.content})
+		if err != nil || fact != nil || reason != tt.want {
+			t.Errorf("Review(%q) = %v, %q, %v, want reason %q", tt.content, fact, reason, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultLanguage is assumed for facts without a language in their metadata
+const DefaultLanguage = "English"
+
+// DefaultKeywordLimit is the number of keywords kept per fact
+const DefaultKeywordLimit = 8
+
+// maxPhraseWords is the longest keyphrase we extract
+const maxPhraseWords = 3
+
+// DocumentFrequencies tracks how many facts in the corpus contain each term,
+// per language
+type DocumentFrequencies interface {
+	// Lookup returns the number of documents and the document frequency of
+	// each of the given terms
+	Lookup(ctx context.Context, language string, terms []string) (int64, map[string]int64, error)
+	// Add records a new document containing the given terms
+	Add(ctx context.Context, language string, terms []string) error
+	// Remove forgets a document containing the given terms
+	Remove(ctx context.Context, language string, terms []string) error
+}
+
+// Keyword is a term with its TF-IDF weight in a fact
+type Keyword struct {
+	Term   string  `json:"term"`
+	Weight float64 `json:"weight"`
+}
+
+// candidate is a term occurring in a text, with the surface form of its first
+// occurrence so that "Ada Lovelace" keeps its capitalization
+type candidate struct {
+	term    string
+	surface string
+	count   int
+	words   int
+}
+
+// Terms returns the distinct terms of a text, i.e. words and keyphrases of up
+// to three words that do not cross punctuation or stopwords
+func Terms(text, language string) []string {
+	candidates := extractCandidates(text, language)
+	terms := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		terms = append(terms, c.term)
+	}
+	return terms
+}
+
+func extractCandidates(text, language string) []*candidate {
+	stop := stopwords[language]
+
+	var (
+		candidates []*candidate
+		seen       = make(map[string]*candidate)
+		run        []string
+	)
+
+	add := func(words []string) {
+		term := strings.ToLower(strings.Join(words, " "))
+		if c, ok := seen[term]; ok {
+			c.count++
+			return
+		}
+		c := &candidate{term: term, surface: strings.Join(words, " "), count: 1, words: len(words)}
+		seen[term] = c
+		candidates = append(candidates, c)
+	}
+
+	flush := func() {
+		for i := range run {
+			for n := 1; n <= maxPhraseWords && i+n <= len(run); n++ {
+				add(run[i : i+n])
+			}
+		}
+		run = run[:0]
+	}
+
+	for _, segment := range strings.FieldsFunc(text, isPhraseBreak) {
+		for _, word := range strings.Fields(segment) {
+			word = strings.TrimFunc(word, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			})
+			lower := strings.ToLower(word)
+			if lower == "" || stop[lower] || !isKeywordToken(lower) {
+				flush()
+				continue
+			}
+			run = append(run, word)
+		}
+		flush()
+	}
+
+	return candidates
+}
+
+func isPhraseBreak(r rune) bool {
+	return strings.ContainsRune(".,;:!?()[]{}\"“”", r)
+}
+
+// isKeywordToken rejects tokens that make poor keywords on their own, such
+// as short words and bare numbers
+func isKeywordToken(word string) bool {
+	if len([]rune(word)) < 3 {
+		return false
+	}
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractKeywords returns up to limit keywords of a text ranked by TF-IDF
+// against the corpus document frequencies. Words already covered by a
+// higher-ranked keyphrase are skipped.
+func ExtractKeywords(ctx context.Context, df DocumentFrequencies, text, language string, limit int) ([]Keyword, error) {
+	candidates := extractCandidates(text, language)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	terms := make([]string, 0, len(candidates))
+	totalWords := 0
+	for _, c := range candidates {
+		terms = append(terms, c.term)
+		if c.words == 1 {
+			totalWords += c.count
+		}
+	}
+
+	docs, frequencies, err := df.Lookup(ctx, language, terms)
+	if err != nil {
+		return nil, fmt.Errorf("looking up document frequencies: %w", err)
+	}
+
+	type scored struct {
+		*candidate
+		weight float64
+	}
+
+	idf := func(term string) float64 {
+		return math.Log(float64(docs+1)/float64(frequencies[term]+1)) + 1
+	}
+
+	ranked := make([]scored, 0, len(candidates))
+	for _, c := range candidates {
+		// A phrase is as specific as its words on average, unless the phrase
+		// itself is common in the corpus
+		weight := idf(c.term)
+		if c.words > 1 {
+			var sum float64
+			for _, word := range strings.Fields(c.term) {
+				sum += idf(word)
+			}
+			weight = math.Min(weight, sum/float64(c.words))
+			// Prefer a phrase over its equally specific words
+			weight *= 1 + 0.1*float64(c.words-1)
+		}
+
+		tf := float64(c.count) / float64(totalWords)
+		ranked = append(ranked, scored{candidate: c, weight: tf * weight})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].weight > ranked[j].weight
+	})
+
+	var keywords []Keyword
+	for _, r := range ranked {
+		if len(keywords) >= limit {
+			break
+		}
+		if coveredBy(r.term, keywords) {
+			continue
+		}
+		keywords = append(keywords, Keyword{Term: r.surface, Weight: r.weight})
+	}
+
+	return keywords, nil
+}
+
+func coveredBy(term string, keywords []Keyword) bool {
+	for _, k := range keywords {
+		kt := strings.ToLower(k.Term)
+		if strings.Contains(" "+kt+" ", " "+term+" ") || strings.Contains(" "+term+" ", " "+kt+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// KeywordStage fills the keywords metadata of a fact
+type KeywordStage struct {
+	df    DocumentFrequencies
+	limit int
+}
+
+// NewKeywordStage creates a keyword stage that keeps the top limit keywords
+func NewKeywordStage(df DocumentFrequencies, limit int) *KeywordStage {
+	return &KeywordStage{df: df, limit: limit}
+}
+
+// Name returns the stage name
+func (s *KeywordStage) Name() string {
+	return "keywords"
+}
+
+// Apply extracts keywords from the fact content. Document frequencies are not
+// updated here; that happens once the fact has been stored.
+func (s *KeywordStage) Apply(ctx context.Context, fact *models.Fact) error {
+	language := fact.Metadata.Language
+	if language == "" {
+		language = DefaultLanguage
+	}
+
+	keywords, err := ExtractKeywords(ctx, s.df, fact.Content, language, s.limit)
+	if err != nil {
+		return err
+	}
+
+	terms := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		terms = append(terms, k.Term)
+	}
+	fact.Metadata.Keywords = terms
+	return nil
+}
+
+// MongoDocumentFrequencies stores document frequencies in MongoDB, one
+// document per (language, term) plus a per-language document count
+type MongoDocumentFrequencies struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDocumentFrequencies creates a document frequency store
+func NewMongoDocumentFrequencies(collection *mongo.Collection) *MongoDocumentFrequencies {
+	return &MongoDocumentFrequencies{collection: collection}
+}
+
+type termFrequency struct {
+	ID       string `bson:"_id"`
+	Language string `bson:"language"`
+	Term     string `bson:"term"`
+	DF       int64  `bson:"df"`
+}
+
+// The per-language document count is stored under the empty term
+func termID(language, term string) string {
+	return language + ":" + term
+}
+
+// Lookup returns the document count and the frequencies of the given terms
+func (m *MongoDocumentFrequencies) Lookup(ctx context.Context, language string, terms []string) (int64, map[string]int64, error) {
+	ids := make([]string, 0, len(terms)+1)
+	ids = append(ids, termID(language, ""))
+	for _, term := range terms {
+		ids = append(ids, termID(language, term))
+	}
+
+	cursor, err := m.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs int64
+	frequencies := make(map[string]int64, len(terms))
+	for cursor.Next(ctx) {
+		var tf termFrequency
+		if err := cursor.Decode(&tf); err != nil {
+			return 0, nil, err
+		}
+		if tf.Term == "" {
+			docs = tf.DF
+			continue
+		}
+		frequencies[tf.Term] = tf.DF
+	}
+
+	return docs, frequencies, cursor.Err()
+}
+
+// Add increments the document count and the frequency of each term
+func (m *MongoDocumentFrequencies) Add(ctx context.Context, language string, terms []string) error {
+	return m.increment(ctx, language, terms, 1)
+}
+
+// Remove decrements the document count and the frequency of each term
+func (m *MongoDocumentFrequencies) Remove(ctx context.Context, language string, terms []string) error {
+	return m.increment(ctx, language, terms, -1)
+}
+
+func (m *MongoDocumentFrequencies) increment(ctx context.Context, language string, terms []string, delta int64) error {
+	writes := make([]mongo.WriteModel, 0, len(terms)+1)
+	for _, term := range append([]string{""}, terms...) {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": termID(language, term)}).
+			SetUpdate(bson.M{
+				"$inc":         bson.M{"df": delta},
+				"$setOnInsert": bson.M{"language": language, "term": term},
+			}).
+			SetUpsert(true))
+	}
+
+	_, err := m.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// Reset replaces all stored frequencies with the given ones
+func (m *MongoDocumentFrequencies) Reset(ctx context.Context, frequencies *MemoryDocumentFrequencies) error {
+	if _, err := m.collection.DeleteMany(ctx, bson.M{}); err != nil {
+		return err
+	}
+
+	frequencies.mutex.RLock()
+	defer frequencies.mutex.RUnlock()
+
+	var writes []mongo.WriteModel
+	for language, terms := range frequencies.terms {
+		for term, df := range terms {
+			writes = append(writes, mongo.NewInsertOneModel().SetDocument(termFrequency{
+				ID:       termID(language, term),
+				Language: language,
+				Term:     term,
+				DF:       df,
+			}))
+		}
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+
+	_, err := m.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// MemoryDocumentFrequencies keeps document frequencies in memory. It is used
+// to rebuild the corpus statistics in one pass and in tests.
+type MemoryDocumentFrequencies struct {
+	mutex sync.RWMutex
+	terms map[string]map[string]int64
+}
+
+// NewMemoryDocumentFrequencies creates an empty in-memory store
+func NewMemoryDocumentFrequencies() *MemoryDocumentFrequencies {
+	return &MemoryDocumentFrequencies{terms: make(map[string]map[string]int64)}
+}
+
+// Lookup returns the document count and the frequencies of the given terms
+func (m *MemoryDocumentFrequencies) Lookup(ctx context.Context, language string, terms []string) (int64, map[string]int64, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	frequencies := make(map[string]int64, len(terms))
+	for _, term := range terms {
+		if df, ok := m.terms[language][term]; ok {
+			frequencies[term] = df
+		}
+	}
+	return m.terms[language][""], frequencies, nil
+}
+
+// Add records a document containing the given terms
+func (m *MemoryDocumentFrequencies) Add(ctx context.Context, language string, terms []string) error {
+	m.increment(language, terms, 1)
+	return nil
+}
+
+// Remove forgets a document containing the given terms
+func (m *MemoryDocumentFrequencies) Remove(ctx context.Context, language string, terms []string) error {
+	m.increment(language, terms, -1)
+	return nil
+}
+
+// Reset replaces all frequencies with a copy of the given ones
+func (m *MemoryDocumentFrequencies) Reset(ctx context.Context, frequencies *MemoryDocumentFrequencies) error {
+	terms := make(map[string]map[string]int64)
+	frequencies.mutex.RLock()
+	for language, dfs := range frequencies.terms {
+		terms[language] = make(map[string]int64, len(dfs))
+		for term, df := range dfs {
+			terms[language][term] = df
+		}
+	}
+	frequencies.mutex.RUnlock()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.terms = terms
+	return nil
+}
+
+func (m *MemoryDocumentFrequencies) increment(language string, terms []string, delta int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.terms[language] == nil {
+		m.terms[language] = make(map[string]int64)
+	}
+	for _, term := range append([]string{""}, terms...) {
+		m.terms[language][term] += delta
+	}
+}
@@ -0,0 +1,63 @@
+package processors
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTermsSkipStopwordsAndPunctuation(t *testing.T) {
+	terms := Terms("Ada Lovelace wrote the first algorithm, in the 1840s.", "English")
+
+	want := map[string]bool{"ada": true, "lovelace": true, "ada lovelace": true, "ada lovelace wrote": true, "lovelace wrote": true, "wrote": true, "algorithm": true, "1840s": true}
+	for _, term := range terms {
+		if !want[term] {
+			t.Errorf("Unexpected term %q", term)
+		}
+		delete(want, term)
+	}
+	for term := range want {
+		t.Errorf("Missing term %q", term)
+	}
+}
+
+func TestExtractKeywordsPrefersRareTerms(t *testing.T) {
+	ctx := context.Background()
+	df := NewMemoryDocumentFrequencies()
+
+	corpus := []string{
+		"The river flows through the city.",
+		"The city has a large river port.",
+		"The city was founded on the river.",
+	}
+	for _, text := range corpus {
+		df.Add(ctx, "English", Terms(text, "English"))
+	}
+
+	keywords, err := ExtractKeywords(ctx, df, "The city river hosts the Danube Regatta.", "English", 2)
+	if err != nil {
+		t.Fatalf("Error extracting keywords: %v", err)
+	}
+
+	if len(keywords) == 0 || keywords[0].Term != "Danube Regatta" {
+		t.Fatalf("Expected \"Danube Regatta\" as top keyword, got %+v", keywords)
+	}
+	for _, k := range keywords {
+		if k.Term == "Danube" || k.Term == "Regatta" {
+			t.Errorf("Expected %q to be covered by its keyphrase", k.Term)
+		}
+	}
+}
+
+func TestMemoryDocumentFrequenciesRemove(t *testing.T) {
+	ctx := context.Background()
+	df := NewMemoryDocumentFrequencies()
+
+	df.Add(ctx, "English", []string{"river"})
+	df.Add(ctx, "English", []string{"river", "city"})
+	df.Remove(ctx, "English", []string{"river"})
+
+	docs, frequencies, _ := df.Lookup(ctx, "English", []string{"river", "city"})
+	if docs != 1 || frequencies["river"] != 1 || frequencies["city"] != 1 {
+		t.Errorf("Unexpected frequencies after remove: docs=%d %v", docs, frequencies)
+	}
+}
@@ -0,0 +1,383 @@
+package processors
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextRule is one named cleanup step applied to collected text
+type TextRule struct {
+	Name  string
+	Apply func(text string) string
+}
+
+// DefaultTextRules are the cleanup steps applied to collected text, in order.
+// Markup is stripped first since unescaped entities may produce characters the
+// later rules handle.
+var DefaultTextRules = []TextRule{
+	{Name: "markup", Apply: stripMarkup},
+	{Name: "unicode", Apply: normalizeUnicode},
+	{Name: "pronunciations", Apply: removePronunciations},
+	{Name: "life_dates", Apply: removeLifeDates},
+	{Name: "empty_parentheses", Apply: removeEmptyParentheses},
+	{Name: "quotes", Apply: normalizeQuotes},
+	{Name: "whitespace", Apply: normalizeWhitespace},
+	{Name: "sentence_endings", Apply: repairSentenceEndings},
+}
+
+// TextRulesByName returns the default rules with the given names, keeping
+// their default order. No names selects every default rule.
+func TextRulesByName(names []string) ([]TextRule, error) {
+	if len(names) == 0 {
+		return DefaultTextRules, nil
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[strings.TrimSpace(name)] = true
+	}
+
+	var rules []TextRule
+	for _, rule := range DefaultTextRules {
+		if wanted[rule.Name] {
+			rules = append(rules, rule)
+			delete(wanted, rule.Name)
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("unknown text rule %q", name)
+	}
+	return rules, nil
+}
+
+// NormalizeText applies the rules to a text in order
+func NormalizeText(text string, rules []TextRule) string {
+	for _, rule := range rules {
+		text = rule.Apply(text)
+	}
+	return text
+}
+
+var (
+	htmlTagPattern   = regexp.MustCompile(`</?[a-zA-Z][^<>]*>`)
+	templatePattern  = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+	wikiLinkPattern  = regexp.MustCompile(`\[\[(?:[^|\]]*\|)?([^\]]*)\]\]`)
+	citationPattern  = regexp.MustCompile(`\[(?:\d+|[a-z]|note \d+|nb \d+|[a-zA-Z ]+ needed|[a-z]+\?)\]`)
+	emphasisPattern  = regexp.MustCompile(`'{2,}`)
+	headingPattern   = regexp.MustCompile(`(?m)^\s*=+[^=\n]+=+\s*$`)
+	emptyBrackets    = regexp.MustCompile(`\[[\s,;]*\]`)
+	spacePattern     = regexp.MustCompile(`\s+`)
+	spaceBeforePunct = regexp.MustCompile(`\s+([,.;:!?])`)
+	openParenSpace   = regexp.MustCompile(`([(\[])\s+`)
+	closeParenSpace  = regexp.MustCompile(`\s+([)\]])`)
+	missingSpace     = regexp.MustCompile(`([a-z]{2}|\))([.!?])([A-Z][a-z])`)
+	doubledPeriod    = regexp.MustCompile(`([^.])\.\.([^.]|$)`)
+	punctBeforeStop  = regexp.MustCompile(`[,;:]\.`)
+)
+
+// stripMarkup removes HTML tags and entities, templates, wiki links and
+// emphasis, headings and citation markers
+func stripMarkup(text string) string {
+	text = html.UnescapeString(text)
+	text = htmlTagPattern.ReplaceAllString(text, "")
+
+	// Templates may be nested, so strip them from the inside out
+	for templatePattern.MatchString(text) {
+		text = templatePattern.ReplaceAllString(text, "")
+	}
+
+	text = wikiLinkPattern.ReplaceAllString(text, "$1")
+	text = citationPattern.ReplaceAllString(text, "")
+	text = emphasisPattern.ReplaceAllString(text, "")
+	text = headingPattern.ReplaceAllString(text, "")
+	return text
+}
+
+// normalizeUnicode composes characters, turns special spaces into plain ones
+// and drops invisible formatting characters
+func normalizeUnicode(text string) string {
+	text = norm.NFC.String(text)
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u00a0', r == '\u202f', r == '\u205f', r == '\u3000', r >= '\u2000' && r <= '\u200a':
+			return ' '
+		case r == '\u00ad', r == '\u200b', r == '\u2060', r == '\ufeff', r == '\u200e', r == '\u200f',
+			r >= '\u202a' && r <= '\u202e':
+			return -1
+		case r == '\n', r == '\t':
+			return r
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, text)
+}
+
+var quoteReplacer = strings.NewReplacer(
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "«", `"`, "»", `"`,
+	"‘", "'", "’", "'", "‚", "'", "‛", "'",
+)
+
+// normalizeQuotes replaces typographic quotes with straight ones
+func normalizeQuotes(text string) string {
+	return quoteReplacer.Replace(text)
+}
+
+// normalizeWhitespace collapses runs of whitespace and removes spaces before
+// punctuation and inside brackets
+func normalizeWhitespace(text string) string {
+	text = spacePattern.ReplaceAllString(text, " ")
+	text = openParenSpace.ReplaceAllString(text, "$1")
+	text = closeParenSpace.ReplaceAllString(text, "$1")
+	text = spaceBeforePunct.ReplaceAllString(text, "$1")
+	return strings.TrimSpace(text)
+}
+
+// repairSentenceEndings adds missing spaces between sentences, removes
+// doubled stops and makes sure the text ends with a complete sentence
+func repairSentenceEndings(text string) string {
+	text = missingSpace.ReplaceAllString(text, "$1$2 $3")
+	text = punctBeforeStop.ReplaceAllString(text, ".")
+	text = doubledPeriod.ReplaceAllString(text, "$1.$2")
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return text
+	}
+
+	// An extract cut off before a list ends with a colon; drop the dangling
+	// sentence if a complete one precedes it
+	if strings.HasSuffix(text, ":") || strings.HasSuffix(text, ",") || strings.HasSuffix(text, ";") {
+		if end := lastSentenceEnd(text); end > 0 {
+			return text[:end]
+		}
+		text = strings.TrimRight(text, ":,;") + "."
+	}
+
+	trimmed := strings.TrimRight(text, `"')`)
+	if !strings.HasSuffix(trimmed, ".") && !strings.HasSuffix(trimmed, "!") && !strings.HasSuffix(trimmed, "?") {
+		text += "."
+	}
+	return text
+}
+
+// lastSentenceEnd returns the index just past the last sentence-ending
+// punctuation that is followed by a space, or 0 if there is none
+func lastSentenceEnd(text string) int {
+	end := 0
+	for _, stop := range []string{". ", "! ", "? "} {
+		if i := strings.LastIndex(text, stop); i >= 0 && i+1 > end {
+			end = i + 1
+		}
+	}
+	return end
+}
+
+var (
+	slashIPAPattern   = regexp.MustCompile(`/[^/\s][^/]*/`)
+	bracketIPAPattern = regexp.MustCompile(`\[[^\]]*\]`)
+	listenPattern     = regexp.MustCompile(`\(\s*listen\s*\)|\blisten\b`)
+	languageLabel     = regexp.MustCompile(`^(([A-Z][a-z]+ ){0,2}[A-Z][a-z]+( pronunciation)?:|pronounced:?)$`)
+	respellingWord    = regexp.MustCompile(`^[A-Za-z]*[A-Z]{2}[A-Za-z]*(-[A-Za-z]+)+$|^[a-z]+(-[a-z]+)*-[A-Z]{2,}[A-Za-z-]*$`)
+)
+
+// isIPA reports whether a rune only appears in phonetic transcriptions
+func isIPA(r rune) bool {
+	return (r >= 0x0250 && r <= 0x02ff) || r == 'θ' || r == 'ŋ'
+}
+
+func containsIPA(text string) bool {
+	return strings.IndexFunc(text, isIPA) >= 0
+}
+
+// removePronunciations drops IPA transcriptions, respellings and "listen"
+// links from parentheticals, keeping translations that come with them
+func removePronunciations(text string) string {
+	return rewriteParentheticals(text, func(segments []string) []string {
+		joined := strings.Join(segments, ";")
+		if !containsIPA(joined) && !listenPattern.MatchString(joined) {
+			return segments
+		}
+
+		var kept []string
+		for _, segment := range segments {
+			segment = slashIPAPattern.ReplaceAllStringFunc(segment, dropIfIPA)
+			segment = bracketIPAPattern.ReplaceAllStringFunc(segment, dropIfIPA)
+			segment = listenPattern.ReplaceAllString(segment, "")
+
+			// Respellings and labels are often listed with commas
+			var parts []string
+			for _, part := range strings.Split(segment, ",") {
+				part = strings.Join(strings.Fields(part), " ")
+				if part == "" || languageLabel.MatchString(part) || isRespelling(part) {
+					continue
+				}
+				parts = append(parts, part)
+			}
+			if len(parts) > 0 {
+				kept = append(kept, strings.Join(parts, ", "))
+			}
+		}
+		return kept
+	})
+}
+
+func dropIfIPA(span string) string {
+	if containsIPA(span) {
+		return ""
+	}
+	return span
+}
+
+// isRespelling reports whether every word of a segment is a pronunciation
+// respelling such as "EYE-fəl" or "AL-bərt EYEN-styne"
+func isRespelling(segment string) bool {
+	for _, word := range strings.Fields(segment) {
+		word = strings.Trim(word, ",:")
+		if !containsIPA(word) && !respellingWord.MatchString(word) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	bornPattern  = regexp.MustCompile(`^(born|b\.|died|d\.|baptised|baptized)\s`)
+	dateToken    = regexp.MustCompile(`(?i)\b(january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sep|sept|oct|nov|dec|c|ca|circa|bc|bce|ad|ce|or|fl)\b\.?|\d{1,4}(st|nd|rd|th)?|[,–—\-?]`)
+	monthPattern = regexp.MustCompile(`(?i)\b(january|february|march|april|may|june|july|august|september|october|november|december)\b`)
+	yearPattern  = regexp.MustCompile(`\d{3,4}`)
+)
+
+// isDate reports whether a segment only consists of dates
+func isDate(segment string) bool {
+	return yearPattern.MatchString(segment) && strings.TrimSpace(dateToken.ReplaceAllString(segment, "")) == ""
+}
+
+// removeLifeDates drops "born ..." asides and birth and death dates from
+// parentheticals. Year-only ranges are kept, since they usually date an event
+// rather than a life.
+func removeLifeDates(text string) string {
+	return rewriteParentheticals(text, func(segments []string) []string {
+		born := false
+		for _, segment := range segments {
+			if bornPattern.MatchString(segment) {
+				born = true
+			}
+		}
+
+		var kept []string
+		for _, segment := range segments {
+			lifespan := isDate(segment) && strings.ContainsAny(segment, "–—-") && monthPattern.MatchString(segment)
+			if bornPattern.MatchString(segment) || lifespan || (born && isDate(segment)) {
+				continue
+			}
+			kept = append(kept, segment)
+		}
+		return kept
+	})
+}
+
+// removeEmptyParentheses drops brackets and parentheticals left without any
+// words by removed templates
+func removeEmptyParentheses(text string) string {
+	text = emptyBrackets.ReplaceAllString(text, "")
+	return rewriteParentheticals(text, func(segments []string) []string {
+		var kept []string
+		for _, segment := range segments {
+			if strings.IndexFunc(segment, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+				kept = append(kept, segment)
+			}
+		}
+		return kept
+	})
+}
+
+// rewriteParentheticals passes the semicolon-separated segments of every
+// top-level parenthetical to rewrite. Parentheticals left without segments
+// are removed along with the space before them; unchanged ones are kept as
+// they were.
+func rewriteParentheticals(text string, rewrite func(segments []string) []string) string {
+	var out strings.Builder
+	depth, start := 0, 0
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+			continue
+		case ')':
+			if depth == 0 {
+				break
+			}
+			depth--
+			if depth > 0 {
+				continue
+			}
+
+			inner := text[start+1 : i]
+			segments := splitSegments(inner)
+			kept := rewrite(segments)
+
+			switch {
+			case equalSegments(segments, kept):
+				out.WriteString(text[start : i+1])
+			case len(kept) == 0:
+				trimmed := strings.TrimRight(out.String(), " ")
+				out.Reset()
+				out.WriteString(trimmed)
+			default:
+				out.WriteString("(" + strings.Join(kept, "; ") + ")")
+			}
+			continue
+		}
+
+		if depth == 0 {
+			out.WriteByte(text[i])
+		}
+	}
+
+	// Leave an unbalanced parenthesis untouched
+	if depth > 0 {
+		out.WriteString(text[start:])
+	}
+	return out.String()
+}
+
+// splitSegments splits the inside of a parenthetical at top-level semicolons
+func splitSegments(inner string) []string {
+	var segments []string
+	depth, start := 0, 0
+	for i, r := range inner {
+		switch r {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ';':
+			if depth == 0 {
+				segments = append(segments, strings.TrimSpace(inner[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(segments, strings.TrimSpace(inner[start:]))
+}
+
+func equalSegments(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
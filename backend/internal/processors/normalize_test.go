@@ -0,0 +1,97 @@
+package processors
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestNormalizeGolden runs the default rules over the extracts in
+// testdata/normalize and compares the result with the .golden files. Run
+// with -update after changing a rule and review the diff.
+func TestNormalizeGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "normalize", "*.input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("No test extracts found")
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".input")
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := NormalizeText(string(raw), DefaultTextRules)
+			golden := strings.TrimSuffix(input, ".input") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(got+"\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != strings.TrimSuffix(string(want), "\n") {
+				t.Errorf("NormalizeText() =\n%s\nwant\n%s", got, want)
+			}
+
+			if again := NormalizeText(got, DefaultTextRules); again != got {
+				t.Errorf("NormalizeText() is not idempotent:\n%s\n%s", got, again)
+			}
+		})
+	}
+}
+
+func TestTextRulesByName(t *testing.T) {
+	rules, err := TextRulesByName([]string{"whitespace", "markup"})
+	if err != nil {
+		t.Fatalf("Error selecting rules: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Name != "markup" || rules[1].Name != "whitespace" {
+		t.Errorf("Rules not in default order: %+v", rules)
+	}
+
+	if _, err := TextRulesByName([]string{"spelling"}); err == nil {
+		t.Error("Expected an error for an unknown rule")
+	}
+}
+
+func TestProcessorNormalizesBeforeRules(t *testing.T) {
+	processor := NewProcessor()
+	processor.SetTextRules(DefaultTextRules)
+
+	// The life dates would trip the banned word rule if left in
+	raw := collectors.RawFact{Content: "Albert Einstein (born 14 March 1879, died 18 April 1955) was a physicist who developed the theory of relativity"}
+	explanation := processor.Explain(context.Background(), raw)
+	if explanation.Decision != DecisionAccepted {
+		t.Fatalf("Decision = %s (%s), want %s", explanation.Decision, explanation.Reason, DecisionAccepted)
+	}
+	want := "Albert Einstein was a physicist who developed the theory of relativity."
+	if explanation.Normalized != want || explanation.Fact.Content != want {
+		t.Errorf("Content = %q, normalized %q, want %q", explanation.Fact.Content, explanation.Normalized, want)
+	}
+	if explanation.Fact.Metadata.OriginalContent != raw.Content {
+		t.Errorf("OriginalContent = %q, want the collected text", explanation.Fact.Metadata.OriginalContent)
+	}
+
+	// Text only long enough before cleanup is rejected on its cleaned length
+	raw = collectors.RawFact{Content: "Ada Lovelace (10 December 1815 – 27 November 1852) was a mathematician"}
+	if fact, reason, _ := processor.Review(context.Background(), raw); fact != nil || reason != "length" {
+		t.Errorf("Review() = %v, %q, want rejected on length", fact, reason)
+	}
+}
@@ -2,28 +2,15 @@ package processors
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
 )
 
-// ProcessedFact represents a fact that has been validated and enriched
-type ProcessedFact struct {
-	ID          string            `json:"id" bson:"_id,omitempty"`
-	Content     string            `json:"content" bson:"content"`
-	Source      string            `json:"source" bson:"source"`
-	Category    string            `json:"category" bson:"category"`
-	Tags        []string          `json:"tags" bson:"tags"`
-	URLs        []string          `json:"related_urls" bson:"related_urls"`
-	Metadata    map[string]string `json:"metadata" bson:"metadata"`
-	Verified    bool             `json:"verified" bson:"verified"`
-	Score       float64          `json:"score" bson:"score"`
-	CreatedAt   time.Time        `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time        `json:"updated_at" bson:"updated_at"`
-	PublishDate time.Time        `json:"publish_date" bson:"publish_date"`
-}
-
 // Processor handles fact validation and enrichment
 type Processor struct {
 	minLength     int
@@ -31,10 +18,35 @@ type Processor struct {
 	minScore      float64
 	bannedWords   []string
 	requiredWords []string
+	textRules     []TextRule
+	tags          *TagMapper
+	stages        []Stage
+}
+
+// Stage is an optional processing step that runs after a raw fact has passed
+// validation and scoring. Stages run in the order they were added and may
+// enrich the fact in place. A stage drops the fact by returning an error that
+// wraps ErrRejected.
+type Stage interface {
+	Name() string
+	Apply(ctx context.Context, fact *models.Fact) error
 }
 
+// ErrRejected is wrapped by stages that reject a fact outright rather than
+// sending it to review
+var ErrRejected = errors.New("rejected")
+
+// RejectedLowScore is the reason given by Review for facts below the
+// structural minimum score
+const RejectedLowScore = "low_score"
+
 // NewProcessor creates a new fact processor
 func NewProcessor() *Processor {
+	tags, err := NewTagMapper(DefaultTagTaxonomy())
+	if err != nil {
+		panic(err)
+	}
+
 	return &Processor{
 		minLength: 50,
 		maxLength: 500,
@@ -46,109 +58,231 @@ func NewProcessor() *Processor {
 		requiredWords: []string{
 			"the", "is", "are", "was", "were",
 		},
+		tags: tags,
 	}
 }
 
-// Process validates and enriches a raw fact
-func (p *Processor) Process(ctx context.Context, raw collectors.RawFact) (*ProcessedFact, error) {
-	// Basic validation
-	if !p.validateLength(raw.Content) {
-		return nil, nil
+// SetTagMapper replaces the taxonomy used to map source categories onto tags
+func (p *Processor) SetTagMapper(tags *TagMapper) {
+	p.tags = tags
+}
+
+// SetTextRules sets the cleanup rules applied to collected text before it is
+// validated and scored
+func (p *Processor) SetTextRules(rules []TextRule) {
+	p.textRules = rules
+}
+
+// Use appends stages to the processing pipeline
+func (p *Processor) Use(stages ...Stage) {
+	p.stages = append(p.stages, stages...)
+}
+
+// Process validates and enriches a raw fact into a fact ready to be stored.
+// Facts that fail validation are returned as nil without an error.
+func (p *Processor) Process(ctx context.Context, raw collectors.RawFact) (*models.Fact, error) {
+	fact, _, err := p.process(ctx, raw, nil)
+	return fact, err
+}
+
+// Review processes a raw fact like Process and, when the fact is dropped,
+// also returns why: the failed rule, "low_score", or the name of the stage
+// that rejected it
+func (p *Processor) Review(ctx context.Context, raw collectors.RawFact) (*models.Fact, string, error) {
+	return p.process(ctx, raw, nil)
+}
+
+// process runs the pipeline, recording every rule, score component and stage
+// in the explanation if one is given
+func (p *Processor) process(ctx context.Context, raw collectors.RawFact, explanation *Explanation) (*models.Fact, string, error) {
+	// Clean up the text first, so the rules and score judge what is stored
+	collected := raw.Content
+	raw.Content = NormalizeText(raw.Content, p.textRules)
+	if explanation != nil && raw.Content != collected {
+		explanation.Normalized = raw.Content
 	}
 
-	if !p.validateContent(raw.Content) {
-		return nil, nil
+	// Basic validation
+	rules := p.checkRules(raw.Content)
+	if explanation != nil {
+		explanation.Rules = rules
+	}
+	for _, rule := range rules {
+		if !rule.Passed {
+			return nil, rule.Rule, nil
+		}
 	}
 
-	// Calculate fact quality score
-	score := p.calculateScore(raw)
+	// Calculate fact quality score on the curated tags, so maintenance
+	// categories do not count
+	tags := p.tags.Map(raw.Tags)
+	components := p.scoreComponents(raw, tags)
+	score := sumScore(components)
+	if explanation != nil {
+		explanation.ScoreBreakdown = components
+		explanation.Score = score
+	}
 	if score < p.minScore {
-		return nil, nil
+		return nil, RejectedLowScore, nil
 	}
 
 	// Create processed fact with normalized fields
+	language := raw.Metadata["language"]
+	if language == "" {
+		language = DefaultLanguage
+	}
+
+	urls := raw.URLs
+	if urls == nil {
+		urls = []string{}
+	}
+
 	now := time.Now()
-	fact := &ProcessedFact{
-		Content:     raw.Content,
-		Source:      raw.Source,
-		Category:    p.normalizeCategory(raw.Category),
-		Tags:        p.normalizeTags(raw.Tags),
-		URLs:        raw.URLs,
-		Metadata:    raw.Metadata,
-		Verified:    true,
+	fact := &models.Fact{
+		SchemaVersion: models.CurrentFactSchemaVersion,
+		Content:       raw.Content,
+		Source:        raw.Source,
+		Category:      p.normalizeCategory(raw.Category),
+		Tags:          tags,
+		RelatedURLs:   urls,
+		Metadata: models.FactMetadata{
+			Title:      raw.Metadata["title"],
+			Language:   language,
+			References: []string{},
+			Keywords:   []string{},
+			// The category the source collected it from, before it is
+			// mapped or classified
+			SourceCategory: raw.Category,
+			// The collected text, which verification checks the content against
+			OriginalContent: collected,
+		},
+		Verified:    false, // Set by the verification stage
 		Score:       score,
 		CreatedAt:   now,
 		UpdatedAt:   now,
-		PublishDate: now.AddDate(0, 0, 1), // Schedule for tomorrow
 	}
 
-	return fact, nil
+	for _, stage := range p.stages {
+		var before map[string]interface{}
+		if explanation != nil {
+			before = snapshot(fact)
+		}
+
+		err := stage.Apply(ctx, fact)
+
+		if explanation != nil {
+			trace := StageTrace{Stage: stage.Name(), Changes: diffSnapshots(before, snapshot(fact))}
+			if err != nil {
+				trace.Error = err.Error()
+			}
+			explanation.Stages = append(explanation.Stages, trace)
+		}
+		if errors.Is(err, ErrRejected) {
+			if explanation != nil {
+				explanation.Reason = fmt.Sprintf("%s stage: %v", stage.Name(), err)
+			}
+			return nil, stage.Name(), nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("%s stage: %w", stage.Name(), err)
+		}
+	}
+
+	return fact, "", nil
 }
 
-func (p *Processor) validateLength(content string) bool {
+// checkRules runs the validation rules against the content of a raw fact
+func (p *Processor) checkRules(content string) []RuleHit {
 	length := len(content)
-	return length >= p.minLength && length <= p.maxLength
-}
+	rules := []RuleHit{{
+		Rule:   "length",
+		Passed: length >= p.minLength && length <= p.maxLength,
+		Detail: fmt.Sprintf("%d characters, allowed %d-%d", length, p.minLength, p.maxLength),
+	}}
 
-func (p *Processor) validateContent(content string) bool {
 	content = strings.ToLower(content)
-	
+
 	// Check for banned words
 	for _, word := range p.bannedWords {
 		if strings.Contains(content, word) {
-			return false
+			rules = append(rules, RuleHit{
+				Rule:   "banned_word",
+				Passed: false,
+				Detail: fmt.Sprintf("contains %q", word),
+			})
 		}
 	}
 
 	// Check for required words
-	hasRequiredWord := false
+	required := RuleHit{Rule: "required_word", Detail: "contains none of " + strings.Join(p.requiredWords, ", ")}
 	for _, word := range p.requiredWords {
 		if strings.Contains(content, word) {
-			hasRequiredWord = true
+			required.Passed = true
+			required.Detail = fmt.Sprintf("contains %q", word)
 			break
 		}
 	}
 
-	return hasRequiredWord
+	return append(rules, required)
 }
 
-func (p *Processor) calculateScore(raw collectors.RawFact) float64 {
-	var score float64 = 1.0
+// scoreComponents breaks the quality score of a raw fact into its parts
+func (p *Processor) scoreComponents(raw collectors.RawFact, tags []string) []ScoreComponent {
+	components := []ScoreComponent{{Name: "base", Value: 1.0}}
 
 	// Content length score (0.8 - 1.2)
 	contentLength := len(raw.Content)
+	length := ScoreComponent{Name: "length", Detail: fmt.Sprintf("%d characters", contentLength)}
 	if contentLength >= 200 && contentLength <= 300 {
-		score += 0.2
+		length.Value = 0.2
 	} else if contentLength < 100 || contentLength > 400 {
-		score -= 0.2
+		length.Value = -0.2
 	}
+	components = append(components, length)
 
 	// Category score (0 - 0.2)
+	category := ScoreComponent{Name: "category", Detail: "no category"}
 	if raw.Category != "" {
-		score += 0.1
+		category.Value = 0.1
+		category.Detail = raw.Category
 	}
+	components = append(components, category)
 
 	// Tags score (0 - 0.2)
-	if len(raw.Tags) > 0 {
-		score += 0.1
-		if len(raw.Tags) >= 3 {
-			score += 0.1
+	tagScore := ScoreComponent{Name: "tags", Detail: fmt.Sprintf("%d tags", len(tags))}
+	if len(tags) > 0 {
+		tagScore.Value += 0.1
+		if len(tags) >= 3 {
+			tagScore.Value += 0.1
 		}
 	}
+	components = append(components, tagScore)
 
 	// URLs score (0 - 0.2)
+	urls := ScoreComponent{Name: "urls", Detail: fmt.Sprintf("%d URLs", len(raw.URLs))}
 	if len(raw.URLs) > 0 {
-		score += 0.2
+		urls.Value = 0.2
 	}
+	components = append(components, urls)
 
 	// Metadata score (0 - 0.2)
+	metadata := ScoreComponent{Name: "metadata", Detail: fmt.Sprintf("%d keys", len(raw.Metadata))}
 	if len(raw.Metadata) > 0 {
-		score += 0.1
+		metadata.Value += 0.1
 		if len(raw.Metadata) >= 3 {
-			score += 0.1
+			metadata.Value += 0.1
 		}
 	}
+	components = append(components, metadata)
+
+	return components
+}
 
+func sumScore(components []ScoreComponent) float64 {
+	var score float64
+	for _, c := range components {
+		score += c.Value
+	}
 	return score
 }
 
@@ -205,25 +339,3 @@ func (p *Processor) normalizeCategory(category string) string {
 	// Default to General if no match found
 	return "General"
 }
-
-func (p *Processor) normalizeTags(tags []string) []string {
-	normalizedTags := make([]string, 0, len(tags))
-	seenTags := make(map[string]bool)
-
-	for _, tag := range tags {
-		// Clean up tag
-		tag = strings.TrimSpace(tag)
-		tag = strings.TrimPrefix(tag, "Category:")
-		tag = strings.ToLower(tag)
-
-		// Skip empty or already seen tags
-		if tag == "" || seenTags[tag] {
-			continue
-		}
-
-		normalizedTags = append(normalizedTags, tag)
-		seenTags[tag] = true
-	}
-
-	return normalizedTags
-}
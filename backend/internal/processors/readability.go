@@ -0,0 +1,125 @@
+package processors
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Readability holds the text metrics used to estimate how hard a fact is to read
+type Readability struct {
+	Words              int     `json:"words"`
+	Sentences          int     `json:"sentences"`
+	Syllables          int     `json:"syllables"`
+	FleschKincaidGrade float64 `json:"flesch_kincaid_grade"`
+	RareWordRatio      float64 `json:"rare_word_ratio"`
+	NumericDensity     float64 `json:"numeric_density"`
+}
+
+// MeasureReadability computes readability metrics for a piece of English text
+func MeasureReadability(text string) Readability {
+	var r Readability
+
+	words := splitWords(text)
+	r.Sentences = countSentences(text)
+
+	var rare, numeric int
+	for _, word := range words {
+		if isNumericToken(word) {
+			numeric++
+			r.Words++
+			continue
+		}
+
+		word = strings.ToLower(strings.Trim(word, "'’-.,"))
+		if word == "" {
+			continue
+		}
+
+		r.Words++
+		r.Syllables += countSyllables(word)
+		if !commonWords[word] && len(word) > 3 {
+			rare++
+		}
+	}
+
+	if r.Words == 0 {
+		return r
+	}
+	if r.Sentences == 0 {
+		r.Sentences = 1
+	}
+
+	words64 := float64(r.Words)
+	r.FleschKincaidGrade = 0.39*(words64/float64(r.Sentences)) + 11.8*(float64(r.Syllables)/words64) - 15.59
+	if r.FleschKincaidGrade < 0 {
+		r.FleschKincaidGrade = 0
+	}
+	r.RareWordRatio = float64(rare) / words64
+	r.NumericDensity = float64(numeric) / words64
+
+	return r
+}
+
+// splitWords splits text into word tokens, keeping digits, apostrophes and
+// inner punctuation of numbers such as "1,200" or "3.5" together
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’' || r == '-' || r == '.' || r == ',')
+	})
+}
+
+func countSentences(text string) int {
+	count := 0
+	runes := []rune(text)
+	for i, r := range runes {
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+		// Decimal points and abbreviations like "U.S." are not sentence ends
+		if i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+func isNumericToken(word string) bool {
+	word = strings.Trim(word, ".,")
+	if word == "" {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsDigit(r) && r != '.' && r != ',' {
+			return false
+		}
+	}
+	return true
+}
+
+// countSyllables estimates the number of syllables in a lowercase English word
+// by counting vowel groups, with the usual adjustment for a silent final "e".
+func countSyllables(word string) int {
+	word = strings.Trim(word, ".,")
+	if word == "" {
+		return 0
+	}
+
+	count := 0
+	prevVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
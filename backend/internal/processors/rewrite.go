@@ -0,0 +1,175 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+// Length limits for rewritten facts
+const (
+	MinRewriteLength    = 60
+	MaxRewriteLength    = 280
+	MaxRewriteSentences = 2
+)
+
+const rewriteSystemPrompt = "You write facts for the 'One Fact' app. Rewrite the encyclopedia extract you are given " +
+	"into one or two short, engaging sentences that state a single surprising or interesting fact.\n\n" +
+	"RULES:\n" +
+	"1. Use only information stated in the extract. Do not add names, places, dates or numbers.\n" +
+	"2. The fact must make sense on its own without the extract.\n" +
+	"3. Do not start with \"Did you know\" and do not use exclamation marks.\n" +
+	"4. Reply with the fact only."
+
+// Completer generates a text completion for a system and user prompt
+type Completer interface {
+	Complete(ctx context.Context, system, prompt string) (string, error)
+}
+
+// RewriteStage turns encyclopedic extracts into short, self-contained facts
+// using a language model. The original text is kept in the metadata and
+// rewrites that fail the length or grounding checks are discarded.
+type RewriteStage struct {
+	completer Completer
+}
+
+// NewRewriteStage creates a rewrite stage using the given completer
+func NewRewriteStage(completer Completer) *RewriteStage {
+	return &RewriteStage{completer: completer}
+}
+
+// Name returns the stage name
+func (s *RewriteStage) Name() string {
+	return "rewrite"
+}
+
+// Apply rewrites the fact content. Failures keep the original content, since
+// a fact is still usable without a rewrite.
+func (s *RewriteStage) Apply(ctx context.Context, fact *models.Fact) error {
+	original := fact.Content
+
+	rewrite, err := s.completer.Complete(ctx, rewriteSystemPrompt, original)
+	if err != nil {
+		log.Printf("Error rewriting fact: %v", err)
+		fact.Metadata.RewriteRejected = "completion failed"
+		return nil
+	}
+
+	rewrite = strings.Trim(strings.TrimSpace(rewrite), "\"“”")
+	if err := CheckRewrite(original, rewrite); err != nil {
+		fact.Metadata.RewriteRejected = err.Error()
+		return nil
+	}
+
+	if fact.Metadata.OriginalContent == "" {
+		fact.Metadata.OriginalContent = original
+	}
+	fact.Content = rewrite
+	return nil
+}
+
+// CheckRewrite verifies that a rewrite respects the length limits and only
+// uses entities and numbers that appear in the source text
+func CheckRewrite(source, rewrite string) error {
+	length := len([]rune(rewrite))
+	if length < MinRewriteLength || length > MaxRewriteLength {
+		return fmt.Errorf("length %d outside %d-%d characters", length, MinRewriteLength, MaxRewriteLength)
+	}
+
+	if sentences := countSentences(rewrite); sentences > MaxRewriteSentences {
+		return fmt.Errorf("%d sentences, at most %d allowed", sentences, MaxRewriteSentences)
+	}
+
+	sourceNumbers := make(map[string]bool)
+	for _, n := range ExtractNumbers(source) {
+		sourceNumbers[n] = true
+	}
+	for _, n := range ExtractNumbers(rewrite) {
+		if !sourceNumbers[n] {
+			return fmt.Errorf("number %q not in source", n)
+		}
+	}
+
+	sourceWords := words(source)
+	for _, entity := range extractEntities(rewrite) {
+		if !containsWords(sourceWords, words(entity)) {
+			return fmt.Errorf("entity %q not in source", entity)
+		}
+	}
+
+	return nil
+}
+
+var numberPattern = regexp.MustCompile(`\d[\d,.]*`)
+
+// ExtractNumbers returns the numbers in a text with thousands separators and
+// trailing punctuation removed, so "1,200." and "1200" compare equal
+func ExtractNumbers(text string) []string {
+	var numbers []string
+	for _, n := range numberPattern.FindAllString(text, -1) {
+		n = strings.TrimRight(strings.ReplaceAll(n, ",", ""), ".")
+		if n != "" {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+// extractEntities returns the capitalized words of a text, which is a cheap
+// stand-in for named entities. Sentence-initial words only count when they
+// are not ordinary words.
+func extractEntities(text string) []string {
+	var entities []string
+	sentenceStart := true
+	for _, word := range strings.Fields(text) {
+		trimmed := strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+
+		if trimmed != "" {
+			first := []rune(trimmed)[0]
+			lower := strings.ToLower(trimmed)
+			ordinary := commonWords[lower] || stopwords[DefaultLanguage][lower]
+			if unicode.IsUpper(first) && !(sentenceStart && ordinary) {
+				entities = append(entities, trimmed)
+			}
+		}
+
+		sentenceStart = strings.HasSuffix(word, ".") || strings.HasSuffix(word, "!") || strings.HasSuffix(word, "?")
+	}
+	return entities
+}
+
+// words splits a text into lowercase words at anything but letters and
+// digits, so "Eiffel's" yields "eiffel" and "s"
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsWords reports whether the phrase appears as consecutive words of
+// the text, so "Paris" matches "Paris, France" but not "Parisian"
+func containsWords(text, phrase []string) bool {
+	if len(phrase) == 0 {
+		return true
+	}
+	for i := 0; i+len(phrase) <= len(text); i++ {
+		match := true
+		for j, word := range phrase {
+			if text[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
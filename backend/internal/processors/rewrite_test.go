@@ -0,0 +1,80 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+const rewriteSource = "The Eiffel Tower is a wrought-iron lattice tower on the Champ de Mars in Paris, France. It was designed by Gustave Eiffel's company and completed in 1889, standing 330 metres tall."
+
+type fakeCompleter struct {
+	text string
+	err  error
+}
+
+func (f fakeCompleter) Complete(ctx context.Context, system, prompt string) (string, error) {
+	return f.text, f.err
+}
+
+func TestCheckRewrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		rewrite string
+		wantErr bool
+	}{
+		{"grounded", "When it was completed in 1889, the Eiffel Tower in Paris stood 330 metres tall.", false},
+		{"new number", "When it was completed in 1889, the Eiffel Tower in Paris stood 324 metres tall.", true},
+		{"new entity", "When it was completed in 1889, the Eiffel Tower in Paris amazed Napoleon with its height.", true},
+		{"entity is a source word", "When it was completed in 1889, the Eiffel Tower in Paris stood 330 metres tall on the Mars.", false},
+		{"entity only part of a source word", "When it was completed in 1889, the Eiffel Tower in Fran stood 330 metres tall.", true},
+		{"too short", "The Eiffel Tower is tall.", true},
+		{"too many sentences", "The Eiffel Tower is in Paris. It was completed in 1889. It is 330 metres tall. It is iron.", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRewrite(rewriteSource, tt.rewrite)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckRewrite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRewriteStageKeepsOriginal(t *testing.T) {
+	rewrite := "When it was completed in 1889, the Eiffel Tower in Paris stood 330 metres tall."
+	fact := &models.Fact{Content: rewriteSource}
+
+	if err := NewRewriteStage(fakeCompleter{text: rewrite}).Apply(context.Background(), fact); err != nil {
+		t.Fatalf("Error applying stage: %v", err)
+	}
+
+	if fact.Content != rewrite {
+		t.Errorf("Expected rewritten content, got %q", fact.Content)
+	}
+	if fact.Metadata.OriginalContent != rewriteSource {
+		t.Error("Expected original content to be kept in metadata")
+	}
+}
+
+func TestRewriteStageFallsBackOnFailure(t *testing.T) {
+	for _, completer := range []fakeCompleter{
+		{err: errors.New("unavailable")},
+		{text: "The Eiffel Tower in Paris was visited by 7 million people in 1889 alone, a record."},
+	} {
+		fact := &models.Fact{Content: rewriteSource}
+		if err := NewRewriteStage(completer).Apply(context.Background(), fact); err != nil {
+			t.Fatalf("Error applying stage: %v", err)
+		}
+
+		if fact.Content != rewriteSource {
+			t.Errorf("Expected original content to be kept, got %q", fact.Content)
+		}
+		if fact.Metadata.RewriteRejected == "" {
+			t.Error("Expected the rejection to be recorded")
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package processors
+
+import "strings"
+
+// stopwords are the words ignored by keyword extraction, keyed by the language
+// names used in FactMetadata.Language. Languages without a list get no
+// stopword filtering.
+var stopwords = map[string]map[string]bool{
+	"English": wordSet(`
+		a about above after again against all also am an and any are as at be
+		because been before being below between both but by can could did do does
+		doing down during each few for from further had has have having he her here
+		hers herself him himself his how i if in into is it its itself just me more
+		most my myself no nor not now of off on once only or other our ours
+		ourselves out over own same she should so some such than that the their
+		theirs them themselves then there these they this those through to too
+		under until up very was we were what when where which while who whom why
+		will with would you your yours yourself yourselves among known called since
+		many one two three first later became become used including however often
+		well within without around several may
+	`),
+	"Spanish": wordSet(`
+		a al algo algunas algunos ante antes como con contra cual cuando de del
+		desde donde durante e el ella ellas ellos en entre era erais eran eras eres
+		es esa esas ese eso esos esta estaba estado estas este esto estos fue
+		fueron ha habia han hasta hay la las le les lo los mas me mi mis mucho muy
+		nada ni no nos nosotros o os otra otro para pero poco por porque que quien
+		se sea ser si sin sobre son su sus también tambien te tiene tu tus un una
+		uno unos y ya yo
+	`),
+	"French": wordSet(`
+		a ai au aux avec ce ces dans de des du elle elles en est et etait été être
+		eu il ils je la le les leur leurs lui ma mais me meme même mes moi mon ne
+		nos notre nous on ont ou où par pas pour qu que qui sa se ses son sont sur
+		ta te tes toi ton tu un une vos votre vous était comme plus aussi entre
+	`),
+	"German": wordSet(`
+		aber alle als also am an auch auf aus bei bin bis bist da dadurch daher
+		darum das dass dein deine dem den der des dessen die dies diese dieser
+		dieses doch dort du durch ein eine einem einen einer eines er es euer eure
+		für hatte hatten hattest hier hinter ich ihr ihre im in ist ja jede jedem
+		jeden jeder jedes jener jenes jetzt kann kannst können könnt machen mein
+		meine mit muss musst müssen nach nachdem nein nicht nun oder seid sein
+		seine sich sie sind soll sollen sondern sonst über um und uns unser unsere
+		unter vom von vor wann warum was weiter weitere wenn wer werde werden
+		werdet weshalb wie wieder wieso wir wird wirst wo woher wohin zu zum zur
+		wurde wurden
+	`),
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,176 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const tagTaxonomyID = "tag_taxonomy"
+
+// ErrInvalidTaxonomy is returned for a taxonomy that cannot be compiled
+var ErrInvalidTaxonomy = errors.New("invalid tag taxonomy")
+
+// CanonicalTag is a curated tag and the raw categories that map onto it
+type CanonicalTag struct {
+	Name     string   `json:"name" bson:"name"`
+	Synonyms []string `json:"synonyms" bson:"synonyms"`
+}
+
+// TagTaxonomy is the editable set of canonical tags and blocked category
+// patterns. Blocked patterns are case-insensitive regular expressions.
+type TagTaxonomy struct {
+	Tags        []CanonicalTag `json:"tags" bson:"tags"`
+	Blocked     []string       `json:"blocked" bson:"blocked"`
+	KeepUnknown bool           `json:"keep_unknown" bson:"keep_unknown"`
+	UpdatedAt   time.Time      `json:"updated_at" bson:"updated_at"`
+}
+
+// DefaultTagTaxonomy blocks Wikipedia's maintenance and tracking categories
+// and keeps every other category as a tag until editors curate them
+func DefaultTagTaxonomy() *TagTaxonomy {
+	return &TagTaxonomy{
+		Tags: []CanonicalTag{
+			{Name: "astronomy", Synonyms: []string{"astrophysics", "outer space", "space science"}},
+			{Name: "computing", Synonyms: []string{"computer science", "computers", "software"}},
+			{Name: "biology", Synonyms: []string{"life sciences", "biological sciences"}},
+		},
+		Blocked: []string{
+			`^(all )?articles? `,
+			`^(all )?pages `,
+			`^(all )?wikipedia `,
+			`^cs1 `,
+			`^webarchive `,
+			`^use (dmy|mdy) dates`,
+			`^use [a-z]+ english`,
+			`^(good|featured) articles`,
+			`^commons category`,
+			`^coordinates on wikidata`,
+			`^harv and sfn`,
+			`short description`,
+			`wikidata`,
+			`disambiguation`,
+			`stubs?$`,
+			`(dead|broken|external) links`,
+			`\bmaint\b`,
+			`tracking categor`,
+		},
+		KeepUnknown: true,
+	}
+}
+
+// TagMapper maps raw source categories onto canonical tags
+type TagMapper struct {
+	canonical   map[string]string
+	blocked     []*regexp.Regexp
+	keepUnknown bool
+}
+
+// NewTagMapper compiles a taxonomy into a mapper
+func NewTagMapper(taxonomy *TagTaxonomy) (*TagMapper, error) {
+	mapper := &TagMapper{
+		canonical:   make(map[string]string),
+		keepUnknown: taxonomy.KeepUnknown,
+	}
+
+	for _, tag := range taxonomy.Tags {
+		name := cleanTag(tag.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: canonical tag without a name", ErrInvalidTaxonomy)
+		}
+		for _, raw := range append([]string{name}, tag.Synonyms...) {
+			raw = cleanTag(raw)
+			if existing, ok := mapper.canonical[raw]; ok && existing != name {
+				return nil, fmt.Errorf("%w: %q maps to both %q and %q", ErrInvalidTaxonomy, raw, existing, name)
+			}
+			mapper.canonical[raw] = name
+		}
+	}
+
+	for _, pattern := range taxonomy.Blocked {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: blocked pattern %q: %v", ErrInvalidTaxonomy, pattern, err)
+		}
+		mapper.blocked = append(mapper.blocked, re)
+	}
+
+	return mapper, nil
+}
+
+// Map turns raw categories into canonical tags, dropping blocked categories,
+// duplicates and, unless the taxonomy keeps them, unknown categories
+func (m *TagMapper) Map(tags []string) []string {
+	mapped := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = cleanTag(tag)
+		if tag == "" || m.Blocked(tag) {
+			continue
+		}
+
+		if canonical, ok := m.canonical[tag]; ok {
+			tag = canonical
+		} else if !m.keepUnknown {
+			continue
+		}
+
+		if !seen[tag] {
+			mapped = append(mapped, tag)
+			seen[tag] = true
+		}
+	}
+
+	return mapped
+}
+
+// Blocked reports whether a raw category matches a blocked pattern
+func (m *TagMapper) Blocked(tag string) bool {
+	for _, re := range m.blocked {
+		if re.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func cleanTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimPrefix(tag, "Category:")
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// LoadTagTaxonomy reads the taxonomy from the settings collection, falling
+// back to the default one if editors have not saved any
+func LoadTagTaxonomy(ctx context.Context, settings *mongo.Collection) (*TagTaxonomy, error) {
+	var taxonomy TagTaxonomy
+	err := settings.FindOne(ctx, bson.M{"_id": tagTaxonomyID}).Decode(&taxonomy)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return DefaultTagTaxonomy(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading tag taxonomy: %w", err)
+	}
+	return &taxonomy, nil
+}
+
+// SaveTagTaxonomy stores the taxonomy in the settings collection
+func SaveTagTaxonomy(ctx context.Context, settings *mongo.Collection, taxonomy *TagTaxonomy) error {
+	_, err := settings.ReplaceOne(ctx,
+		bson.M{"_id": tagTaxonomyID},
+		taxonomy,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("saving tag taxonomy: %w", err)
+	}
+	return nil
+}
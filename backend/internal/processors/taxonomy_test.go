@@ -0,0 +1,69 @@
+package processors
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTagMapperDefault(t *testing.T) {
+	mapper, err := NewTagMapper(DefaultTagTaxonomy())
+	if err != nil {
+		t.Fatalf("Error compiling default taxonomy: %v", err)
+	}
+
+	raw := []string{
+		"Category:Articles with short description",
+		"Short description is different from Wikidata",
+		"CS1 maint: archived copy as title",
+		"Use dmy dates from March 2020",
+		"All articles with unsourced statements",
+		"Webarchive template wayback links",
+		"Astrophysics",
+		"Outer space",
+		"Towers in Paris",
+		"Physics stubs",
+	}
+
+	got := mapper.Map(raw)
+	want := []string{"astronomy", "towers in paris"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Map() = %v, want %v", got, want)
+	}
+}
+
+func TestTagMapperDropsUnknown(t *testing.T) {
+	mapper, err := NewTagMapper(&TagTaxonomy{
+		Tags: []CanonicalTag{{Name: "Paris", Synonyms: []string{"Towers in Paris"}}},
+	})
+	if err != nil {
+		t.Fatalf("Error compiling taxonomy: %v", err)
+	}
+
+	got := mapper.Map([]string{"towers in paris", "1889 establishments in France", "Paris"})
+	if want := []string{"paris"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Map() = %v, want %v", got, want)
+	}
+}
+
+func TestNewTagMapperInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		taxonomy *TagTaxonomy
+	}{
+		{"bad pattern", &TagTaxonomy{Blocked: []string{"(unclosed"}}},
+		{"empty name", &TagTaxonomy{Tags: []CanonicalTag{{Name: " "}}}},
+		{"ambiguous synonym", &TagTaxonomy{Tags: []CanonicalTag{
+			{Name: "space", Synonyms: []string{"astronomy"}},
+			{Name: "science", Synonyms: []string{"Astronomy"}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTagMapper(tt.taxonomy); !errors.Is(err, ErrInvalidTaxonomy) {
+				t.Errorf("NewTagMapper() error = %v, want ErrInvalidTaxonomy", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseCronNext(t *testing.T) {
+	tests := []struct {
+		expr string
+		from string
+		want string
+	}{
+		{"0 */6 * * *", "2024-03-10 07:15", "2024-03-10 12:00"},
+		{"*/15 * * * *", "2024-03-10 07:15", "2024-03-10 07:30"},
+		{"30 6 * * mon-fri", "2024-03-08 07:00", "2024-03-11 06:30"}, // Friday to Monday
+		{"0 0 1 jan *", "2024-03-10 00:00", "2025-01-01 00:00"},
+		{"0 0 29 2 *", "2023-03-01 00:00", "2024-02-29 00:00"},
+		{"0 12 * * 7", "2024-03-10 13:00", "2024-03-17 12:00"},   // 7 is Sunday
+		{"0 0 13 * 5", "2024-03-10 00:00", "2024-03-13 00:00"},   // 13th or a Friday
+		{"5/20 1 * * *", "2024-03-10 01:30", "2024-03-10 01:45"}, // 5, 25, 45
+		{"@daily", "2024-03-10 07:15", "2024-03-11 00:00"},
+		{"@hourly", "2024-03-10 07:15", "2024-03-10 08:00"},
+		{"@every 90m", "2024-03-10 07:15", "2024-03-10 08:45"},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := schedule.Next(date(tt.from)); !got.Equal(date(tt.want)) {
+			t.Errorf("%q after %s = %s, want %s", tt.expr, tt.from, got.Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every 30s",
+		"@every soon",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded", expr)
+		}
+	}
+}
+
+func TestParseCronImpossible(t *testing.T) {
+	schedule, err := ParseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := schedule.Next(date("2024-01-01 00:00")); !next.IsZero() {
+		t.Errorf("February 31st scheduled at %s", next)
+	}
+}
+
+func TestDueRuns(t *testing.T) {
+	hourly, _ := ParseCron("@hourly")
+	now := date("2024-03-10 12:02")
+
+	tests := []struct {
+		name    string
+		policy  string
+		nextRun string
+		want    int
+	}{
+		{"not due", CatchUpOnce, "2024-03-10 13:00", 0},
+		{"on time once", CatchUpOnce, "2024-03-10 12:00", 1},
+		{"on time skip", CatchUpSkip, "2024-03-10 12:00", 1},
+		{"on time backfill", CatchUpBackfill, "2024-03-10 12:00", 1},
+		{"missed once", CatchUpOnce, "2024-03-10 09:00", 1},
+		{"missed backfill", CatchUpBackfill, "2024-03-10 09:00", 4},
+		{"missed backfill capped", CatchUpBackfill, "2024-03-08 09:00", MaxBackfillRuns},
+		{"missed skip with a run due now", CatchUpSkip, "2024-03-10 09:00", 1},
+	}
+
+	for _, tt := range tests {
+		if got := dueRuns(hourly, tt.policy, date(tt.nextRun), now); got != tt.want {
+			t.Errorf("%s: dueRuns = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// Missed runs are skipped when the latest is too late as well
+	daily, _ := ParseCron("@daily")
+	if got := dueRuns(daily, CatchUpSkip, date("2024-03-08 00:00"), now); got != 0 {
+		t.Errorf("skip after downtime: dueRuns = %d, want 0", got)
+	}
+}
@@ -0,0 +1,234 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+)
+
+// EmbeddedScheduler collects facts into a fact repository on a schedule, so
+// the server can run as a single binary without MongoDB. It keeps none of
+// the state the Scheduler keeps in MongoDB: every source runs on one
+// schedule, facts are processed with the default settings, runs are only
+// logged, and there is no publishing calendar or leader lease, since one
+// process owns the data file.
+type EmbeddedScheduler struct {
+	pipeline
+	sources  []collectors.Source
+	facts    storage.FactRepository
+	schedule Schedule
+	collect  sync.Mutex // Held while collecting
+	life     context.Context
+	endLife  context.CancelFunc
+	stopped  chan struct{} // Closed when Start returns
+	mutex    sync.Mutex
+}
+
+// EmbeddedRun counts what happened to the facts of one collection
+type EmbeddedRun struct {
+	Fetched    int
+	Inserted   int
+	Duplicates int
+	Rejected   int
+}
+
+// NewEmbeddedScheduler creates a scheduler storing facts in the repository
+// and recording them in the keyword statistics
+func NewEmbeddedScheduler(facts storage.FactRepository, keywords processors.DocumentFrequencies) *EmbeddedScheduler {
+	life, endLife := context.WithCancel(context.Background())
+	schedule, _ := ParseCron("0 */6 * * *") // Collect facts every 6 hours
+	return &EmbeddedScheduler{
+		pipeline: pipeline{
+			keywords:  keywords,
+			textRules: processors.DefaultTextRules,
+		},
+		sources:  defaultSources(),
+		facts:    facts,
+		schedule: schedule,
+		life:     life,
+		endLife:  endLife,
+	}
+}
+
+// SetSchedule changes the cron expression facts are collected on
+func (e *EmbeddedScheduler) SetSchedule(cron string) error {
+	schedule, err := ParseCron(cron)
+	if err != nil {
+		return err
+	}
+	e.schedule = schedule
+	return nil
+}
+
+// Start collects facts on the schedule until the context is cancelled or
+// Stop is called. A repository without facts is filled at once rather than
+// at the first scheduled time.
+func (e *EmbeddedScheduler) Start(ctx context.Context) error {
+	e.mutex.Lock()
+	if e.life.Err() != nil {
+		e.mutex.Unlock()
+		return ErrStopped
+	}
+	if e.stopped != nil {
+		e.mutex.Unlock()
+		return nil
+	}
+	stopped := make(chan struct{})
+	e.stopped = stopped
+	e.mutex.Unlock()
+	defer close(stopped)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopOnEnd := context.AfterFunc(e.life, cancel)
+	defer stopOnEnd()
+
+	if facts, err := e.facts.Find(ctx, storage.FactFilter{}, storage.FindOptions{Limit: 1}); err == nil && len(facts) == 0 {
+		e.runLogged(ctx)
+	}
+
+	for {
+		timer := time.NewTimer(time.Until(e.schedule.Next(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+			e.runLogged(ctx)
+		}
+	}
+}
+
+// Stop cancels a running collection and waits until Start has returned or
+// ctx is done. A stopped scheduler cannot be started again.
+func (e *EmbeddedScheduler) Stop(ctx context.Context) error {
+	e.mutex.Lock()
+	e.endLife()
+	stopped := e.stopped
+	e.mutex.Unlock()
+
+	if stopped == nil {
+		return nil
+	}
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runLogged collects facts and logs the outcome
+func (e *EmbeddedScheduler) runLogged(ctx context.Context) {
+	run, err := e.CollectFacts(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("Error collecting facts: %v", err)
+	}
+	log.Printf("Collected %d facts: %d new, %d duplicates, %d rejected",
+		run.Fetched, run.Inserted, run.Duplicates, run.Rejected)
+}
+
+// CollectFacts collects facts from all sources now and stores those not
+// stored yet. Only one collection runs at a time.
+func (e *EmbeddedScheduler) CollectFacts(ctx context.Context) (*EmbeddedRun, error) {
+	e.collect.Lock()
+	defer e.collect.Unlock()
+
+	run := &EmbeddedRun{}
+	processor, err := e.newProcessor(defaultProcessorSettings())
+	if err != nil {
+		return run, fmt.Errorf("building processor: %w", err)
+	}
+
+	var errs []error
+	fetchCtx := collectors.WithStoredFunc(ctx, e.storedTitles)
+	for _, source := range e.sources {
+		rawFacts, err := source.GetFacts(fetchCtx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			continue
+		}
+		run.Fetched += len(rawFacts)
+
+		for _, raw := range rawFacts {
+			if err := ctx.Err(); err != nil {
+				return run, err
+			}
+			fact, _, err := processor.Review(ctx, raw)
+			if err != nil {
+				log.Printf("Error processing fact from %s: %v", source.Name(), err)
+			}
+			if fact == nil {
+				run.Rejected++
+				continue
+			}
+
+			inserted, err := e.store(ctx, fact)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("storing fact: %w", err))
+			case inserted:
+				run.Inserted++
+			default:
+				run.Duplicates++
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return run, fmt.Errorf("collecting facts: %v", errs)
+	}
+	return run, nil
+}
+
+// storedTitles reports which of the page titles of a source are stored
+// already, so sources pick pages not collected yet
+func (e *EmbeddedScheduler) storedTitles(ctx context.Context, source string, titles []string) (map[string]bool, error) {
+	wanted := make(map[string]bool, len(titles))
+	for _, title := range titles {
+		wanted[title] = true
+	}
+	stored := make(map[string]bool)
+	err := e.facts.Each(ctx, storage.FactFilter{Source: source}, func(fact *models.Fact) error {
+		if wanted[fact.Metadata.Title] {
+			stored[fact.Metadata.Title] = true
+		}
+		return nil
+	})
+	return stored, err
+}
+
+// store inserts a fact unless one with the same source and title, or the
+// same content when it has no title, is already stored, and reports whether
+// it was inserted
+func (e *EmbeddedScheduler) store(ctx context.Context, fact *models.Fact) (bool, error) {
+	fact.SetContentHash()
+	filter := storage.FactFilter{Content: fact.Content}
+	if fact.Metadata.Title != "" {
+		filter = storage.FactFilter{Source: fact.Source, Title: fact.Metadata.Title}
+	}
+	existing, err := e.facts.Find(ctx, filter, storage.FindOptions{Limit: 1})
+	if err != nil {
+		return false, err
+	}
+	if len(existing) > 0 {
+		return false, nil
+	}
+
+	if err := e.facts.Insert(ctx, fact); err != nil {
+		return false, err
+	}
+	language := fact.Metadata.Language
+	if err := e.keywords.Add(ctx, language, processors.Terms(fact.Content, language)); err != nil {
+		log.Printf("Error updating keyword statistics: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+)
+
+// fakeSource returns the same facts on every collection
+type fakeSource struct {
+	facts []collectors.RawFact
+}
+
+func (f fakeSource) Name() string { return "Fake" }
+
+func (f fakeSource) GetFacts(ctx context.Context) ([]collectors.RawFact, error) {
+	return f.facts, nil
+}
+
+func TestEmbeddedCollectFacts(t *testing.T) {
+	ctx := context.Background()
+	facts := storage.NewMemoryFacts()
+	keywords := processors.NewMemoryDocumentFrequencies()
+	scheduler := NewEmbeddedScheduler(facts, keywords)
+	scheduler.sources = []collectors.Source{fakeSource{facts: []collectors.RawFact{
+		{
+			Content:  "The Eiffel Tower was completed in 1889 and was the tallest man-made structure in the world for 41 years.",
+			Source:   "Fake",
+			Category: "History",
+			URLs:     []string{"https://en.wikipedia.org/wiki/Eiffel_Tower"},
+			Metadata: map[string]string{"title": "Eiffel Tower"},
+		},
+		{Content: "Too short."},
+	}}}
+
+	run, err := scheduler.CollectFacts(ctx)
+	if err != nil {
+		t.Fatalf("CollectFacts: %v", err)
+	}
+	if run.Fetched != 2 || run.Inserted != 1 || run.Rejected != 1 {
+		t.Errorf("first run = %+v, want 2 fetched, 1 inserted and 1 rejected", run)
+	}
+
+	stored, err := facts.Find(ctx, storage.FactFilter{}, storage.FindOptions{})
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("stored facts = %v (%v), want the Eiffel Tower", stored, err)
+	}
+	if stored[0].Metadata.Title != "Eiffel Tower" || len(stored[0].Metadata.Keywords) == 0 {
+		t.Errorf("stored fact = %+v, want it titled and with keywords", stored[0])
+	}
+
+	run, err = scheduler.CollectFacts(ctx)
+	if err != nil {
+		t.Fatalf("CollectFacts again: %v", err)
+	}
+	if run.Inserted != 0 || run.Duplicates != 1 {
+		t.Errorf("second run = %+v, want the fact found a duplicate", run)
+	}
+}
+
+func TestEmbeddedStop(t *testing.T) {
+	scheduler := NewEmbeddedScheduler(storage.NewMemoryFacts(), processors.NewMemoryDocumentFrequencies())
+	scheduler.sources = []collectors.Source{fakeSource{}}
+
+	done := make(chan error, 1)
+	go func() { done <- scheduler.Start(context.Background()) }()
+	for {
+		scheduler.mutex.Lock()
+		started := scheduler.stopped != nil
+		scheduler.mutex.Unlock()
+		if started {
+			break
+		}
+	}
+
+	if err := scheduler.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Start returned %v, want context.Canceled", err)
+	}
+	if err := scheduler.Start(context.Background()); err != ErrStopped {
+		t.Errorf("Start after Stop = %v, want ErrStopped", err)
+	}
+}
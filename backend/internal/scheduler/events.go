@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Collection progress event types
+const (
+	EventRunStarted     = "run_started"
+	EventSourceStarted  = "source_started"
+	EventPageFetched    = "page_fetched"
+	EventSourceFetched  = "source_fetched"
+	EventSourceFailed   = "source_failed"
+	EventFactAccepted   = "fact_accepted"
+	EventFactRejected   = "fact_rejected"
+	EventFactStored     = "fact_stored"
+	EventFactDuplicate  = "fact_duplicate"
+	EventStoreFailed    = "store_failed"
+	EventBatchWritten   = "batch_written"
+	EventRunFinished    = "run_finished"
+	EventInventoryAlert = "inventory_alert"
+	EventEventsDropped  = "events_dropped"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before events are dropped for it
+const subscriberBuffer = 256
+
+// Event is a step of a collection run as it happens. Events about a source
+// carry its counts so far; run events carry the whole run.
+type Event struct {
+	Type      string             `json:"type"`
+	RunID     primitive.ObjectID `json:"run_id"`
+	Source    string             `json:"source,omitempty"`
+	Title     string             `json:"title,omitempty"`
+	Reason    string             `json:"reason,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	Count     int                `json:"count,omitempty"`
+	Stats     *SourceStats       `json:"stats,omitempty"`
+	Run       *CollectionRun     `json:"run,omitempty"`
+	Batch     *BatchStats        `json:"batch,omitempty"`
+	Inventory *CategoryInventory `json:"inventory,omitempty"`
+	Time      time.Time          `json:"time"`
+}
+
+// eventBus fans collection events out to subscribers. Publishing never
+// blocks collection: a subscriber that falls behind misses events and is
+// told how many with an EventEventsDropped event.
+type eventBus struct {
+	mutex       sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	events  chan Event
+	dropped int
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subscribers: make(map[*subscriber]struct{})}
+}
+
+func (b *eventBus) publish(event Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for sub := range b.subscribers {
+		if sub.dropped > 0 {
+			select {
+			case sub.events <- Event{Type: EventEventsDropped, Count: sub.dropped, Time: event.Time}:
+				sub.dropped = 0
+			default:
+				sub.dropped++
+				continue
+			}
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+func (b *eventBus) subscribe() (<-chan Event, func()) {
+	sub := &subscriber{events: make(chan Event, subscriberBuffer)}
+
+	b.mutex.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mutex.Unlock()
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			delete(b.subscribers, sub)
+			b.mutex.Unlock()
+		})
+	}
+}
+
+// Subscribe streams the progress events of the collection runs made by
+// this scheduler until the returned function is called
+func (s *Scheduler) Subscribe() (<-chan Event, func()) {
+	return s.events.subscribe()
+}
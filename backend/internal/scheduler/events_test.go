@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRecorderPublishesEvents(t *testing.T) {
+	bus := newEventBus()
+	events, unsubscribe := bus.subscribe()
+	defer unsubscribe()
+
+	recorder := newRunRecorder(TriggerManual, []string{"wikipedia"}, bus.publish)
+	recorder.sourceStarted("wikipedia")
+	recorder.pageFetched("wikipedia", "Eiffel Tower", nil)
+	recorder.pageFetched("wikipedia", "Missing", errors.New("not found"))
+	recorder.fetched("wikipedia", 1)
+	recorder.accepted("wikipedia", "Eiffel Tower")
+	recorder.inserted("wikipedia", "Eiffel Tower", false)
+	recorder.publishRun(EventRunFinished, recorder.finish(nil, false))
+
+	want := []string{
+		EventSourceStarted, EventPageFetched, EventPageFetched, EventSourceFetched,
+		EventFactAccepted, EventFactStored, EventRunFinished,
+	}
+	for i, eventType := range want {
+		event := <-events
+		if event.Type != eventType || event.RunID != recorder.run.ID {
+			t.Fatalf("event %d = %s for run %s, want %s", i, event.Type, event.RunID.Hex(), eventType)
+		}
+		switch event.Type {
+		case EventPageFetched:
+			if event.Title == "Missing" && event.Error != "not found" {
+				t.Errorf("failed page event = %+v", event)
+			}
+		case EventFactStored:
+			if event.Stats == nil || event.Stats.Inserted != 1 || event.Stats.Fetched != 1 {
+				t.Errorf("stored event stats = %+v", event.Stats)
+			}
+		case EventRunFinished:
+			if event.Run == nil || event.Run.Status != RunSucceeded {
+				t.Errorf("finished event run = %+v", event.Run)
+			}
+		}
+	}
+}
+
+func TestEventBusDropsForSlowSubscribers(t *testing.T) {
+	bus := newEventBus()
+	events, unsubscribe := bus.subscribe()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		bus.publish(Event{Type: EventPageFetched})
+	}
+	for i := 0; i < subscriberBuffer; i++ {
+		<-events
+	}
+
+	// The next event tells the subscriber what it missed
+	bus.publish(Event{Type: EventRunFinished})
+	if event := <-events; event.Type != EventEventsDropped || event.Count != 10 {
+		t.Errorf("event = %+v, want %d dropped", event, 10)
+	}
+	if event := <-events; event.Type != EventRunFinished {
+		t.Errorf("event = %+v, want %s", event, EventRunFinished)
+	}
+
+	unsubscribe()
+	unsubscribe()
+	bus.publish(Event{Type: EventRunStarted})
+	select {
+	case event := <-events:
+		t.Errorf("unsubscribed subscriber got %+v", event)
+	default:
+	}
+}
@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Pages fetched per category and run, from categories at their target buffer
+// to categories with no facts left
+const (
+	minPlannedPages = 1
+	maxPlannedPages = 10
+)
+
+// CategoryInventory is the stock of facts a category has left for the
+// publishing calendar, and how collection is weighted toward it
+type CategoryInventory struct {
+	Category  string   `bson:"category" json:"category"`
+	Available int      `bson:"available" json:"available"`                     // Verified facts not scheduled recently
+	Scheduled int      `bson:"scheduled" json:"scheduled"`                     // Calendar slots filled from today on
+	DailyUse  float64  `bson:"daily_use" json:"daily_use"`                     // Calendar slots filled per day lately
+	DaysLeft  *float64 `bson:"days_left,omitempty" json:"days_left,omitempty"` // Until it runs dry; unset when unused
+	Pages     int      `bson:"pages" json:"pages"`                             // Pages wanted per run, spread over the crawl categories yielding it
+	Alert     bool     `bson:"alert" json:"alert"`                             // Projected to run dry within the alert horizon
+}
+
+// SetInventoryPolicy sets the number of available facts each category should
+// keep in stock, and how many days ahead a category projected to run dry
+// raises an alert
+func (s *Scheduler) SetInventoryPolicy(target, alertDays int) {
+	s.stockTarget = target
+	s.alertDays = alertDays
+}
+
+// Inventory computes the stock of every category that has facts or that the
+// sources' categories yield, with the pages wanted for each
+func (s *Scheduler) Inventory(ctx context.Context) ([]CategoryInventory, error) {
+	inventory, _, err := s.inventory(ctx)
+	return inventory, err
+}
+
+// inventory computes the stock of the categories facts are filed under, and
+// plans the pages of the categories sources crawl from it. Stock is counted
+// in the categories the classifier files facts under, which are not the
+// categories sources crawl, so a crawl category is weighted by the stock of
+// the categories its facts end up in.
+func (s *Scheduler) inventory(ctx context.Context) ([]CategoryInventory, collectors.Plan, error) {
+	stock, err := s.calendar.Stock(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	yields, err := s.crawlYields(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	categories := make(map[string]bool, len(stock))
+	for category := range stock {
+		categories[category] = true
+	}
+	var crawled []string
+	for _, source := range s.sources {
+		if source, ok := source.(collectors.CategorySource); ok {
+			for _, category := range source.Categories() {
+				crawled = append(crawled, category)
+				for yielded := range yieldOf(yields, category) {
+					categories[yielded] = true
+				}
+			}
+		}
+	}
+
+	inventory := make([]CategoryInventory, 0, len(categories))
+	pages := make(map[string]int, len(categories))
+	for category := range categories {
+		item := CategoryInventory{Category: category}
+		if st := stock[category]; st != nil {
+			item.Available = st.Available
+			item.Scheduled = st.Scheduled
+			item.DailyUse = st.DailyUse
+		}
+		item.DaysLeft = daysLeft(item.Available+item.Scheduled, item.DailyUse)
+		item.Alert = item.DaysLeft != nil && *item.DaysLeft < float64(s.alertDays)
+		item.Pages = plannedPages(item.Available, s.stockTarget)
+		pages[category] = item.Pages
+		inventory = append(inventory, item)
+	}
+
+	sort.Slice(inventory, func(i, j int) bool { return inventory[i].Category < inventory[j].Category })
+	return inventory, planPages(crawled, yields, pages), nil
+}
+
+// crawlYields returns, for each category sources crawled, the share of the
+// facts collected from it that were filed under each category
+func (s *Scheduler) crawlYields(ctx context.Context) (map[string]map[string]float64, error) {
+	cursor, err := s.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"metadata.source_category": bson.M{"$nin": bson.A{nil, ""}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"crawled": "$metadata.source_category", "category": "$category"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []struct {
+		ID struct {
+			Crawled  string `bson:"crawled"`
+			Category string `bson:"category"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]int)
+	for _, group := range groups {
+		totals[group.ID.Crawled] += group.Count
+	}
+	yields := make(map[string]map[string]float64, len(totals))
+	for _, group := range groups {
+		if yields[group.ID.Crawled] == nil {
+			yields[group.ID.Crawled] = make(map[string]float64)
+		}
+		yields[group.ID.Crawled][group.ID.Category] = float64(group.Count) / float64(totals[group.ID.Crawled])
+	}
+	return yields, nil
+}
+
+// yieldOf returns the share of each category among the facts collected from
+// a crawl category. One nothing was collected from yet is assumed to yield
+// its own category.
+func yieldOf(yields map[string]map[string]float64, crawled string) map[string]float64 {
+	if shares := yields[crawled]; len(shares) > 0 {
+		return shares
+	}
+	return map[string]float64{crawled: 1}
+}
+
+// planPages gives each crawl category the pages wanted for the categories it
+// yields, weighted by their share of its facts
+func planPages(crawled []string, yields map[string]map[string]float64, pages map[string]int) collectors.Plan {
+	plan := make(collectors.Plan, len(crawled))
+	for _, category := range crawled {
+		var weighted float64
+		for yielded, share := range yieldOf(yields, category) {
+			wanted, ok := pages[yielded]
+			if !ok {
+				wanted = minPlannedPages
+			}
+			weighted += share * float64(wanted)
+		}
+		plan[category] = max(minPlannedPages, int(math.Round(weighted)))
+	}
+	return plan
+}
+
+// daysLeft projects how many days a supply of facts lasts at the daily use,
+// or nil when it is not used. A category without facts has none left.
+func daysLeft(supply int, dailyUse float64) *float64 {
+	var days float64
+	switch {
+	case supply == 0:
+		days = 0
+	case dailyUse > 0:
+		days = float64(supply) / dailyUse
+	default:
+		return nil
+	}
+	return &days
+}
+
+// plannedPages weights collection toward a category by how far its available
+// facts fall short of the target
+func plannedPages(available, target int) int {
+	if target <= 0 || available >= target {
+		return minPlannedPages
+	}
+	shortfall := float64(target-available) / float64(target)
+	return minPlannedPages + int(math.Ceil(shortfall*(maxPlannedPages-minPlannedPages)))
+}
+
+// planCollection computes the inventory before a run, raises alerts for
+// categories running dry and returns a context weighting the sources toward
+// categories below target. Without an inventory the sources collect as usual.
+func (s *Scheduler) planCollection(ctx context.Context, recorder *runRecorder) context.Context {
+	inventory, plan, err := s.inventory(ctx)
+	if err != nil {
+		log.Printf("Error computing fact inventory, collecting without a plan: %v", err)
+		return ctx
+	}
+	recorder.setInventory(inventory)
+
+	for _, item := range inventory {
+		if item.Alert {
+			log.Printf("Inventory alert: %s has %d facts available and %d scheduled, %.1f days left",
+				item.Category, item.Available, item.Scheduled, *item.DaysLeft)
+			recorder.inventoryAlert(item)
+		}
+	}
+	return collectors.WithPlan(ctx, plan)
+}
@@ -0,0 +1,29 @@
+package scheduler
+
+import "testing"
+
+func TestPlanPagesMapsCrawlCategories(t *testing.T) {
+	// The classifier files most Science pages under Space, which runs low
+	yields := map[string]map[string]float64{
+		"Science": {"Space": 0.75, "Science": 0.25},
+		"History": {"History": 1},
+	}
+	pages := map[string]int{
+		"Space":   plannedPages(0, 100),
+		"Science": plannedPages(100, 100),
+		"History": plannedPages(100, 100),
+		"Arts":    plannedPages(50, 100),
+	}
+
+	plan := planPages([]string{"Science", "History", "Arts", "Sports"}, yields, pages)
+	if want := 8; plan["Science"] != want {
+		t.Errorf("Science pages = %d, want %d from the Space shortfall", plan["Science"], want)
+	}
+	if plan["History"] != minPlannedPages {
+		t.Errorf("History pages = %d, want %d at target", plan["History"], minPlannedPages)
+	}
+	// Crawl categories nothing was collected from yield their own category
+	if plan["Arts"] != pages["Arts"] || plan["Sports"] != minPlannedPages {
+		t.Errorf("Arts pages = %d and Sports pages = %d, want %d and %d", plan["Arts"], plan["Sports"], pages["Arts"], minPlannedPages)
+	}
+}
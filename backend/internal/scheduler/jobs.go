@@ -0,0 +1,227 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/leader"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// jobLease is the lease held while collecting, by collection jobs and
+// scheduled runs alike, so only one collection runs at a time across replicas
+const jobLease = "collection_job"
+
+// jobLeaseTTL is how long the job lease outlives a replica that died mid-job
+const jobLeaseTTL = time.Minute
+
+// heartbeatInterval is how often a running job saves its progress, renews
+// its lease and checks whether it was cancelled
+const heartbeatInterval = 5 * time.Second
+
+var (
+	// ErrCollectionRunning is returned when starting a job while another runs
+	ErrCollectionRunning = errors.New("a collection job is already running")
+
+	// ErrNotCancellable is returned when cancelling a run that is not a
+	// running collection job
+	ErrNotCancellable = errors.New("only running collection jobs can be cancelled")
+)
+
+// StartCollection starts collecting from every source in the background and
+// returns the run that tracks its progress. The job is not tied to ctx, so
+// it keeps running after the request that started it, until it finishes, is
+// cancelled or the scheduler stops.
+func (s *Scheduler) StartCollection(ctx context.Context) (*CollectionRun, error) {
+	holder := primitive.NewObjectID().Hex()
+	token, err := s.jobLock.Acquire(ctx, holder, jobLeaseTTL)
+	if errors.Is(err, leader.ErrNotAcquired) {
+		return nil, ErrCollectionRunning
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Register the job before Stop can start waiting for jobs
+	s.mutex.Lock()
+	if s.life.Err() != nil {
+		s.mutex.Unlock()
+		s.jobLock.Release(ctx, holder, token)
+		return nil, ErrStopped
+	}
+	s.jobsWG.Add(1)
+	s.mutex.Unlock()
+
+	jobCtx, cancel := context.WithCancel(s.life)
+	recorder := s.startRun(jobCtx, s.sources, TriggerManual)
+	run := recorder.snapshot()
+
+	s.mutex.Lock()
+	s.jobs[run.ID] = cancel
+	s.mutex.Unlock()
+
+	go func() {
+		defer s.jobsWG.Done()
+		s.runJob(jobCtx, cancel, recorder, holder, token)
+	}()
+	return run, nil
+}
+
+// holdJobLease takes the job lease for a collection run in the foreground
+// and renews it until release is called. The returned context is cancelled
+// when the lease is lost. While a collection job runs it fails with
+// ErrCollectionRunning.
+func (s *Scheduler) holdJobLease(ctx context.Context) (context.Context, func(), error) {
+	holder := primitive.NewObjectID().Hex()
+	token, err := s.jobLock.Acquire(ctx, holder, jobLeaseTTL)
+	if errors.Is(err, leader.ErrNotAcquired) {
+		return nil, nil, ErrCollectionRunning
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	leaseCtx, cancel := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-leaseCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := s.jobLock.Renew(leaseCtx, holder, token, jobLeaseTTL)
+			if errors.Is(err, leader.ErrLeaseLost) {
+				log.Printf("Collection lost its lease, cancelling")
+				cancel()
+				return
+			} else if err != nil {
+				log.Printf("Error renewing collection lease: %v", err)
+			}
+		}
+	}()
+
+	release := func() {
+		cancel()
+		<-renewed
+		releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelRelease()
+		if err := s.jobLock.Release(releaseCtx, holder, token); err != nil {
+			log.Printf("Error releasing collection lease: %v", err)
+		}
+	}
+	return leaseCtx, release, nil
+}
+
+// runJob collects into the recorder, then records the outcome and frees the
+// job lease
+func (s *Scheduler) runJob(ctx context.Context, cancel context.CancelFunc, recorder *runRecorder, holder string, token int64) {
+	id := recorder.run.ID
+	defer func() {
+		s.mutex.Lock()
+		delete(s.jobs, id)
+		s.mutex.Unlock()
+		cancel()
+
+		releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelRelease()
+		if err := s.jobLock.Release(releaseCtx, holder, token); err != nil {
+			log.Printf("Error releasing collection job lease: %v", err)
+		}
+	}()
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		s.heartbeat(ctx, cancel, recorder, holder, token, stop)
+	}()
+
+	errs := s.collectInto(ctx, s.sources, recorder)
+	close(stop)
+	<-stopped
+
+	run, err := s.finishRun(ctx, recorder, errs)
+	if err != nil {
+		log.Printf("Collection job %s %s: %v", id.Hex(), run.Status, err)
+		return
+	}
+	log.Printf("Collection job %s finished", id.Hex())
+}
+
+// heartbeat saves the job's progress and renews its lease until stopped. It
+// cancels the job when an editor asked for it on any replica, or when the
+// lease was lost.
+func (s *Scheduler) heartbeat(ctx context.Context, cancel context.CancelFunc, recorder *runRecorder, holder string, token int64, stop <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		// Keep going when cancelled so the progress is still saved
+		saveCtx := context.WithoutCancel(ctx)
+
+		err := s.jobLock.Renew(saveCtx, holder, token, jobLeaseTTL)
+		if errors.Is(err, leader.ErrLeaseLost) {
+			log.Printf("Collection job lost its lease, cancelling")
+			cancel()
+		} else if err != nil {
+			log.Printf("Error renewing collection job lease: %v", err)
+		}
+
+		run := recorder.snapshot()
+		var stored CollectionRun
+		err = s.runs.FindOneAndUpdate(saveCtx,
+			bson.M{"_id": run.ID},
+			bson.M{"$set": bson.M{"sources": run.Sources, "heartbeat_at": time.Now()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&stored)
+		if err != nil {
+			log.Printf("Error saving collection job progress: %v", err)
+			continue
+		}
+		if stored.CancelRequested {
+			cancel()
+		}
+	}
+}
+
+// CancelCollection cancels a running collection job. The job may run on
+// another replica, which stops at its next heartbeat.
+func (s *Scheduler) CancelCollection(ctx context.Context, id primitive.ObjectID) (*CollectionRun, error) {
+	result, err := s.runs.UpdateOne(ctx,
+		bson.M{"_id": id, "trigger": TriggerManual, "status": RunRunning},
+		bson.M{"$set": bson.M{"cancel_requested": true}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	cancel, local := s.jobs[id]
+	s.mutex.Unlock()
+	if local {
+		cancel()
+	}
+
+	run, err := s.GetRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 && !local {
+		return run, ErrNotCancellable
+	}
+	return run, nil
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/leader"
+)
+
+// memoryLock is a leader.Lock kept in memory for tests
+type memoryLock struct {
+	mutex  sync.Mutex
+	holder string
+	token  int64
+}
+
+func (l *memoryLock) Acquire(ctx context.Context, holder string, ttl time.Duration) (int64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.holder != "" && l.holder != holder {
+		return 0, leader.ErrNotAcquired
+	}
+	l.holder = holder
+	l.token++
+	return l.token, nil
+}
+
+func (l *memoryLock) Renew(ctx context.Context, holder string, token int64, ttl time.Duration) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.holder != holder || l.token != token {
+		return leader.ErrLeaseLost
+	}
+	return nil
+}
+
+func (l *memoryLock) Release(ctx context.Context, holder string, token int64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.holder == holder && l.token == token {
+		l.holder = ""
+	}
+	return nil
+}
+
+func TestHoldJobLeaseExcludesJobs(t *testing.T) {
+	lock := &memoryLock{}
+	s := &Scheduler{jobLock: lock}
+
+	// A manual job holds the lease
+	token, err := lock.Acquire(context.Background(), "job", jobLeaseTTL)
+	if err != nil {
+		t.Fatalf("Error acquiring lease: %v", err)
+	}
+	if _, _, err := s.holdJobLease(context.Background()); !errors.Is(err, ErrCollectionRunning) {
+		t.Fatalf("holdJobLease() during a job = %v, want ErrCollectionRunning", err)
+	}
+	lock.Release(context.Background(), "job", token)
+
+	ctx, release, err := s.holdJobLease(context.Background())
+	if err != nil {
+		t.Fatalf("holdJobLease() = %v", err)
+	}
+	if _, err := lock.Acquire(context.Background(), "job", jobLeaseTTL); !errors.Is(err, leader.ErrNotAcquired) {
+		t.Errorf("Job acquired the lease during a scheduled run: %v", err)
+	}
+	release()
+	if ctx.Err() == nil {
+		t.Error("Lease context still live after release")
+	}
+	if _, err := lock.Acquire(context.Background(), "job", jobLeaseTTL); err != nil {
+		t.Errorf("Lease not released: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scoring"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/verification"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// pipeline configures the processor that collected facts go through
+type pipeline struct {
+	keywords  processors.DocumentFrequencies
+	textRules []processors.TextRule
+	rewriter  processors.Completer
+	judge     processors.Completer
+}
+
+// SetTextRules replaces the cleanup rules applied to collected text
+func (p *pipeline) SetTextRules(rules []processors.TextRule) {
+	p.textRules = rules
+}
+
+// EnableRewrite turns on the LLM rewrite stage using the given completer
+func (p *pipeline) EnableRewrite(completer processors.Completer) {
+	p.rewriter = completer
+}
+
+// EnableVerificationJudge lets the verification stage ask an LLM judge
+// whether facts are supported by the text they were collected from
+func (p *pipeline) EnableVerificationJudge(completer processors.Completer) {
+	p.judge = completer
+}
+
+// processorSettings are the calibrations and trained models a processor is
+// built with
+type processorSettings struct {
+	thresholds processors.DifficultyThresholds
+	model      *processors.CategoryModel // Nil until trained
+	scorer     *scoring.Model            // Nil until trained
+	taxonomy   *processors.TagTaxonomy
+}
+
+// defaultProcessorSettings are the settings used before any calibration or
+// training
+func defaultProcessorSettings() processorSettings {
+	return processorSettings{
+		thresholds: processors.DefaultDifficultyThresholds(),
+		taxonomy:   processors.DefaultTagTaxonomy(),
+	}
+}
+
+// loadProcessorSettings reads the settings saved in the settings collection,
+// falling back to the defaults for those never saved
+func loadProcessorSettings(ctx context.Context, settings *mongo.Collection) (processorSettings, error) {
+	var loaded processorSettings
+	var err error
+
+	if loaded.thresholds, err = processors.LoadDifficultyThresholds(ctx, settings); err != nil {
+		return loaded, err
+	}
+	if loaded.model, err = processors.LoadCategoryModel(ctx, settings); err != nil {
+		return loaded, err
+	}
+	if loaded.scorer, err = scoring.LoadModel(ctx, settings); err != nil {
+		return loaded, err
+	}
+	if loaded.taxonomy, err = processors.LoadTagTaxonomy(ctx, settings); err != nil {
+		return loaded, err
+	}
+	return loaded, nil
+}
+
+// newProcessor creates a processor with every stage configured
+func (p *pipeline) newProcessor(settings processorSettings) (*processors.Processor, error) {
+	tags, err := processors.NewTagMapper(settings.taxonomy)
+	if err != nil {
+		return nil, err
+	}
+
+	processor := processors.NewProcessor()
+	processor.SetTagMapper(tags)
+	processor.SetTextRules(p.textRules)
+	verifier := verification.NewVerifier(p.judge)
+	verifier.SetTextRules(p.textRules)
+	if p.rewriter != nil {
+		// Rewrite first so that later stages see the final text
+		processor.Use(processors.NewRewriteStage(p.rewriter))
+	}
+	processor.Use(
+		processors.NewCategoryStage(settings.model, processors.DefaultCategoryConfidence),
+		processors.NewDifficultyStage(settings.thresholds),
+		processors.NewKeywordStage(p.keywords, processors.DefaultKeywordLimit),
+		// Score before verification so rejected facts never reach the judge
+		scoring.NewStage(settings.scorer),
+		verification.NewStage(verifier),
+	)
+	return processor, nil
+}
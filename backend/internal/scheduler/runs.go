@@ -0,0 +1,313 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// What started a collection run
+const (
+	TriggerSchedule = "schedule" // A source's cron schedule came due
+	TriggerManual   = "manual"   // An editor asked for a run
+	TriggerBoot     = "boot"     // Catching up when the scheduler starts
+)
+
+// Collection run statuses
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+	RunCancelled = "cancelled"
+)
+
+// runTTLIndex is the name of the index that expires old runs
+const runTTLIndex = "started_at_ttl"
+
+// indexOptionsConflict is the MongoDB error code for an existing index with
+// different options
+const indexOptionsConflict = 85
+
+// SourceStats counts what one source produced during a run
+type SourceStats struct {
+	Source        string         `bson:"source" json:"source"`
+	Fetched       int            `bson:"fetched" json:"fetched"`
+	Rejected      map[string]int `bson:"rejected,omitempty" json:"rejected,omitempty"` // By reason
+	Inserted      int            `bson:"inserted" json:"inserted"`
+	HeldForReview int            `bson:"held_for_review" json:"held_for_review"` // Inserted, but not verified
+	Duplicates    int            `bson:"duplicates" json:"duplicates"`
+	Errors        []string       `bson:"errors,omitempty" json:"errors,omitempty"`
+}
+
+// CollectionRun is the record of one collection run
+type CollectionRun struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Trigger    string             `bson:"trigger" json:"trigger"`
+	Status     string             `bson:"status" json:"status"`
+	StartedAt  time.Time          `bson:"started_at" json:"started_at"`
+	FinishedAt time.Time          `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	Sources    []SourceStats      `bson:"sources" json:"sources"`
+	Errors     []string           `bson:"errors,omitempty" json:"errors,omitempty"`
+
+	// Metrics of each bulk write of the run's facts
+	Batches []BatchStats `bson:"batches,omitempty" json:"batches,omitempty"`
+
+	// Stock of each category before the run, which weighted its collection
+	Inventory []CategoryInventory `bson:"inventory,omitempty" json:"inventory,omitempty"`
+
+	// Set on collection jobs, which save their progress as they go
+	HeartbeatAt     time.Time `bson:"heartbeat_at,omitempty" json:"heartbeat_at,omitempty"`
+	CancelRequested bool      `bson:"cancel_requested,omitempty" json:"cancel_requested,omitempty"`
+}
+
+// RunFilter selects collection runs to list
+type RunFilter struct {
+	Trigger string
+	Status  string
+	Source  string
+	Limit   int64
+}
+
+// runRecorder collects the statistics of a run from concurrent sources and
+// publishes each step as an event
+type runRecorder struct {
+	mutex   sync.Mutex
+	run     *CollectionRun
+	sources map[string]*SourceStats
+	emit    func(Event)
+}
+
+func newRunRecorder(trigger string, sources []string, emit func(Event)) *runRecorder {
+	r := &runRecorder{
+		run: &CollectionRun{
+			ID:        primitive.NewObjectID(),
+			Trigger:   trigger,
+			Status:    RunRunning,
+			StartedAt: time.Now(),
+			Sources:   make([]SourceStats, len(sources)),
+		},
+		sources: make(map[string]*SourceStats, len(sources)),
+		emit:    emit,
+	}
+	for i, source := range sources {
+		r.run.Sources[i].Source = source
+		r.sources[source] = &r.run.Sources[i]
+	}
+	return r
+}
+
+// update applies a change to a source's counts and publishes the event with
+// the counts after it
+func (r *runRecorder) update(source string, event Event, apply func(stats *SourceStats)) {
+	r.mutex.Lock()
+	stats := r.sources[source]
+	if apply != nil {
+		apply(stats)
+	}
+	current := copyStats(*stats)
+	r.mutex.Unlock()
+
+	if r.emit == nil {
+		return
+	}
+	event.RunID = r.run.ID
+	event.Source = source
+	event.Stats = &current
+	event.Time = time.Now()
+	r.emit(event)
+}
+
+func (r *runRecorder) sourceStarted(source string) {
+	r.update(source, Event{Type: EventSourceStarted}, nil)
+}
+
+func (r *runRecorder) pageFetched(source, title string, err error) {
+	event := Event{Type: EventPageFetched, Title: title}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	r.update(source, event, nil)
+}
+
+func (r *runRecorder) fetched(source string, n int) {
+	r.update(source, Event{Type: EventSourceFetched, Count: n}, func(stats *SourceStats) { stats.Fetched += n })
+}
+
+func (r *runRecorder) accepted(source, title string) {
+	r.update(source, Event{Type: EventFactAccepted, Title: title}, nil)
+}
+
+func (r *runRecorder) rejected(source, title, reason string) {
+	r.update(source, Event{Type: EventFactRejected, Title: title, Reason: reason}, func(stats *SourceStats) {
+		if stats.Rejected == nil {
+			stats.Rejected = make(map[string]int)
+		}
+		stats.Rejected[reason]++
+	})
+}
+
+func (r *runRecorder) inserted(source, title string, heldForReview bool) {
+	r.update(source, Event{Type: EventFactStored, Title: title}, func(stats *SourceStats) {
+		stats.Inserted++
+		if heldForReview {
+			stats.HeldForReview++
+		}
+	})
+}
+
+func (r *runRecorder) duplicate(source, title string) {
+	r.update(source, Event{Type: EventFactDuplicate, Title: title}, func(stats *SourceStats) { stats.Duplicates++ })
+}
+
+func (r *runRecorder) failed(source, eventType string, err error) {
+	r.update(source, Event{Type: eventType, Error: err.Error()}, func(stats *SourceStats) {
+		stats.Errors = append(stats.Errors, err.Error())
+	})
+}
+
+// setInventory records the inventory the run was planned from
+func (r *runRecorder) setInventory(inventory []CategoryInventory) {
+	r.mutex.Lock()
+	r.run.Inventory = inventory
+	r.mutex.Unlock()
+}
+
+// batchWritten records the metrics of a bulk write and publishes them
+func (r *runRecorder) batchWritten(stats BatchStats) {
+	r.mutex.Lock()
+	r.run.Batches = append(r.run.Batches, stats)
+	r.mutex.Unlock()
+
+	if r.emit != nil {
+		r.emit(Event{Type: EventBatchWritten, RunID: r.run.ID, Batch: &stats, Time: time.Now()})
+	}
+}
+
+// inventoryAlert publishes an alert for a category running dry
+func (r *runRecorder) inventoryAlert(item CategoryInventory) {
+	if r.emit != nil {
+		r.emit(Event{Type: EventInventoryAlert, RunID: r.run.ID, Inventory: &item, Time: time.Now()})
+	}
+}
+
+// publishRun publishes an event carrying the whole run
+func (r *runRecorder) publishRun(eventType string, run *CollectionRun) {
+	if r.emit != nil {
+		r.emit(Event{Type: eventType, RunID: run.ID, Run: run, Time: time.Now()})
+	}
+}
+
+// snapshot returns a copy of the run as recorded so far
+func (r *runRecorder) snapshot() *CollectionRun {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.copyRun()
+}
+
+func (r *runRecorder) copyRun() *CollectionRun {
+	run := *r.run
+	run.Sources = make([]SourceStats, len(r.run.Sources))
+	for i, stats := range r.run.Sources {
+		run.Sources[i] = copyStats(stats)
+	}
+	run.Errors = append([]string(nil), r.run.Errors...)
+	run.Batches = append([]BatchStats(nil), r.run.Batches...)
+	run.Inventory = append([]CategoryInventory(nil), r.run.Inventory...)
+	return &run
+}
+
+func copyStats(stats SourceStats) SourceStats {
+	if stats.Rejected != nil {
+		rejected := make(map[string]int, len(stats.Rejected))
+		for reason, n := range stats.Rejected {
+			rejected[reason] = n
+		}
+		stats.Rejected = rejected
+	}
+	stats.Errors = append([]string(nil), stats.Errors...)
+	return stats
+}
+
+// finish marks the run as done and returns a copy of it
+func (r *runRecorder) finish(errs []error, cancelled bool) *CollectionRun {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.run.FinishedAt = time.Now()
+	r.run.Status = RunSucceeded
+	for _, err := range errs {
+		r.run.Errors = append(r.run.Errors, err.Error())
+	}
+	if len(errs) > 0 {
+		r.run.Status = RunFailed
+	}
+	if cancelled {
+		r.run.Status = RunCancelled
+	}
+
+	return r.copyRun()
+}
+
+// EnsureRunHistory creates the index that deletes collection runs older than
+// retention, updating it if the retention changed
+func (s *Scheduler) EnsureRunHistory(ctx context.Context, retention time.Duration) error {
+	seconds := int32(retention / time.Second)
+	_, err := s.runs.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "started_at", Value: 1}},
+		Options: options.Index().SetName(runTTLIndex).SetExpireAfterSeconds(seconds),
+	})
+
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == indexOptionsConflict {
+		return s.runs.Database().RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: "collection_runs"},
+			{Key: "index", Value: bson.M{"name": runTTLIndex, "expireAfterSeconds": seconds}},
+		}).Err()
+	}
+	return err
+}
+
+// Runs lists collection runs, most recent first
+func (s *Scheduler) Runs(ctx context.Context, filter RunFilter) ([]CollectionRun, error) {
+	query := bson.M{}
+	if filter.Trigger != "" {
+		query["trigger"] = filter.Trigger
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Source != "" {
+		query["sources.source"] = filter.Source
+	}
+
+	opts := options.Find().SetSort(bson.M{"started_at": -1})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cursor, err := s.runs.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []CollectionRun{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// GetRun returns one collection run
+func (s *Scheduler) GetRun(ctx context.Context, id primitive.ObjectID) (*CollectionRun, error) {
+	var run CollectionRun
+	if err := s.runs.FindOne(ctx, bson.M{"_id": id}).Decode(&run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
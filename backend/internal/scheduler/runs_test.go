@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestRunRecorder(t *testing.T) {
+	recorder := newRunRecorder(TriggerManual, []string{"wikipedia", "nasa"}, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder.fetched("wikipedia", 1)
+			recorder.rejected("wikipedia", "", "length")
+		}()
+	}
+	wg.Wait()
+	recorder.rejected("wikipedia", "", "verification")
+	recorder.inserted("wikipedia", "", false)
+	recorder.inserted("wikipedia", "", true)
+	recorder.duplicate("wikipedia", "")
+	recorder.failed("nasa", EventSourceFailed, errors.New("timeout"))
+
+	run := recorder.finish([]error{errors.New("timeout")}, false)
+	if run.Status != RunFailed || run.Trigger != TriggerManual || run.FinishedAt.Before(run.StartedAt) {
+		t.Errorf("run = %+v", run)
+	}
+
+	wiki := run.Sources[0]
+	if wiki.Fetched != 10 || wiki.Rejected["length"] != 10 || wiki.Rejected["verification"] != 1 {
+		t.Errorf("wikipedia fetched/rejected = %d/%v", wiki.Fetched, wiki.Rejected)
+	}
+	if wiki.Inserted != 2 || wiki.HeldForReview != 1 || wiki.Duplicates != 1 {
+		t.Errorf("wikipedia stored = %+v", wiki)
+	}
+	if nasa := run.Sources[1]; len(nasa.Errors) != 1 || nasa.Fetched != 0 {
+		t.Errorf("nasa = %+v", nasa)
+	}
+
+	// The returned run is a copy
+	recorder.inserted("wikipedia", "", false)
+	if run.Sources[0].Inserted != 2 {
+		t.Error("finish returned a run that is still being recorded into")
+	}
+}
+
+func TestRunRecorderSucceeds(t *testing.T) {
+	run := newRunRecorder(TriggerSchedule, []string{"wikipedia"}, nil).finish(nil, false)
+	if run.Status != RunSucceeded || len(run.Errors) != 0 {
+		t.Errorf("run = %+v", run)
+	}
+}
+
+func TestRunRecorderCancelled(t *testing.T) {
+	recorder := newRunRecorder(TriggerManual, []string{"wikipedia"}, nil)
+	recorder.rejected("wikipedia", "", "length")
+	before := recorder.snapshot()
+
+	recorder.rejected("wikipedia", "", "length")
+	if before.Sources[0].Rejected["length"] != 1 || before.Status != RunRunning {
+		t.Errorf("snapshot changed with the run: %+v", before)
+	}
+
+	if run := recorder.finish(nil, true); run.Status != RunCancelled {
+		t.Errorf("status = %s, want %s", run.Status, RunCancelled)
+	}
+}
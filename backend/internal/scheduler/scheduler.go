@@ -2,97 +2,357 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/ZigaoWang/one-fact-app/backend/internal/calendar"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/leader"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scoring"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// ErrStopped is returned when starting a scheduler or job after Stop
+var ErrStopped = errors.New("scheduler stopped")
+
 // Scheduler manages automated fact collection and processing
 type Scheduler struct {
-	sources    []collectors.Source
-	processor  *processors.Processor
-	collection *mongo.Collection
-	interval   time.Duration
-	mutex      sync.Mutex
-	running    bool
+	pipeline
+	sources        []collectors.Source
+	db             *database.Database
+	collection     *mongo.Collection
+	schedules      *mongo.Collection
+	runs           *mongo.Collection
+	elector        *leader.Elector
+	jobLock        leader.Lock
+	jobs           map[primitive.ObjectID]context.CancelFunc
+	events         *eventBus
+	defaultCron    string
+	defaultCatchUp string
+	rescore        time.Duration
+	calendar       *calendar.Calendar
+	calendarDays   int
+	stockTarget    int
+	alertDays      int
+	store          StoreConfig
+	wake           chan struct{}
+	life           context.Context // Ended by Stop
+	endLife        context.CancelFunc
+	jobsWG         sync.WaitGroup
+	stopped        chan struct{} // Closed when Start returns
+	mutex          sync.Mutex
+	running        bool
 }
 
 // NewScheduler creates a new scheduler instance
-func NewScheduler(collection *mongo.Collection) *Scheduler {
+func NewScheduler(db *database.Database) *Scheduler {
+	life, endLife := context.WithCancel(context.Background())
 	return &Scheduler{
-		sources: []collectors.Source{
-			collectors.NewWikipediaSource(),
-			// Add more sources here
+		pipeline: pipeline{
+			keywords:  processors.NewMongoDocumentFrequencies(db.GetCollection("keyword_stats")),
+			textRules: processors.DefaultTextRules,
 		},
-		processor:  processors.NewProcessor(),
-		collection: collection,
-		interval:   6 * time.Hour, // Collect facts every 6 hours
+		sources:        defaultSources(),
+		db:             db,
+		collection:     db.GetCollection("facts"),
+		schedules:      db.GetCollection("source_schedules"),
+		runs:           db.GetCollection("collection_runs"),
+		jobLock:        leader.NewMongoLock(db.GetCollection("leases"), jobLease),
+		jobs:           make(map[primitive.ObjectID]context.CancelFunc),
+		events:         newEventBus(),
+		life:           life,
+		endLife:        endLife,
+		defaultCron:    "0 */6 * * *", // Collect facts every 6 hours
+		defaultCatchUp: CatchUpOnce,
+		rescore:        24 * time.Hour, // Retrain the scoring model daily
+		calendar:       calendar.New(db),
+		calendarDays:   7,
+		stockTarget:    30, // Keep a month of fresh facts per category
+		alertDays:      3,
+		store:          DefaultStoreConfig(),
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// defaultSources returns the sources facts are collected from
+func defaultSources() []collectors.Source {
+	return []collectors.Source{
+		collectors.NewWikipediaSource(),
+		// Add more sources here
 	}
 }
 
-// Start begins the automated fact collection process
+// source returns the source with the given name, or nil
+func (s *Scheduler) source(name string) collectors.Source {
+	for _, source := range s.sources {
+		if source.Name() == name {
+			return source
+		}
+	}
+	return nil
+}
+
+// Start runs each source on its schedule until the context is cancelled.
+// Runs missed while the scheduler was down are caught up according to each
+// source's catch-up policy. With an elector, jobs only run while this
+// replica holds the leader lease.
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.mutex.Lock()
 	if s.running {
 		s.mutex.Unlock()
 		return nil
 	}
+	if s.life.Err() != nil {
+		s.mutex.Unlock()
+		return ErrStopped
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopOnEnd := context.AfterFunc(s.life, cancel)
+	defer stopOnEnd()
+
 	s.running = true
+	s.stopped = make(chan struct{})
+	stopped := s.stopped
 	s.mutex.Unlock()
 
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+	defer func() {
+		s.mutex.Lock()
+		s.running = false
+		s.mutex.Unlock()
+		close(stopped)
+	}()
 
-	// Initial collection
-	if err := s.CollectFacts(ctx); err != nil {
-		log.Printf("Error in initial fact collection: %v", err)
+	if s.elector == nil {
+		s.run(ctx, 0)
+		return ctx.Err()
 	}
+	return s.elector.Run(ctx, s.run)
+}
 
+// SetElector makes the scheduler campaign for leadership before running jobs
+func (s *Scheduler) SetElector(elector *leader.Elector) {
+	s.elector = elector
+}
+
+// run runs the scheduled jobs until the context is cancelled. The token is
+// the leader's fencing token, or 0 without leader election.
+func (s *Scheduler) run(ctx context.Context, token int64) {
+	rescoreTicker := time.NewTicker(s.rescore)
+	defer rescoreTicker.Stop()
+	// Facts collected since the last rescore may still have structural scores,
+	// which are on another scale than learned ones, so rescore before the
+	// calendar is filled by score
+	s.rescoreLogged(ctx)
+	s.fillCalendar(ctx)
+
+	trigger := TriggerBoot
 	for {
+		next := s.runDueSources(ctx, token, trigger)
+		trigger = TriggerSchedule
+		timer := time.NewTimer(time.Until(next))
+
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if err := s.CollectFacts(ctx); err != nil {
-				log.Printf("Error collecting facts: %v", err)
-			}
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-s.wake:
+			timer.Stop()
+		case <-rescoreTicker.C:
+			timer.Stop()
+			s.rescoreLogged(ctx)
+			s.fillCalendar(ctx)
 		}
 	}
 }
 
-// CollectFacts collects facts from all sources
+// rescoreLogged retrains the scoring model and rescores facts, logging the
+// outcome
+func (s *Scheduler) rescoreLogged(ctx context.Context) {
+	_, _, err := s.RescoreFacts(ctx)
+	if errors.Is(err, scoring.ErrNotEnoughEngagement) {
+		log.Printf("Skipping rescoring: %v", err)
+	} else if err != nil {
+		log.Printf("Error rescoring facts: %v", err)
+	}
+}
+
+// SetCalendarDays changes how many days ahead the publishing calendar is
+// filled each day, 0 leaving slots to be filled when they are served
+func (s *Scheduler) SetCalendarDays(days int) {
+	s.calendarDays = days
+}
+
+// fillCalendar fills the empty slots of the publishing calendar for the
+// coming days, so editors can review them before they are served
+func (s *Scheduler) fillCalendar(ctx context.Context) {
+	if s.calendarDays <= 0 {
+		return
+	}
+	filled, err := s.calendar.Fill(ctx, s.calendar.Today(), s.calendarDays, nil)
+	if err != nil {
+		log.Printf("Error filling the publishing calendar: %v", err)
+		return
+	}
+	if filled > 0 {
+		log.Printf("Filled %d publishing calendar slots", filled)
+	}
+}
+
+// RescoreFacts retrains the scoring model on the latest engagement and
+// rescores every stored fact with it. It returns the model and the number of
+// facts whose score changed.
+func (s *Scheduler) RescoreFacts(ctx context.Context) (*scoring.Model, int, error) {
+	model, err := scoring.Retrain(ctx, s.collection, s.db.GetCollection("processor_settings"))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	updated, err := scoring.Rescore(ctx, s.collection, model)
+	if err != nil {
+		return model, updated, err
+	}
+
+	log.Printf("Rescored %d facts (model trained on %d facts, fit %.2f)", updated, model.Facts, model.Fit)
+	return model, updated, nil
+}
+
+// BuildProcessor creates a processor with every stage configured from the
+// settings stored in MongoDB. It is rebuilt for each run so that calibrations
+// made between runs take effect.
+func (s *Scheduler) BuildProcessor(ctx context.Context) (*processors.Processor, error) {
+	settings, err := loadProcessorSettings(ctx, s.db.GetCollection("processor_settings"))
+	if err != nil {
+		return nil, err
+	}
+	return s.newProcessor(settings)
+}
+
+// CollectFacts collects facts from all sources now, regardless of their
+// schedules, unless a collection job is running
 func (s *Scheduler) CollectFacts(ctx context.Context) error {
+	_, err := s.collect(ctx, s.sources, TriggerManual)
+	return err
+}
+
+// collectedFact is a processed fact and the source it came from
+type collectedFact struct {
+	source string
+	fact   *models.Fact
+}
+
+// collect runs the given sources through the processor, stores the facts
+// and records the run in the collection history. It holds the job lease
+// while collecting, so it fails with ErrCollectionRunning during a job.
+func (s *Scheduler) collect(ctx context.Context, sources []collectors.Source, trigger string) (*CollectionRun, error) {
+	ctx, release, err := s.holdJobLease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	recorder := s.startRun(ctx, sources, trigger)
+	return s.finishRun(ctx, recorder, s.collectInto(ctx, sources, recorder))
+}
+
+// startRun records the start of a collection run
+func (s *Scheduler) startRun(ctx context.Context, sources []collectors.Source, trigger string) *runRecorder {
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		names[i] = source.Name()
+	}
+	recorder := newRunRecorder(trigger, names, s.events.publish)
+	run := recorder.snapshot()
+	if _, err := s.runs.InsertOne(ctx, run); err != nil {
+		log.Printf("Error recording collection run: %v", err)
+	}
+	recorder.publishRun(EventRunStarted, run)
+	return recorder
+}
+
+// finishRun records the outcome of a collection run. It is saved even when
+// the run was cancelled.
+func (s *Scheduler) finishRun(ctx context.Context, recorder *runRecorder, errs []error) (*CollectionRun, error) {
+	cancelled := ctx.Err() != nil
+	run := recorder.finish(errs, cancelled)
+
+	if _, err := s.runs.ReplaceOne(context.WithoutCancel(ctx), bson.M{"_id": run.ID}, run); err != nil {
+		log.Printf("Error recording collection run: %v", err)
+	}
+	recorder.publishRun(EventRunFinished, run)
+
+	if cancelled {
+		return run, ctx.Err()
+	}
+	if len(errs) > 0 {
+		return run, fmt.Errorf("collecting facts: %v", errs)
+	}
+	return run, nil
+}
+
+// collectInto collects from the sources, counting what happens to each fact
+// in the recorder, and returns the errors met along the way
+func (s *Scheduler) collectInto(ctx context.Context, sources []collectors.Source, recorder *runRecorder) []error {
+	processor, err := s.BuildProcessor(ctx)
+	if err != nil {
+		return []error{fmt.Errorf("building processor: %w", err)}
+	}
+
 	var wg sync.WaitGroup
-	factsChan := make(chan *processors.ProcessedFact, 100)
-	errorsChan := make(chan error, len(s.sources))
+	factsChan := make(chan collectedFact, s.store.BatchSize)
+	errorsChan := make(chan error, len(sources))
+
+	// Weight the sources toward categories running low, with pages not
+	// collected yet
+	planCtx := collectors.WithStoredFunc(s.planCollection(ctx, recorder), s.storedTitles)
 
 	// Collect facts from all sources concurrently
-	for _, source := range s.sources {
+	for _, source := range sources {
 		wg.Add(1)
 		go func(src collectors.Source) {
 			defer wg.Done()
-			
-			rawFacts, err := src.GetFacts(ctx)
+
+			name := src.Name()
+			recorder.sourceStarted(name)
+			fetchCtx := collectors.WithPageFunc(planCtx, func(title string, err error) {
+				recorder.pageFetched(name, title, err)
+			})
+
+			rawFacts, err := src.GetFacts(fetchCtx)
 			if err != nil {
+				recorder.failed(name, EventSourceFailed, err)
 				errorsChan <- err
 				return
 			}
+			recorder.fetched(name, len(rawFacts))
 
 			// Process each fact
 			for _, raw := range rawFacts {
-				fact, err := s.processor.Process(ctx, raw)
+				if ctx.Err() != nil {
+					return
+				}
+				title := raw.Metadata["title"]
+				fact, reason, err := processor.Review(ctx, raw)
 				if err != nil {
-					log.Printf("Error processing fact from %s: %v", src.Name(), err)
+					log.Printf("Error processing fact from %s: %v", name, err)
+					recorder.rejected(name, title, "error")
 					continue
 				}
-				if fact != nil {
-					factsChan <- fact
+				if fact == nil {
+					recorder.rejected(name, title, reason)
+					continue
 				}
+				recorder.accepted(name, title)
+				factsChan <- collectedFact{source: name, fact: fact}
 			}
 		}(source)
 	}
@@ -104,29 +364,39 @@ func (s *Scheduler) CollectFacts(ctx context.Context) error {
 		close(errorsChan)
 	}()
 
-	// Store facts in MongoDB
-	var errs []error
-	for fact := range factsChan {
-		if _, err := s.collection.InsertOne(ctx, fact); err != nil {
-			errs = append(errs, fmt.Errorf("storing fact: %w", err))
-		}
-	}
+	// Store facts in MongoDB in batches
+	errs := s.storeAll(ctx, factsChan, recorder)
 
 	// Check for errors from sources
 	for err := range errorsChan {
 		errs = append(errs, err)
 	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("collecting facts: %v", errs)
-	}
-
-	return nil
+	return errs
 }
 
-// Stop stops the scheduler
-func (s *Scheduler) Stop() {
+// Stop cancels the scheduled jobs and any collection jobs, and waits until
+// they have finished or ctx is done. Cancelled runs are recorded as such and
+// the leader lease is released. A stopped scheduler cannot be started again.
+func (s *Scheduler) Stop(ctx context.Context) error {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.running = false
+	s.endLife()
+	stopped := s.stopped
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.jobsWG.Wait()
+		if stopped != nil {
+			<-stopped
+		}
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
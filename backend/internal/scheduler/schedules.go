@@ -0,0 +1,297 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Catch-up policies decide what happens to runs that were missed while the
+// scheduler was down
+const (
+	CatchUpSkip     = "skip"     // Wait for the next scheduled run
+	CatchUpOnce     = "once"     // Run once for all missed runs
+	CatchUpBackfill = "backfill" // Run once per missed run, up to MaxBackfillRuns
+)
+
+// MaxBackfillRuns caps the runs made to backfill a long downtime
+const MaxBackfillRuns = 10
+
+// lateTolerance is how late a run may start and still count as on time
+const lateTolerance = 5 * time.Minute
+
+// idleWait is how long the scheduler sleeps when no source has a valid schedule
+const idleWait = time.Hour
+
+var (
+	// ErrUnknownSource is returned for a schedule of a source that does not exist
+	ErrUnknownSource = errors.New("unknown source")
+
+	// ErrInvalidSchedule is returned for an invalid cron expression or catch-up policy
+	ErrInvalidSchedule = errors.New("invalid schedule")
+)
+
+// SourceSchedule is the persisted schedule of one source
+type SourceSchedule struct {
+	Source    string    `bson:"_id" json:"source"`
+	Cron      string    `bson:"cron" json:"cron"`
+	CatchUp   string    `bson:"catch_up" json:"catch_up"`
+	NextRun   time.Time `bson:"next_run" json:"next_run"`
+	LastRun   time.Time `bson:"last_run,omitempty" json:"last_run,omitempty"`
+	LastError string    `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	Fence     int64     `bson:"fence,omitempty" json:"fence,omitempty"` // Token of the leader that claimed the last run
+}
+
+// validateSchedule parses a cron expression and checks the catch-up policy
+func validateSchedule(cron, catchUp string) (Schedule, error) {
+	schedule, err := ParseCron(cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%w: %q never runs", ErrInvalidSchedule, cron)
+	}
+	switch catchUp {
+	case CatchUpSkip, CatchUpOnce, CatchUpBackfill:
+	default:
+		return nil, fmt.Errorf("%w: unknown catch-up policy %q", ErrInvalidSchedule, catchUp)
+	}
+	return schedule, nil
+}
+
+// SetDefaultSchedule sets the schedule given to sources that have none yet
+func (s *Scheduler) SetDefaultSchedule(cron, catchUp string) error {
+	if _, err := validateSchedule(cron, catchUp); err != nil {
+		return err
+	}
+	s.defaultCron = cron
+	s.defaultCatchUp = catchUp
+	return nil
+}
+
+// Schedules returns the schedule of every source, creating the missing ones
+// with the default schedule. New schedules are due immediately.
+func (s *Scheduler) Schedules(ctx context.Context) ([]SourceSchedule, error) {
+	cursor, err := s.schedules.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []SourceSchedule
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+	byName := make(map[string]SourceSchedule, len(stored))
+	for _, schedule := range stored {
+		byName[schedule.Source] = schedule
+	}
+
+	schedules := make([]SourceSchedule, 0, len(s.sources))
+	for _, source := range s.sources {
+		schedule, ok := byName[source.Name()]
+		if !ok {
+			schedule = SourceSchedule{
+				Source:  source.Name(),
+				Cron:    s.defaultCron,
+				CatchUp: s.defaultCatchUp,
+				NextRun: time.Now().Truncate(time.Millisecond), // MongoDB's precision
+			}
+			// Another instance may have created it in the meantime
+			result, err := s.schedules.UpdateOne(ctx,
+				bson.M{"_id": schedule.Source},
+				bson.M{"$setOnInsert": schedule},
+				options.Update().SetUpsert(true),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if result.UpsertedCount == 0 {
+				if err := s.schedules.FindOne(ctx, bson.M{"_id": schedule.Source}).Decode(&schedule); err != nil {
+					return nil, err
+				}
+			}
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// UpdateSchedule changes the cron expression and catch-up policy of a source.
+// The next run is computed from now.
+func (s *Scheduler) UpdateSchedule(ctx context.Context, source, cron, catchUp string) (*SourceSchedule, error) {
+	if s.source(source) == nil {
+		return nil, ErrUnknownSource
+	}
+
+	parsed, err := validateSchedule(cron, catchUp)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule SourceSchedule
+	err = s.schedules.FindOneAndUpdate(ctx,
+		bson.M{"_id": source},
+		bson.M{"$set": bson.M{
+			"cron":     cron,
+			"catch_up": catchUp,
+			"next_run": parsed.Next(time.Now()),
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	// Let a running scheduler pick up the new next run
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return &schedule, nil
+}
+
+// dueRuns returns how many times a source should run now, given when its
+// next run was scheduled and its catch-up policy
+func dueRuns(schedule Schedule, policy string, nextRun, now time.Time) int {
+	if nextRun.After(now) {
+		return 0
+	}
+
+	// Count the scheduled runs up to now, remembering the latest one
+	missed, latest := 0, nextRun
+	for t := nextRun; !t.IsZero() && !t.After(now) && missed <= MaxBackfillRuns; t = schedule.Next(t) {
+		missed++
+		latest = t
+	}
+
+	switch policy {
+	case CatchUpSkip:
+		if now.Sub(latest) <= lateTolerance {
+			return 1
+		}
+		return 0
+	case CatchUpBackfill:
+		if missed > MaxBackfillRuns {
+			return MaxBackfillRuns
+		}
+		return missed
+	default:
+		return 1
+	}
+}
+
+// runDueSources collects from every source whose next run has come, records
+// the outcome and returns the earliest next run. Runs are recorded with the
+// given trigger.
+func (s *Scheduler) runDueSources(ctx context.Context, token int64, trigger string) time.Time {
+	schedules, err := s.Schedules(ctx)
+	if err != nil {
+		log.Printf("Error loading source schedules: %v", err)
+		return time.Now().Add(idleWait)
+	}
+
+	var earliest time.Time
+	for _, schedule := range schedules {
+		parsed, err := validateSchedule(schedule.Cron, schedule.CatchUp)
+		if err != nil {
+			log.Printf("Skipping %s: %v", schedule.Source, err)
+			continue
+		}
+
+		now := time.Now()
+		next := schedule.NextRun
+		if !next.After(now) {
+			next = parsed.Next(now)
+			claimed, err := s.claimRun(ctx, schedule, next, token)
+			if err != nil {
+				log.Printf("Error claiming run of %s: %v", schedule.Source, err)
+			} else if !claimed {
+				log.Printf("Run of %s was claimed by another leader", schedule.Source)
+			} else {
+				s.runSource(ctx, parsed, schedule, now, token, trigger)
+			}
+		}
+
+		if !next.IsZero() && (earliest.IsZero() || next.Before(earliest)) {
+			earliest = next
+		}
+	}
+
+	if earliest.IsZero() {
+		return time.Now().Add(idleWait)
+	}
+	return earliest
+}
+
+// runSource runs a claimed source as many times as its catch-up policy asks
+// and records the outcome
+func (s *Scheduler) runSource(ctx context.Context, parsed Schedule, schedule SourceSchedule, now time.Time, token int64, trigger string) {
+	runs := dueRuns(parsed, schedule.CatchUp, schedule.NextRun, now)
+	if runs > 1 {
+		log.Printf("Catching up %d missed runs of %s", runs, schedule.Source)
+	} else if runs == 0 {
+		log.Printf("Skipping missed runs of %s", schedule.Source)
+		return
+	}
+
+	var lastErr string
+	for i := 0; i < runs; i++ {
+		_, err := s.collect(ctx, []collectors.Source{s.source(schedule.Source)}, trigger)
+		if errors.Is(err, ErrCollectionRunning) {
+			// The job collects from every source, this one included
+			log.Printf("Skipping run of %s: %v", schedule.Source, err)
+			lastErr = err.Error()
+			break
+		}
+		if err != nil {
+			log.Printf("Error collecting from %s: %v", schedule.Source, err)
+			lastErr = err.Error()
+		}
+	}
+	_, err := s.schedules.UpdateOne(ctx,
+		fenced(schedule.Source, token),
+		bson.M{"$set": bson.M{"last_run": now, "last_error": lastErr}},
+	)
+	if err != nil {
+		log.Printf("Error saving schedule of %s: %v", schedule.Source, err)
+	}
+}
+
+// claimRun moves a due schedule to its next run before running it. It fails
+// when another replica moved it first, or when a leader with a newer fencing
+// token has claimed the source, so a leader that lost its lease mid-run
+// cannot run sources again.
+func (s *Scheduler) claimRun(ctx context.Context, schedule SourceSchedule, next time.Time, token int64) (bool, error) {
+	filter := fenced(schedule.Source, token)
+	filter["next_run"] = schedule.NextRun
+
+	update := bson.M{"next_run": next}
+	if token > 0 {
+		update["fence"] = token
+	}
+
+	result, err := s.schedules.UpdateOne(ctx, filter, bson.M{"$set": update})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// fenced filters a schedule, refusing leaders older than the last one to
+// claim it. Without leader election (token 0) there is no fence.
+func fenced(source string, token int64) bson.M {
+	filter := bson.M{"_id": source}
+	if token > 0 {
+		filter["$or"] = bson.A{
+			bson.M{"fence": bson.M{"$exists": false}},
+			bson.M{"fence": bson.M{"$lte": token}},
+		}
+	}
+	return filter
+}
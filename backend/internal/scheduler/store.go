@@ -0,0 +1,340 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StoreConfig tunes how collected facts are written to MongoDB
+type StoreConfig struct {
+	BatchSize     int           // Facts per bulk write
+	FlushInterval time.Duration // Longest a partial batch waits for more facts
+	MaxRetries    int           // Retries of facts failing with transient errors
+	Writers       int           // Bulk writes in flight at once
+}
+
+// DefaultStoreConfig returns the storage settings used unless configured
+func DefaultStoreConfig() StoreConfig {
+	return StoreConfig{
+		BatchSize:     50,
+		FlushInterval: time.Second,
+		MaxRetries:    3,
+		Writers:       2,
+	}
+}
+
+// retryBackoff is the wait before the first retry of a batch, doubled for
+// each retry after it
+const retryBackoff = 200 * time.Millisecond
+
+// transientCodes are the server error codes worth retrying a write for: the
+// codes the driver retries writes on, and write conflicts
+var transientCodes = map[int]bool{
+	6:     true, // HostUnreachable
+	7:     true, // HostNotFound
+	89:    true, // NetworkTimeout
+	91:    true, // ShutdownInProgress
+	112:   true, // WriteConflict
+	189:   true, // PrimarySteppedDown
+	262:   true, // ExceededTimeLimit
+	9001:  true, // SocketException
+	10107: true, // NotWritablePrimary
+	11600: true, // InterruptedAtShutdown
+	11602: true, // InterruptedDueToReplStateChange
+	13435: true, // NotPrimaryNoSecondaryOk
+	13436: true, // NotPrimaryOrSecondary
+}
+
+// BatchStats are the metrics of one bulk write of collected facts
+type BatchStats struct {
+	Size       int       `bson:"size" json:"size"`
+	Inserted   int       `bson:"inserted" json:"inserted"`
+	Duplicates int       `bson:"duplicates" json:"duplicates"`
+	Failed     int       `bson:"failed" json:"failed"`
+	Attempts   int       `bson:"attempts" json:"attempts"`
+	DurationMS int64     `bson:"duration_ms" json:"duration_ms"` // Including retries
+	WrittenAt  time.Time `bson:"written_at" json:"written_at"`
+}
+
+// SetStoreConfig changes how collected facts are batched and written.
+// Settings left at zero keep their defaults.
+func (s *Scheduler) SetStoreConfig(config StoreConfig) {
+	defaults := DefaultStoreConfig()
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.Writers <= 0 {
+		config.Writers = defaults.Writers
+	}
+	s.store = config
+}
+
+// storeAll batches the collected facts and writes each batch with a single
+// bulk upsert, until facts is closed. A batch is written once full or after
+// the flush interval. Writers are limited, so sources block on a full facts
+// channel while the database falls behind. It returns the errors of the
+// facts that could not be stored.
+func (s *Scheduler) storeAll(ctx context.Context, facts <-chan collectedFact, recorder *runRecorder) []error {
+	batches := make(chan []collectedFact)
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		errs  []error
+	)
+	for i := 0; i < s.store.Writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				if batchErrs := s.writeBatch(ctx, batch, recorder); len(batchErrs) > 0 {
+					mutex.Lock()
+					errs = append(errs, batchErrs...)
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(s.store.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []collectedFact
+	flush := func() {
+		if len(batch) > 0 && ctx.Err() == nil {
+			batches <- batch
+		}
+		batch = nil
+	}
+
+	for open := true; open; {
+		select {
+		case collected, ok := <-facts:
+			if !ok {
+				open = false
+				break
+			}
+			if ctx.Err() != nil {
+				continue // Cancelled, let the sources drain
+			}
+			batch = append(batch, collected)
+			if len(batch) >= s.store.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+	flush()
+	close(batches)
+	wg.Wait()
+
+	return errs
+}
+
+// Unique indexes the upserts of collected facts match on, so concurrent
+// upserts of the same fact fail with a duplicate key instead of inserting it
+// twice
+const (
+	titleIndex       = "source_title_unique"
+	contentHashIndex = "content_hash_unique"
+)
+
+// EnsureFactIndexes creates the unique indexes collected facts are
+// deduplicated on. It fails while the facts collection holds duplicates.
+// Facts without a title stored before content hashes are left out of the
+// index until migrate-facts hashes them.
+func (s *Scheduler) EnsureFactIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "source", Value: 1}, {Key: "metadata.title", Value: 1}},
+			Options: options.Index().SetName(titleIndex).SetUnique(true).
+				SetPartialFilterExpression(bson.M{"metadata.title": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{{Key: "content_hash", Value: 1}},
+			Options: options.Index().SetName(contentHashIndex).SetUnique(true).
+				SetPartialFilterExpression(bson.M{"content_hash": bson.M{"$exists": true}}),
+		},
+	})
+	return err
+}
+
+// storedTitles reports which of the page titles of a source are stored
+// already, so sources pick pages not collected yet
+func (s *Scheduler) storedTitles(ctx context.Context, source string, titles []string) (map[string]bool, error) {
+	values, err := s.collection.Distinct(ctx, "metadata.title", bson.M{
+		"source":         source,
+		"metadata.title": bson.M{"$in": titles},
+	})
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(values))
+	for _, value := range values {
+		if title, ok := value.(string); ok {
+			stored[title] = true
+		}
+	}
+	return stored, nil
+}
+
+// upsertModel inserts a fact unless one with the same source and title, or
+// the same content hash when it has no title, is already stored
+func upsertModel(fact *models.Fact) mongo.WriteModel {
+	fact.SetContentHash()
+	filter := bson.M{"content_hash": fact.ContentHash}
+	if fact.Metadata.Title != "" {
+		filter = bson.M{"source": fact.Source, "metadata.title": fact.Metadata.Title}
+	}
+	return mongo.NewUpdateOneModel().
+		SetFilter(filter).
+		SetUpdate(bson.M{"$setOnInsert": fact}).
+		SetUpsert(true)
+}
+
+// writeBatch upserts a batch of facts, retrying the facts that failed with
+// transient errors, and records what happened to each. A write that started
+// is finished even when the run is cancelled, so the counts stay accurate.
+func (s *Scheduler) writeBatch(ctx context.Context, batch []collectedFact, recorder *runRecorder) []error {
+	start := time.Now()
+	stats := BatchStats{Size: len(batch)}
+	writeCtx := context.WithoutCancel(ctx)
+
+	var errs []error
+	pending := batch
+	for attempt := 1; len(pending) > 0; attempt++ {
+		stats.Attempts = attempt
+
+		writes := make([]mongo.WriteModel, len(pending))
+		for i, collected := range pending {
+			writes[i] = upsertModel(collected.fact)
+		}
+		result, err := s.collection.BulkWrite(writeCtx, writes, options.BulkWrite().SetOrdered(false))
+		failures := writeFailures(err, len(pending))
+
+		var retry []collectedFact
+		for i, collected := range pending {
+			fact := collected.fact
+			failure, failed := failures[i]
+			switch {
+			case failed && mongo.IsDuplicateKeyError(failure):
+				// Another run stored the same fact in the meantime
+				stats.Duplicates++
+				recorder.duplicate(collected.source, fact.Metadata.Title)
+			case failed && isTransient(failure) && attempt <= s.store.MaxRetries && ctx.Err() == nil:
+				retry = append(retry, collected)
+			case failed:
+				stats.Failed++
+				failure = fmt.Errorf("storing fact: %w", failure)
+				recorder.failed(collected.source, EventStoreFailed, failure)
+				errs = append(errs, failure)
+			case result != nil && result.UpsertedIDs[int64(i)] != nil:
+				stats.Inserted++
+				recorder.inserted(collected.source, fact.Metadata.Title, fact.NeedsReview)
+				s.addKeywords(writeCtx, fact)
+			default:
+				stats.Duplicates++
+				recorder.duplicate(collected.source, fact.Metadata.Title)
+			}
+		}
+
+		pending = retry
+		if len(pending) > 0 {
+			log.Printf("Retrying %d facts after a transient storage error (attempt %d)", len(pending), attempt)
+			if !sleepContext(ctx, retryBackoff<<(attempt-1)) {
+				for _, collected := range pending {
+					stats.Failed++
+					recorder.failed(collected.source, EventStoreFailed, fmt.Errorf("storing fact: %w", ctx.Err()))
+				}
+				errs = append(errs, fmt.Errorf("storing %d facts: %w", len(pending), ctx.Err()))
+				pending = nil
+			}
+		}
+	}
+
+	stats.DurationMS = time.Since(start).Milliseconds()
+	stats.WrittenAt = time.Now()
+	recorder.batchWritten(stats)
+	return errs
+}
+
+// writeFailures maps the index of each write that failed in a bulk write to
+// its error. Every write failed when the bulk write failed as a whole.
+func writeFailures(err error, n int) map[int]error {
+	if err == nil {
+		return nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil && len(bulkErr.WriteErrors) > 0 {
+		failures := make(map[int]error, len(bulkErr.WriteErrors))
+		for _, writeErr := range bulkErr.WriteErrors {
+			failures[writeErr.Index] = writeErr.WriteError
+		}
+		return failures
+	}
+
+	failures := make(map[int]error, n)
+	for i := 0; i < n; i++ {
+		failures[i] = err
+	}
+	return failures
+}
+
+// isTransient reports whether a write error may succeed when retried
+func isTransient(err error) bool {
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
+	}
+
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	if serverErr.HasErrorLabel("RetryableWriteError") || serverErr.HasErrorLabel("TransientTransactionError") {
+		return true
+	}
+	for code := range transientCodes {
+		if serverErr.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// addKeywords keeps the corpus statistics used for keyword extraction up to
+// date with a stored fact
+func (s *Scheduler) addKeywords(ctx context.Context, fact *models.Fact) {
+	language := fact.Metadata.Language
+	if err := s.keywords.Add(ctx, language, processors.Terms(fact.Content, language)); err != nil {
+		log.Printf("Error updating keyword statistics: %v", err)
+	}
+}
+
+// sleepContext waits for d, or returns false when ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
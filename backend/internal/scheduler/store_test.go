@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestWriteFailures(t *testing.T) {
+	if failures := writeFailures(nil, 3); len(failures) != 0 {
+		t.Errorf("writeFailures(nil) = %v, want none", failures)
+	}
+
+	bulkErr := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+		{WriteError: mongo.WriteError{Index: 1, Code: 11000}},
+		{WriteError: mongo.WriteError{Index: 3, Code: 112}},
+	}}
+	failures := writeFailures(bulkErr, 4)
+	if len(failures) != 2 || failures[1] == nil || failures[3] == nil {
+		t.Fatalf("writeFailures(write errors) = %v, want indexes 1 and 3", failures)
+	}
+	if !mongo.IsDuplicateKeyError(failures[1]) || !isTransient(failures[3]) {
+		t.Errorf("failures = %v, want a duplicate key error and a write conflict", failures)
+	}
+
+	failures = writeFailures(context.DeadlineExceeded, 2)
+	if len(failures) != 2 || !errors.Is(failures[0], context.DeadlineExceeded) {
+		t.Errorf("writeFailures(whole batch) = %v, want every write failed", failures)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{mongo.WriteError{Code: 112}, true},
+		{mongo.WriteError{Code: 11000}, false},
+		{mongo.WriteError{Code: 121}, false}, // Document failed validation
+		{mongo.CommandError{Code: 2, Labels: []string{"RetryableWriteError"}}, true},
+		{mongo.CommandError{Code: 10107}, true},
+		{context.DeadlineExceeded, true},
+		{errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSetStoreConfigDefaults(t *testing.T) {
+	s := &Scheduler{}
+	s.SetStoreConfig(StoreConfig{BatchSize: 10, MaxRetries: -1})
+
+	want := DefaultStoreConfig()
+	want.BatchSize = 10
+	want.MaxRetries = 0
+	if s.store != want {
+		t.Errorf("store config = %+v, want %+v", s.store, want)
+	}
+}
+
+func TestUpsertModelFilters(t *testing.T) {
+	titled := &models.Fact{Content: "Paris is the capital of France.", Source: "Wikipedia", Metadata: models.FactMetadata{Title: "Paris"}}
+	model := upsertModel(titled).(*mongo.UpdateOneModel)
+	if want := (bson.M{"source": "Wikipedia", "metadata.title": "Paris"}); !reflect.DeepEqual(model.Filter, want) {
+		t.Errorf("filter = %v, want %v", model.Filter, want)
+	}
+	if titled.ContentHash != "" {
+		t.Errorf("ContentHash = %q, want none for a titled fact", titled.ContentHash)
+	}
+
+	untitled := &models.Fact{Content: titled.Content}
+	model = upsertModel(untitled).(*mongo.UpdateOneModel)
+	if untitled.ContentHash == "" || !reflect.DeepEqual(model.Filter, bson.M{"content_hash": untitled.ContentHash}) {
+		t.Errorf("filter = %v, want the content hash %q", model.Filter, untitled.ContentHash)
+	}
+	other := &models.Fact{Content: titled.Content + " It lies on the Seine."}
+	if other.SetContentHash(); other.ContentHash == untitled.ContentHash {
+		t.Error("Different content hashed alike")
+	}
+}
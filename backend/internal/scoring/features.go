@@ -0,0 +1,67 @@
+package scoring
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+)
+
+// Engagement weights per event. Shares take the most effort, so they count
+// the most.
+const (
+	LikeWeight     = 1.0
+	ChatOpenWeight = 1.5
+	ShareWeight    = 3.0
+)
+
+// MinServes is the number of times a fact must have been served before its
+// engagement is used for training
+const MinServes = 3
+
+// priorServes is how many serves' worth of the average engagement rate is
+// mixed into each fact's rate, so rarely served facts are not extreme
+const priorServes = 5
+
+// Features describes a fact for the scoring model. Every feature is known
+// when the fact is collected, so new facts can be scored before anyone has
+// seen them.
+func Features(fact *models.Fact) map[string]float64 {
+	r := processors.MeasureReadability(fact.Content)
+
+	features := map[string]float64{
+		"length":          math.Min(float64(len(fact.Content))/300, 2),
+		"grade":           math.Min(r.FleschKincaidGrade/20, 2),
+		"rare_words":      r.RareWordRatio,
+		"numeric_density": r.NumericDensity,
+		"tags":            math.Min(float64(len(fact.Tags)), 5) / 5,
+		"keywords":        math.Min(float64(len(fact.Metadata.Keywords)), 8) / 8,
+	}
+	if len(processors.ExtractNumbers(fact.Content)) > 0 {
+		features["has_number"] = 1
+	}
+	if len(fact.RelatedURLs) > 0 {
+		features["has_url"] = 1
+	}
+	if fact.Category != "" {
+		features["category:"+strings.ToLower(fact.Category)] = 1
+	}
+	if fact.Metadata.Difficulty != "" {
+		features["difficulty:"+strings.ToLower(fact.Metadata.Difficulty)] = 1
+	}
+	return features
+}
+
+// engagementEvents is the weighted number of engagement events of a fact
+func engagementEvents(m models.FactMetadata) float64 {
+	return LikeWeight*float64(m.Popularity) +
+		ChatOpenWeight*float64(m.ChatOpens) +
+		ShareWeight*float64(m.Shares)
+}
+
+// EngagementRate is the weighted engagement per serve, smoothed towards the
+// given prior rate
+func EngagementRate(m models.FactMetadata, prior float64) float64 {
+	return (engagementEvents(m) + prior*priorServes) / float64(m.ServeCount+priorServes)
+}
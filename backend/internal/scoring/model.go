@@ -0,0 +1,297 @@
+package scoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+)
+
+// DefaultMinScore is the learned score below which collected facts are
+// rejected. A score of 1 is the average predicted engagement.
+const DefaultMinScore = 0.5
+
+// MinTrainingFacts is the number of served facts needed to train a model
+const MinTrainingFacts = 20
+
+// ridge is the L2 penalty on feature weights. Most features are sparse, so
+// without it a category seen on a handful of facts would get an extreme weight.
+const ridge = 1.0
+
+// ErrNotEnoughEngagement is returned when too few facts have been served,
+// or the served facts have no engagement to learn from
+var ErrNotEnoughEngagement = errors.New("not enough served facts to train the scoring model")
+
+// Model is a linear model of a fact's engagement per serve. Scores are
+// predictions relative to the mean engagement rate of the training facts.
+type Model struct {
+	Weights   map[string]float64 `json:"weights" bson:"weights"`
+	Intercept float64            `json:"intercept" bson:"intercept"`
+	MeanRate  float64            `json:"mean_rate" bson:"mean_rate"`
+	MinScore  float64            `json:"min_score" bson:"min_score"`
+	Fit       float64            `json:"fit" bson:"fit"` // R² on the training facts
+	Facts     int                `json:"facts" bson:"facts"`
+	TrainedAt time.Time          `json:"trained_at" bson:"trained_at"`
+}
+
+// Contribution is the part of a score that one feature accounts for
+type Contribution struct {
+	Feature      string  `json:"feature"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// Breakdown is a fact's learned score with the contribution of each feature
+type Breakdown struct {
+	Score         float64        `json:"score"`
+	Baseline      float64        `json:"baseline"`
+	Contributions []Contribution `json:"contributions"`
+}
+
+// Train fits a model to served facts. Facts served fewer than MinServes
+// times are ignored.
+func Train(facts []models.Fact) (*Model, error) {
+	var served []models.Fact
+	for _, fact := range facts {
+		if fact.Metadata.ServeCount >= MinServes {
+			served = append(served, fact)
+		}
+	}
+	if len(served) < MinTrainingFacts {
+		return nil, ErrNotEnoughEngagement
+	}
+
+	// The prior for smoothing is the pooled engagement rate
+	var events, serves float64
+	for _, fact := range served {
+		events += engagementEvents(fact.Metadata)
+		serves += float64(fact.Metadata.ServeCount)
+	}
+	prior := events / serves
+	if prior <= 0 {
+		return nil, ErrNotEnoughEngagement
+	}
+
+	// Index the features seen in training
+	rows := make([]map[string]float64, len(served))
+	targets := make([]float64, len(served))
+	index := make(map[string]int)
+	var names []string
+	for i := range served {
+		rows[i] = Features(&served[i])
+		targets[i] = EngagementRate(served[i].Metadata, prior)
+		for name := range rows[i] {
+			if _, ok := index[name]; !ok {
+				index[name] = len(names)
+				names = append(names, name)
+			}
+		}
+	}
+
+	// Solve the ridge normal equations (XᵀX + λI)w = Xᵀy with the intercept
+	// in the last column, which is not penalized
+	n := len(names) + 1
+	xtx := make([][]float64, n)
+	for i := range xtx {
+		xtx[i] = make([]float64, n)
+	}
+	xty := make([]float64, n)
+	for r, row := range rows {
+		x := make([]float64, n)
+		for name, value := range row {
+			x[index[name]] = value
+		}
+		x[n-1] = 1
+
+		for i := 0; i < n; i++ {
+			if x[i] == 0 {
+				continue
+			}
+			xty[i] += x[i] * targets[r]
+			for j := 0; j < n; j++ {
+				xtx[i][j] += x[i] * x[j]
+			}
+		}
+	}
+	for i := 0; i < n-1; i++ {
+		xtx[i][i] += ridge
+	}
+
+	solution, err := solve(xtx, xty)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &Model{
+		Weights:   make(map[string]float64, len(names)),
+		Intercept: solution[n-1],
+		MinScore:  DefaultMinScore,
+		Facts:     len(served),
+		TrainedAt: time.Now(),
+	}
+	for name, i := range index {
+		model.Weights[name] = solution[i]
+	}
+
+	var mean float64
+	for _, y := range targets {
+		mean += y
+	}
+	mean /= float64(len(targets))
+	if mean <= 0 {
+		return nil, ErrNotEnoughEngagement
+	}
+	model.MeanRate = mean
+
+	var residual, total float64
+	for i, row := range rows {
+		diff := targets[i] - model.predict(row)
+		residual += diff * diff
+		total += (targets[i] - mean) * (targets[i] - mean)
+	}
+	if total > 0 {
+		model.Fit = 1 - residual/total
+	}
+
+	return model, nil
+}
+
+// predict adds the weighted features in name order, so the same fact always
+// gets the same score to the last bit
+func (m *Model) predict(features map[string]float64) float64 {
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rate := m.Intercept
+	for _, name := range names {
+		rate += m.Weights[name] * features[name]
+	}
+	return rate
+}
+
+// Score returns the learned score of a fact
+func (m *Model) Score(fact *models.Fact) float64 {
+	return math.Max(m.relative(m.predict(Features(fact))), 0)
+}
+
+// usable reports whether the model has a mean rate to score against. Train
+// never returns one without, but a model saved by an older version may lack it.
+func (m *Model) usable() bool {
+	return m.MeanRate > 0
+}
+
+// relative returns a rate relative to the mean rate
+func (m *Model) relative(rate float64) float64 {
+	if !m.usable() {
+		return 0
+	}
+	return rate / m.MeanRate
+}
+
+// Explain breaks a fact's score down into feature contributions, largest
+// first and ties by name. The baseline is the intercept's share of the score.
+func (m *Model) Explain(fact *models.Fact) *Breakdown {
+	features := Features(fact)
+	breakdown := &Breakdown{
+		Score:    m.Score(fact),
+		Baseline: m.relative(m.Intercept),
+	}
+
+	for name, value := range features {
+		weight, ok := m.Weights[name]
+		if !ok {
+			continue // Not seen in training
+		}
+		breakdown.Contributions = append(breakdown.Contributions, Contribution{
+			Feature:      name,
+			Value:        value,
+			Weight:       weight,
+			Contribution: m.relative(weight * value),
+		})
+	}
+
+	contributions := breakdown.Contributions
+	sort.Slice(contributions, func(i, j int) bool {
+		a, b := math.Abs(contributions[i].Contribution), math.Abs(contributions[j].Contribution)
+		if a != b {
+			return a > b
+		}
+		return contributions[i].Feature < contributions[j].Feature
+	})
+	return breakdown
+}
+
+// solve solves a linear system by Gaussian elimination with partial pivoting
+func solve(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, errors.New("scoring model is singular")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, nil
+}
+
+// Stage replaces the structural score of collected facts with the learned
+// score and rejects facts below the model's minimum. Without a usable model
+// the structural score is kept.
+type Stage struct {
+	model *Model
+}
+
+// NewStage creates a scoring stage. The model may be nil.
+func NewStage(model *Model) *Stage {
+	return &Stage{model: model}
+}
+
+// Name returns the stage name
+func (s *Stage) Name() string {
+	return "scoring"
+}
+
+// Apply scores the fact with the learned model
+func (s *Stage) Apply(ctx context.Context, fact *models.Fact) error {
+	if s.model == nil || !s.model.usable() {
+		return nil
+	}
+
+	fact.Score = s.model.Score(fact)
+	if fact.Score < s.model.MinScore {
+		return fmt.Errorf("%w: learned score %.2f below %.2f", processors.ErrRejected, fact.Score, s.model.MinScore)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package scoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+)
+
+// servedFacts builds facts where Space facts get three times the engagement
+// of History facts
+func servedFacts(n int) []models.Fact {
+	var facts []models.Fact
+	for i := 0; i < n; i++ {
+		fact := models.Fact{
+			Content:  fmt.Sprintf("The fact number %d is about something that was discovered long ago.", i),
+			Category: "History",
+			Tags:     []string{"tag"},
+		}
+		fact.Metadata.ServeCount = 20
+		fact.Metadata.ChatOpens = 2
+		if i%2 == 0 {
+			fact.Category = "Space"
+			fact.Metadata.ChatOpens = 6
+			fact.Metadata.Shares = 1
+		}
+		facts = append(facts, fact)
+	}
+	return facts
+}
+
+func TestTrainLearnsEngagement(t *testing.T) {
+	model, err := Train(servedFacts(40))
+	if err != nil {
+		t.Fatalf("Error training model: %v", err)
+	}
+
+	space := &models.Fact{Content: "The Moon is the only natural satellite of the Earth.", Category: "Space"}
+	history := &models.Fact{Content: "The Moon is the only natural satellite of the Earth.", Category: "History"}
+	if model.Score(space) <= 1 || model.Score(history) >= 1 {
+		t.Errorf("Score(space) = %.2f, Score(history) = %.2f, want above and below average",
+			model.Score(space), model.Score(history))
+	}
+	if model.Fit < 0.9 {
+		t.Errorf("Fit = %.2f, want the category to explain the engagement", model.Fit)
+	}
+
+	breakdown := model.Explain(space)
+	if len(breakdown.Contributions) == 0 || breakdown.Contributions[0].Feature != "category:space" {
+		t.Errorf("Largest contribution = %+v, want category:space", breakdown.Contributions)
+	}
+	total := breakdown.Baseline
+	for _, c := range breakdown.Contributions {
+		total += c.Contribution
+	}
+	if diff := total - breakdown.Score; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Contributions sum to %.4f, score is %.4f", total, breakdown.Score)
+	}
+}
+
+func TestTrainNeedsServedFacts(t *testing.T) {
+	facts := servedFacts(40)
+	for i := range facts {
+		facts[i].Metadata.ServeCount = MinServes - 1
+	}
+
+	if _, err := Train(facts); !errors.Is(err, ErrNotEnoughEngagement) {
+		t.Errorf("Train() error = %v, want ErrNotEnoughEngagement", err)
+	}
+}
+
+func TestTrainNeedsEngagement(t *testing.T) {
+	facts := servedFacts(40)
+	for i := range facts {
+		facts[i].Metadata.ChatOpens = 0
+		facts[i].Metadata.Shares = 0
+	}
+
+	if _, err := Train(facts); !errors.Is(err, ErrNotEnoughEngagement) {
+		t.Errorf("Train() error = %v, want ErrNotEnoughEngagement", err)
+	}
+}
+
+func TestExplainWithoutMeanRate(t *testing.T) {
+	// A model saved without a mean rate neither divides by zero nor rejects
+	model := &Model{Weights: map[string]float64{"category:space": 1}, Intercept: 1, MinScore: DefaultMinScore}
+	fact := &models.Fact{Content: "The Moon is the only natural satellite of the Earth.", Category: "Space", Score: 1.2}
+
+	breakdown := model.Explain(fact)
+	if breakdown.Score != 0 || breakdown.Baseline != 0 {
+		t.Errorf("Explain() = %+v, want zero score and baseline", breakdown)
+	}
+	for _, c := range breakdown.Contributions {
+		if c.Contribution != 0 {
+			t.Errorf("Contribution %+v, want zero", c)
+		}
+	}
+
+	if err := NewStage(model).Apply(context.Background(), fact); err != nil || fact.Score != 1.2 {
+		t.Errorf("Apply() = %v with score %.2f, want the structural score kept", err, fact.Score)
+	}
+}
+
+func TestExplainOrdersTiesByName(t *testing.T) {
+	model := &Model{
+		Weights:  map[string]float64{"difficulty:easy": -0.5, "category:space": 0.5, "has_url": 0.5},
+		MeanRate: 1,
+	}
+	fact := &models.Fact{
+		Content:     "The Moon is the only natural satellite of the Earth.",
+		Category:    "Space",
+		RelatedURLs: []string{"https://en.wikipedia.org/wiki/Moon"},
+	}
+	fact.Metadata.Difficulty = "Easy"
+
+	for i := 0; i < 10; i++ {
+		var names []string
+		for _, c := range model.Explain(fact).Contributions {
+			names = append(names, c.Feature)
+		}
+		if got, want := fmt.Sprint(names), "[category:space difficulty:easy has_url]"; got != want {
+			t.Fatalf("Contributions = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestStageRejectsLowScores(t *testing.T) {
+	model, err := Train(servedFacts(40))
+	if err != nil {
+		t.Fatalf("Error training model: %v", err)
+	}
+	model.MinScore = 1
+
+	fact := &models.Fact{Content: "The Moon is the only natural satellite of the Earth.", Category: "History"}
+	err = NewStage(model).Apply(context.Background(), fact)
+	if !errors.Is(err, processors.ErrRejected) {
+		t.Errorf("Apply() error = %v, want ErrRejected", err)
+	}
+
+	fact.Category = "Space"
+	if err := NewStage(model).Apply(context.Background(), fact); err != nil {
+		t.Errorf("Apply() error = %v", err)
+	}
+	if fact.Score != model.Score(fact) {
+		t.Errorf("Score = %.2f, want the learned score", fact.Score)
+	}
+}
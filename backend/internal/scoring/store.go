@@ -0,0 +1,106 @@
+package scoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const modelID = "scoring_model"
+
+// LoadModel reads the trained model from the settings collection. It returns
+// nil if no model has been trained yet.
+func LoadModel(ctx context.Context, settings *mongo.Collection) (*Model, error) {
+	var model Model
+	err := settings.FindOne(ctx, bson.M{"_id": modelID}).Decode(&model)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading scoring model: %w", err)
+	}
+	return &model, nil
+}
+
+// SaveModel stores a trained model in the settings collection
+func SaveModel(ctx context.Context, settings *mongo.Collection, model *Model) error {
+	_, err := settings.ReplaceOne(ctx,
+		bson.M{"_id": modelID},
+		model,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("saving scoring model: %w", err)
+	}
+	return nil
+}
+
+// Retrain trains a model on the served facts and saves it
+func Retrain(ctx context.Context, facts, settings *mongo.Collection) (*Model, error) {
+	cursor, err := facts.Find(ctx, bson.M{
+		"verified":             true,
+		"metadata.serve_count": bson.M{"$gte": MinServes},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var served []models.Fact
+	if err := cursor.All(ctx, &served); err != nil {
+		return nil, err
+	}
+
+	model, err := Train(served)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := SaveModel(ctx, settings, model); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// Rescore updates the score of every stored fact with the model and returns
+// how many scores changed
+func Rescore(ctx context.Context, facts *mongo.Collection, model *Model) (int, error) {
+	cursor, err := facts.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var writes []mongo.WriteModel
+	for cursor.Next(ctx) {
+		var fact models.Fact
+		if err := cursor.Decode(&fact); err != nil {
+			return 0, err
+		}
+
+		score := model.Score(&fact)
+		if math.Abs(score-fact.Score) < 1e-9 {
+			continue
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": fact.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"score": score, "updated_at": time.Now()}}))
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(writes) == 0 {
+		return 0, nil
+	}
+	if _, err := facts.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return 0, err
+	}
+	return len(writes), nil
+}
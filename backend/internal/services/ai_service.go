@@ -45,6 +45,11 @@ func NewAIService() *AIService {
 	}
 }
 
+// Enabled reports whether an API key is configured
+func (s *AIService) Enabled() bool {
+	return s.apiKey != ""
+}
+
 // Message represents a chat message for the OpenAI API
 type Message struct {
 	Role    string `json:"role"`
@@ -212,6 +217,69 @@ func (s *AIService) ProcessChat(ctx context.Context, messages []models.Message,
 	}, nil
 }
 
+// Complete sends a single system and user prompt to OpenAI and returns the
+// generated text. It is used by the processing pipeline.
+func (s *AIService) Complete(ctx context.Context, system, prompt string) (string, error) {
+	if s.apiKey == "" {
+		return "", fmt.Errorf("OpenAI API key is not set")
+	}
+
+	model := os.Getenv("OPENAI_MODEL")
+	if model == "" {
+		model = "gpt-3.5-turbo" // Default model
+	}
+
+	reqBody := CompletionRequest{
+		Model: model,
+		Messages: []Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+		Temperature: 0.3, // Keep rewrites close to the source
+		MaxTokens:   200,
+	}
+
+	reqBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		fmt.Sprintf("%s/v1/chat/completions", s.baseURL),
+		bytes.NewBuffer(reqBytes),
+	)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("API returned non-200 status code %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var completionResp CompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completionResp); err != nil {
+		return "", fmt.Errorf("error decoding response: %w", err)
+	}
+
+	if len(completionResp.Choices) == 0 {
+		return "", fmt.Errorf("no choices returned from the API")
+	}
+
+	return completionResp.Choices[0].Message.Content, nil
+}
+
 // ProcessStreamChat sends messages to OpenAI and streams the response back through the provided writer
 func (s *AIService) ProcessStreamChat(ctx context.Context, messages []models.Message, fact *models.Fact, w http.ResponseWriter) error {
 	if s.apiKey == "" {
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestAIService_ProcessChat(t *testing.T) {
@@ -20,10 +21,10 @@ func TestAIService_ProcessChat(t *testing.T) {
 
 	// Create a test fact
 	fact := &models.Fact{
-		ID:       "test-fact-id",
-		Title:    "Test Fact",
+		ID:       primitive.NewObjectID(),
 		Content:  "The Great Wall of China is not visible from space with the naked eye.",
 		Category: "History",
+		Metadata: models.FactMetadata{Title: "Test Fact"},
 	}
 
 	// Create test messages
@@ -69,10 +70,10 @@ func TestAIService_ProcessChatNoAPIKey(t *testing.T) {
 
 	// Create a test fact
 	fact := &models.Fact{
-		ID:       "test-fact-id",
-		Title:    "Test Fact",
+		ID:       primitive.NewObjectID(),
 		Content:  "The Great Wall of China is not visible from space with the naked eye.",
 		Category: "History",
+		Metadata: models.FactMetadata{Title: "Test Fact"},
 	}
 
 	// Create test messages
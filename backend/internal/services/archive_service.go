@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/calendar"
+)
+
+// defaultHistoryDays is how many days of daily facts are listed when no range
+// is given
+const defaultHistoryDays = 30
+
+// Cache lifetimes of archive reads. Past days do not change, but the history
+// includes today, whose fact may still be decided or changed.
+const (
+	archiveCacheTTL = 24 * time.Hour
+	historyCacheTTL = 5 * time.Minute
+)
+
+// GetArchivedDailyFact returns the daily fact of a category on a local date
+// in loc, or in the default time zone when loc is nil. Today's fact is
+// decided if it was not yet.
+func (s *FactService) GetArchivedDailyFact(ctx context.Context, date, category string, loc *time.Location) (*calendar.ArchiveEntry, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		loc = s.location
+	}
+	date, err := calendar.ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	today, _ := calendar.Day(time.Now().In(loc))
+	if date > today {
+		return nil, fmt.Errorf("%w: %s", calendar.ErrNotPublished, date)
+	}
+	if date == today {
+		if _, err := s.GetDailyFact(ctx, category, loc, false); err != nil {
+			return nil, err
+		}
+		return s.archive.Entry(ctx, date, category)
+	}
+
+	key := fmt.Sprintf("daily_archive:%s:%s", date, category)
+	var entry calendar.ArchiveEntry
+	if s.cache != nil {
+		if ok, err := s.cache.GetJSON(ctx, key, &entry); err == nil && ok {
+			return &entry, nil
+		}
+	}
+
+	found, err := s.archive.Entry(ctx, date, category)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		if err := s.cache.SetJSON(ctx, key, found, archiveCacheTTL); err != nil {
+			log.Printf("Error caching archived daily fact: %v", err)
+		}
+	}
+	return found, nil
+}
+
+// GetDailyHistory lists the daily facts from one local date to another in
+// loc, most recent first, by default for the last 30 days. Days that have not
+// begun are left out.
+func (s *FactService) GetDailyHistory(ctx context.Context, from, to, category string, loc *time.Location) ([]calendar.ArchiveEntry, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		loc = s.location
+	}
+
+	today, _ := calendar.Day(time.Now().In(loc))
+	if to == "" || to > today {
+		to = today
+	}
+	if from == "" {
+		end, err := calendar.ParseDate(to)
+		if err != nil {
+			return nil, err
+		}
+		from = calendar.AddDays(end, 1-defaultHistoryDays)
+	}
+
+	key := fmt.Sprintf("daily_history:%s:%s:%s", from, to, category)
+	entries := []calendar.ArchiveEntry{}
+	if s.cache != nil {
+		if ok, err := s.cache.GetJSON(ctx, key, &entries); err == nil && ok {
+			return entries, nil
+		}
+	}
+
+	entries, err := s.archive.History(ctx, from, to, category)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		if err := s.cache.SetJSON(ctx, key, entries, historyCacheTTL); err != nil {
+			log.Printf("Error caching daily fact history: %v", err)
+		}
+	}
+	return entries, nil
+}
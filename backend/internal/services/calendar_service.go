@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/calendar"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultCalendarDays is how many days are listed or filled when no range is given
+const defaultCalendarDays = 14
+
+// CalendarSwap asks to exchange the facts of a category on two days
+type CalendarSwap struct {
+	Category string `json:"category"`
+	Date     string `json:"date"`
+	With     string `json:"with"`
+}
+
+// CalendarFill asks to fill the empty slots of the coming days
+type CalendarFill struct {
+	From       string   `json:"from,omitempty"`
+	Days       int      `json:"days,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+}
+
+// EnsureCalendar creates the indexes of the publishing calendar and the
+// daily fact archive
+func (s *FactService) EnsureCalendar(ctx context.Context) error {
+	if err := s.needsMongoDB(); err != nil {
+		return err
+	}
+	if err := s.calendar.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+	return s.archive.EnsureIndexes(ctx)
+}
+
+// GetCalendar lists the scheduled facts from one date to another, by default
+// for the coming two weeks
+func (s *FactService) GetCalendar(ctx context.Context, from, to, category string) ([]calendar.Slot, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	if from == "" {
+		from = s.calendar.Today()
+	}
+	if to == "" {
+		start, err := calendar.ParseDate(from)
+		if err != nil {
+			return nil, err
+		}
+		to = calendar.AddDays(start, defaultCalendarDays-1)
+	}
+	return s.calendar.Slots(ctx, from, to, category)
+}
+
+// FillCalendar fills the empty slots of the coming days from the pool and
+// returns the number filled
+func (s *FactService) FillCalendar(ctx context.Context, fill CalendarFill) (int, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return 0, err
+	}
+	if fill.From == "" {
+		fill.From = s.calendar.Today()
+	}
+	if fill.Days == 0 {
+		fill.Days = defaultCalendarDays
+	}
+	return s.calendar.Fill(ctx, fill.From, fill.Days, fill.Categories)
+}
+
+// PinCalendarFact schedules a fact for a category on a day
+func (s *FactService) PinCalendarFact(ctx context.Context, date, category string, factID primitive.ObjectID) (*calendar.Slot, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	slot, err := s.calendar.Pin(ctx, date, category, factID)
+	if err != nil {
+		return nil, err
+	}
+	s.forgetDailyFact(ctx, slot.Date, slot.Category)
+	return slot, nil
+}
+
+// ClearCalendarSlot empties a slot so that it is filled from the pool again
+func (s *FactService) ClearCalendarSlot(ctx context.Context, date, category string) error {
+	if err := s.needsMongoDB(); err != nil {
+		return err
+	}
+	if err := s.calendar.Clear(ctx, date, category); err != nil {
+		return err
+	}
+	s.forgetDailyFact(ctx, date, category)
+	return nil
+}
+
+// SwapCalendarFacts exchanges the facts of a category on two days
+func (s *FactService) SwapCalendarFacts(ctx context.Context, swap CalendarSwap) ([]calendar.Slot, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	slots, err := s.calendar.Swap(ctx, swap.Category, swap.Date, swap.With)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		s.forgetDailyFact(ctx, slot.Date, slot.Category)
+	}
+	return slots, nil
+}
+
+// forgetScheduledFact drops the cached daily facts of the days a fact is
+// scheduled or was served for, so an edit or deletion reaches clients on
+// their next revalidation rather than at the rotation
+func (s *FactService) forgetScheduledFact(ctx context.Context, fact *models.Fact) {
+	dates := make(map[string]bool)
+	if !fact.PublishDate.IsZero() {
+		dates[calendar.PublishedDate(fact.PublishDate)] = true
+	}
+	if fact.Metadata.ServedDate != "" {
+		dates[fact.Metadata.ServedDate] = true
+	}
+	for date := range dates {
+		s.forgetDailyFact(ctx, date, fact.Category)
+	}
+}
+
+// forgetDailyFact drops the cached daily facts of a category on a date whose
+// slot changed. The date may already have begun in time zones ahead.
+func (s *FactService) forgetDailyFact(ctx context.Context, date, category string) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.ClearDailyFacts(ctx, date, category); err != nil {
+		log.Printf("Error clearing cached daily fact: %v", err)
+	}
+}
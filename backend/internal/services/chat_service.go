@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultSessionsLimit is how many chat sessions are listed when no limit is
+// given
+const defaultSessionsLimit = 50
+
+// SaveChat records a chat exchange. Clients send the whole conversation with
+// every message, so the session holds the messages sent followed by the
+// reply. An empty or unknown session ID starts a new session about the fact.
+func (s *FactService) SaveChat(ctx context.Context, sessionID string, fact *models.Fact, messages []models.Message, reply *models.Message) (*models.ChatSession, error) {
+	session := &models.ChatSession{}
+	if id, err := primitive.ObjectIDFromHex(sessionID); err == nil {
+		existing, err := s.chats.GetSession(ctx, id)
+		switch {
+		case err == nil:
+			session = existing
+		case !errors.Is(err, storage.ErrNotFound):
+			return nil, err
+		}
+	}
+
+	if session.ID.IsZero() && !fact.ID.IsZero() {
+		session.FactID = fact.ID.Hex()
+	}
+	session.Messages = append(append([]models.Message{}, messages...), *reply)
+	session.UpdatedAt = time.Now()
+
+	if err := s.chats.SaveSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetChatSession returns a chat session by ID
+func (s *FactService) GetChatSession(ctx context.Context, id primitive.ObjectID) (*models.ChatSession, error) {
+	return s.chats.GetSession(ctx, id)
+}
+
+// GetChatSessions lists chat sessions, most recently updated first
+func (s *FactService) GetChatSessions(ctx context.Context, filter storage.ChatFilter) ([]models.ChatSession, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSessionsLimit
+	}
+	return s.chats.ListSessions(ctx, filter)
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSaveChat(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t)
+	fact := &models.Fact{ID: primitive.NewObjectID(), Content: "fact"}
+
+	sent := []models.Message{{Role: "user", Content: "Why?"}}
+	session, err := service.SaveChat(ctx, "", fact, sent, &models.Message{Role: "assistant", Content: "Because."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.ID.IsZero() || session.FactID != fact.ID.Hex() || len(session.Messages) != 2 {
+		t.Fatalf("SaveChat() = %+v, want a new session about the fact with both messages", session)
+	}
+
+	// The client sends the whole conversation again to continue it
+	sent = append(session.Messages, models.Message{Role: "user", Content: "Really?"})
+	continued, err := service.SaveChat(ctx, session.ID.Hex(), fact, sent, &models.Message{Role: "assistant", Content: "Yes."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if continued.ID != session.ID || len(continued.Messages) != 4 {
+		t.Errorf("continued session = %s with %d messages, want %s with 4", continued.ID.Hex(), len(continued.Messages), session.ID.Hex())
+	}
+
+	// Fallback facts without an ID and unknown sessions start new sessions
+	other, err := service.SaveChat(ctx, primitive.NewObjectID().Hex(), &models.Fact{}, nil, &models.Message{Role: "assistant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.ID == session.ID || other.FactID != "" {
+		t.Errorf("SaveChat(unknown session) = %+v, want a new session without a fact", other)
+	}
+
+	stored, err := service.GetChatSession(ctx, session.ID)
+	if err != nil || len(stored.Messages) != 4 {
+		t.Fatalf("GetChatSession() = %+v, %v", stored, err)
+	}
+	sessions, _ := service.GetChatSessions(ctx, storage.ChatFilter{FactID: fact.ID.Hex()})
+	if len(sessions) != 1 {
+		t.Errorf("GetChatSessions(fact) = %d sessions, want 1", len(sessions))
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scheduler"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultRunsLimit is how many collection runs are listed when no limit is given
+const defaultRunsLimit = 50
+
+// GetCollectionRuns lists collection runs, most recent first
+func (s *FactService) GetCollectionRuns(ctx context.Context, filter scheduler.RunFilter) ([]scheduler.CollectionRun, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultRunsLimit
+	}
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, err
+	}
+	return sched.Runs(ctx, filter)
+}
+
+// GetCollectionRun returns one collection run with its per-source statistics
+func (s *FactService) GetCollectionRun(ctx context.Context, id primitive.ObjectID) (*scheduler.CollectionRun, error) {
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, err
+	}
+	return sched.GetRun(ctx, id)
+}
+
+// StartCollection starts a collection job in the background and returns the
+// run that tracks it
+func (s *FactService) StartCollection(ctx context.Context) (*scheduler.CollectionRun, error) {
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, err
+	}
+	return sched.StartCollection(ctx)
+}
+
+// CancelCollection cancels a running collection job
+func (s *FactService) CancelCollection(ctx context.Context, id primitive.ObjectID) (*scheduler.CollectionRun, error) {
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, err
+	}
+	return sched.CancelCollection(ctx, id)
+}
+
+// SubscribeCollectionEvents streams the progress of the collection runs made
+// by the server's scheduler until the returned function is called
+func (s *FactService) SubscribeCollectionEvents() (<-chan scheduler.Event, func(), error) {
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, nil, err
+	}
+	events, unsubscribe := sched.Subscribe()
+	return events, unsubscribe, nil
+}
+
+// GetInventory returns the stock of facts of every category and how the
+// next collection is weighted toward them
+func (s *FactService) GetInventory(ctx context.Context) ([]scheduler.CategoryInventory, error) {
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, err
+	}
+	return sched.Inventory(ctx)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidDifficulty is returned for difficulty levels other than Easy, Medium or Hard
+var ErrInvalidDifficulty = errors.New("difficulty must be Easy, Medium or Hard")
+
+// OverrideDifficulty stores an editor's difficulty for a fact. The label
+// takes effect on the thresholds at the next CalibrateDifficulty, so a run of
+// corrections does not rescan the labeled facts on every one.
+func (s *FactService) OverrideDifficulty(ctx context.Context, id primitive.ObjectID, difficulty string) error {
+	switch difficulty {
+	case models.DifficultyEasy, models.DifficultyMedium, models.DifficultyHard:
+	default:
+		return ErrInvalidDifficulty
+	}
+
+	return s.facts.Update(ctx, id, storage.FactUpdate{Set: map[string]interface{}{
+		"metadata.difficulty":        difficulty,
+		"metadata.difficulty_source": models.DifficultySourceEditor,
+		"updated_at":                 time.Now(),
+	}})
+}
+
+// CalibrateDifficulty recalibrates the difficulty thresholds against every
+// fact whose difficulty was set by an editor and saves the result for the
+// processor to use on its next run
+func (s *FactService) CalibrateDifficulty(ctx context.Context) (*processors.DifficultyThresholds, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	editorLabeled := true
+	var samples []processors.DifficultySample
+	err := s.facts.Each(ctx, storage.FactFilter{EditorLabeled: &editorLabeled}, func(fact *models.Fact) error {
+		samples = append(samples, processors.DifficultySample{
+			Content:    fact.Content,
+			Difficulty: fact.Metadata.Difficulty,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds, err := processors.CalibrateDifficulty(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := processors.SaveDifficultyThresholds(ctx, s.db.GetCollection("processor_settings"), thresholds); err != nil {
+		return nil, err
+	}
+
+	return &thresholds, nil
+}
+
+// ReclassifyDifficulty recomputes the difficulty of every fact that was not
+// labeled by an editor using the given thresholds. It returns the number of
+// facts whose difficulty changed.
+func (s *FactService) ReclassifyDifficulty(ctx context.Context, thresholds processors.DifficultyThresholds) (int, error) {
+	editorLabeled := false
+	changed := 0
+	err := s.facts.Each(ctx, storage.FactFilter{EditorLabeled: &editorLabeled}, func(fact *models.Fact) error {
+		difficulty := thresholds.Classify(processors.MeasureReadability(fact.Content))
+		if difficulty == fact.Metadata.Difficulty {
+			return nil
+		}
+
+		if err := s.facts.Update(ctx, fact.ID, storage.FactUpdate{Set: map[string]interface{}{
+			"metadata.difficulty":        difficulty,
+			"metadata.difficulty_source": models.DifficultySourceComputed,
+		}}); err != nil {
+			return err
+		}
+		changed++
+		return nil
+	})
+
+	return changed, err
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const hardText = "Notwithstanding considerable institutional opposition, the comprehensive constitutional reorganization fundamentally transformed administrative responsibilities throughout the metropolitan jurisdictions."
+
+func TestOverrideDifficulty(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService(t, models.Fact{Content: "Cats purr.", Metadata: models.FactMetadata{Difficulty: models.DifficultyEasy}})
+	facts, _ := repo.Find(ctx, storage.FactFilter{}, storage.FindOptions{})
+	id := facts[0].ID
+
+	if err := service.OverrideDifficulty(ctx, id, models.DifficultyHard); err != nil {
+		t.Fatal(err)
+	}
+	metadata := getFact(t, repo, id).Metadata
+	if metadata.Difficulty != models.DifficultyHard || metadata.DifficultySource != models.DifficultySourceEditor {
+		t.Errorf("difficulty = %s from %s, want Hard from the editor", metadata.Difficulty, metadata.DifficultySource)
+	}
+
+	if err := service.OverrideDifficulty(ctx, id, "Impossible"); !errors.Is(err, ErrInvalidDifficulty) {
+		t.Errorf("OverrideDifficulty(Impossible) = %v, want ErrInvalidDifficulty", err)
+	}
+	if err := service.OverrideDifficulty(ctx, primitive.NewObjectID(), models.DifficultyEasy); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("OverrideDifficulty(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestReclassifyDifficultyKeepsEditorLabels(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService(t,
+		models.Fact{Content: hardText, Metadata: models.FactMetadata{Difficulty: models.DifficultyEasy}},
+		models.Fact{Content: hardText, Metadata: models.FactMetadata{Difficulty: models.DifficultyEasy, DifficultySource: models.DifficultySourceEditor}},
+		models.Fact{Content: "Cats purr.", Metadata: models.FactMetadata{Difficulty: models.DifficultyEasy}},
+	)
+
+	changed, err := service.ReclassifyDifficulty(ctx, processors.DefaultDifficultyThresholds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 1 {
+		t.Errorf("ReclassifyDifficulty() changed %d facts, want 1", changed)
+	}
+
+	facts, _ := repo.Find(ctx, storage.FactFilter{}, storage.FindOptions{})
+	if facts[0].Metadata.Difficulty == models.DifficultyEasy || facts[0].Metadata.DifficultySource != models.DifficultySourceComputed {
+		t.Errorf("computed fact = %s from %s, want it reclassified", facts[0].Metadata.Difficulty, facts[0].Metadata.DifficultySource)
+	}
+	if facts[1].Metadata.Difficulty != models.DifficultyEasy {
+		t.Errorf("editor labeled fact = %s, want its label kept", facts[1].Metadata.Difficulty)
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+)
+
+// ErrEmptyExplainRequest is returned when neither text nor a title is given
+var ErrEmptyExplainRequest = errors.New("either text or a Wikipedia title is required")
+
+// ExplainRequest is the input of a processor dry run: raw text, or the title
+// of a Wikipedia page to fetch
+type ExplainRequest struct {
+	Text     string `json:"text"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+}
+
+// ExplainFact runs the input through the collection pipeline and reports how
+// each rule and stage handled it. Nothing is stored.
+func (s *FactService) ExplainFact(ctx context.Context, req ExplainRequest) (*processors.Explanation, error) {
+	raw, err := explainInput(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, err
+	}
+
+	processor, err := sched.BuildProcessor(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("building processor: %w", err)
+	}
+
+	return processor.Explain(ctx, *raw), nil
+}
+
+func explainInput(ctx context.Context, req ExplainRequest) (*collectors.RawFact, error) {
+	if title := strings.TrimSpace(req.Title); title != "" {
+		return collectors.NewWikipediaSource().GetPage(ctx, title, req.Category)
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, ErrEmptyExplainRequest
+	}
+
+	return &collectors.RawFact{
+		Content:  text,
+		Source:   "Manual",
+		Category: req.Category,
+		Metadata: map[string]string{
+			"language": processors.DefaultLanguage,
+		},
+		CollectedAt: time.Now(),
+	}, nil
+}
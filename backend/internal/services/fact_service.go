@@ -3,210 +3,331 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"hash/fnv"
 	"log"
-	"math/rand"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/ZigaoWang/one-fact-app/backend/internal/calendar"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
 	"github.com/ZigaoWang/one-fact-app/backend/internal/scheduler"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type FactService struct {
-	db    *database.Database
-	cache *database.Cache
+	db        *database.Database
+	cache     database.Cache
+	facts     storage.FactRepository
+	chats     storage.ChatRepository
+	keywords  keywordStats
+	scheduler *scheduler.Scheduler
+	calendar  *calendar.Calendar
+	archive   *calendar.Archive
+	location  *time.Location // Time zone of clients that send none
+	serving   sync.Mutex     // Held while counting a daily fact serve without a calendar
 }
 
-func NewFactService(db *database.Database, cache *database.Cache) *FactService {
+// ErrNeedsMongoDB is returned by features whose data is only kept in
+// MongoDB, such as the publishing calendar, collection runs and processor
+// settings, when facts are kept in other storage
+var ErrNeedsMongoDB = errors.New("this feature needs MongoDB storage")
+
+// keywordStats are the corpus statistics used to extract the keywords of
+// facts added through the API
+type keywordStats interface {
+	processors.DocumentFrequencies
+	Reset(ctx context.Context, frequencies *processors.MemoryDocumentFrequencies) error
+}
+
+func NewFactService(db *database.Database, cache database.Cache) *FactService {
+	s := NewStorageFactService(storage.NewMongoFacts(db), storage.NewMongoChats(db), cache)
+	s.db = db
+	s.keywords = processors.NewMongoDocumentFrequencies(db.GetCollection("keyword_stats"))
+	s.calendar = calendar.New(db)
+	s.archive = calendar.NewArchive(db)
+	return s
+}
+
+// NewStorageFactService creates a fact service keeping facts and chat
+// sessions in the given repositories. Without MongoDB there is no publishing
+// calendar, so the daily fact is chosen from the verified facts by date, and
+// the calendar, archive, collection and processor settings are unavailable.
+func NewStorageFactService(facts storage.FactRepository, chats storage.ChatRepository, cache database.Cache) *FactService {
 	return &FactService{
-		db:    db,
-		cache: cache,
-	}
-}
-
-func (s *FactService) GetDailyFact(ctx context.Context, category string, isTest bool) (*models.Fact, error) {
-    // Try to get from cache first (only if not in test mode and cache is available)
-    if !isTest && s.cache != nil {
-        if fact, err := s.cache.GetDailyFact(ctx); err == nil && fact != nil {
-            if fact.Category == category {
-                return fact, nil
-            }
-        }
-    }
-
-	// Get a random fact that hasn't been served recently for the specific category
-	collection := s.db.GetCollection("facts")
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.M{
-			"verified": true,
-			"category": category,
-			"$or": []bson.M{
-				{"metadata.last_served": bson.M{"$exists": false}},
-				{"metadata.last_served": bson.M{
-					"$lt": time.Now().Add(-24 * time.Hour),
-				}},
-			},
-		}}},
-	}
-
-	// If in test mode, skip the last_served check
+		cache:    cache,
+		facts:    facts,
+		chats:    chats,
+		keywords: processors.NewMemoryDocumentFrequencies(),
+		location: time.Local,
+	}
+}
+
+// needsMongoDB returns ErrNeedsMongoDB unless the service runs on MongoDB
+func (s *FactService) needsMongoDB() error {
+	if s.db == nil {
+		return ErrNeedsMongoDB
+	}
+	return nil
+}
+
+// SetScheduler makes manual collections use the server's scheduler so that
+// they run with the same pipeline configuration
+func (s *FactService) SetScheduler(scheduler *scheduler.Scheduler) {
+	s.scheduler = scheduler
+}
+
+// DailyFact is the daily fact of a category for a local day, with the time
+// it rotates to the next day's fact
+type DailyFact struct {
+	*models.Fact
+	Date         string    `json:"date"`
+	Timezone     string    `json:"timezone"`
+	NextRotation time.Time `json:"next_rotation"`
+}
+
+// SetDefaultTimezone sets the time zone days are reckoned in for clients that
+// do not send theirs
+func (s *FactService) SetDefaultTimezone(loc *time.Location) {
+	s.location = loc
+}
+
+// GetDailyFact returns the fact the publishing calendar schedules for the
+// category on the current date in loc, or in the default time zone when loc
+// is nil. It is cached until local midnight, so every user in the time zone
+// gets the same fact for the day, and counted as served once per date and
+// category. In test mode a random top scored fact is returned instead,
+// without being scheduled or counted as served.
+func (s *FactService) GetDailyFact(ctx context.Context, category string, loc *time.Location, isTest bool) (*DailyFact, error) {
+	if loc == nil {
+		loc = s.location
+	}
+	now := time.Now().In(loc)
+	date, ends := calendar.Day(now)
+	daily := &DailyFact{Date: date, Timezone: loc.String(), NextRotation: ends}
+
 	if isTest {
-		pipeline = mongo.Pipeline{
-			{{Key: "$match", Value: bson.M{
-				"verified": true,
-				"category": category,
-			}}},
+		fact, err := s.PickFact(ctx, category)
+		if err != nil {
+			return nil, err
 		}
+		daily.Fact = fact
+		return daily, nil
 	}
 
-	// Add random sampling
-	pipeline = append(pipeline, bson.D{{Key: "$sample", Value: bson.M{"size": 1}}})
+	key := database.DailyFactKey{Date: date, Category: category, Timezone: loc.String()}
+
+	// Try to get from cache first (only if cache is available)
+	if s.cache != nil {
+		if fact, err := s.cache.GetDailyFact(ctx, key); err == nil && fact != nil {
+			daily.Fact = fact
+			return daily, nil
+		}
+	}
 
-	cursor, err := collection.Aggregate(ctx, pipeline)
+	fact, err := s.scheduledFact(ctx, date, category)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	var facts []models.Fact
-	if err := cursor.All(ctx, &facts); err != nil {
+	if err := s.countServe(ctx, date, category, fact.ID, now); err != nil {
 		return nil, err
 	}
 
-	if len(facts) == 0 {
-		return nil, errors.New("no facts available for category: " + category)
+	// Cache the fact until the day ends
+	if s.cache != nil {
+		if err := s.cache.SetDailyFact(ctx, key, fact, ends); err != nil {
+			log.Printf("Error caching daily fact: %v", err)
+		}
 	}
 
-	fact := &facts[0]
+	daily.Fact = fact
+	return daily, nil
+}
 
-	// Update last served time and increment serve count (only if not in test mode)
-	if !isTest {
-		update := bson.M{
-			"$set": bson.M{
-				"metadata.last_served": time.Now(),
-			},
-			"$inc": bson.M{
-				"metadata.serve_count": 1,
-			},
-		}
+// countServe updates the last served time and serve count of the daily fact
+// of a category on a date, unless it was counted for the date already. Each
+// time zone caches the fact separately, so it is read once per time zone and
+// again after the cache is lost. With a calendar the serve is marked on the
+// slot and archived; without one the fact records the date it was counted for.
+func (s *FactService) countServe(ctx context.Context, date, category string, id primitive.ObjectID, now time.Time) error {
+	update := storage.FactUpdate{
+		Set: map[string]interface{}{"metadata.last_served": now},
+		Inc: map[string]int{"metadata.serve_count": 1},
+	}
 
-		if _, err := collection.UpdateByID(ctx, fact.ID, update); err != nil {
-			return nil, err
+	if s.calendar != nil {
+		first, err := s.calendar.MarkServed(ctx, date, category, id)
+		if err != nil || !first {
+			return err
 		}
-
-		// Cache the fact
-		if err := s.cache.SetDailyFact(ctx, fact); err != nil {
-			// Log error but don't fail the request
-			_ = err
+		if err := s.archive.Record(ctx, date, category, id); err != nil {
+			log.Printf("Error archiving daily fact: %v", err)
 		}
+		return s.facts.Update(ctx, id, update)
 	}
 
-	return fact, nil
+	s.serving.Lock()
+	defer s.serving.Unlock()
+	fact, err := s.facts.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if fact.Metadata.ServedDate == date {
+		return nil
+	}
+	update.Set["metadata.served_date"] = date
+	return s.facts.Update(ctx, id, update)
 }
 
-func (s *FactService) GetRandomFact(ctx context.Context) (*models.Fact, error) {
-	collection := s.db.GetCollection("facts")
-	
-	// Get total count of facts
-	count, err := collection.CountDocuments(ctx, bson.M{"verified": true})
+// scheduledFact returns the fact the publishing calendar schedules for a
+// category on a date. Without a calendar a verified fact of the category is
+// chosen by hashing the date, so every request on the date gets the same one
+// while the facts do not change.
+func (s *FactService) scheduledFact(ctx context.Context, date, category string) (*models.Fact, error) {
+	if s.calendar != nil {
+		return s.calendar.Fact(ctx, date, category)
+	}
+
+	verified := true
+	facts, err := s.facts.Find(ctx, storage.FactFilter{Verified: &verified, Category: category}, storage.FindOptions{})
 	if err != nil {
 		return nil, err
 	}
-	
-	if count == 0 {
-		return nil, errors.New("no facts available")
+	if len(facts) == 0 {
+		return nil, fmt.Errorf("%w for category: %s", calendar.ErrNoFacts, category)
 	}
-	
-	// Get a random skip value
-	skip := rand.Int63n(count)
-	
-	// Find one random document
-	var fact models.Fact
-	err = collection.FindOne(ctx, bson.M{"verified": true}, options.FindOne().SetSkip(skip)).Decode(&fact)
+
+	sort.Slice(facts, func(i, j int) bool { return facts[i].ID.Hex() < facts[j].ID.Hex() })
+	hash := fnv.New32a()
+	hash.Write([]byte(date + "/" + category))
+	return &facts[hash.Sum32()%uint32(len(facts))], nil
+}
+
+// PickFact returns a random fact among the best scored verified facts of a
+// category, without scheduling it or counting it as served. Without a
+// calendar any verified fact of the category may be picked.
+func (s *FactService) PickFact(ctx context.Context, category string) (*models.Fact, error) {
+	if s.calendar != nil {
+		return s.calendar.Pick(ctx, category)
+	}
+
+	verified := true
+	facts, err := s.facts.Sample(ctx, storage.FactFilter{Verified: &verified, Category: category}, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(facts) == 0 {
+		return nil, fmt.Errorf("%w for category: %s", calendar.ErrNoFacts, category)
+	}
+	return &facts[0], nil
+}
+
+func (s *FactService) GetRandomFact(ctx context.Context) (*models.Fact, error) {
+	verified := true
+	facts, err := s.facts.Sample(ctx, storage.FactFilter{Verified: &verified}, 1)
 	if err != nil {
 		return nil, err
 	}
 	
-	return &fact, nil
+	if len(facts) == 0 {
+		return nil, errors.New("no facts available")
+	}
+	
+	return &facts[0], nil
 }
 
 func (s *FactService) SearchFacts(ctx context.Context, query models.FactQuery) ([]models.Fact, error) {
-	collection := s.db.GetCollection("facts")
-	filter := bson.M{"verified": true}
+	verified := true
+	filter := storage.FactFilter{
+		Verified:        &verified,
+		CategoryPattern: query.Category,
+		Search:          query.SearchTerm,
+		Difficulty:      query.Difficulty,
+		Language:        query.Language,
+	}
 
 	log.Printf("Received search query: %+v\n", query)
 
-	if query.Category != "" {
-		filter["category"] = bson.M{"$regex": primitive.Regex{Pattern: query.Category, Options: "i"}}
+	if len(query.Tags) > 0 && query.Tags[0] != "" {
+		filter.Tags = query.Tags
 	}
 
-	if len(query.Tags) > 0 && query.Tags[0] != "" {
-		filter["tags"] = bson.M{"$in": query.Tags}
+	facts, err := s.facts.Find(ctx, filter, storage.FindOptions{Limit: query.Limit, Offset: query.Offset})
+	if err != nil {
+		log.Printf("Error finding facts: %v\n", err)
+		return nil, err
 	}
 
-	if query.SearchTerm != "" {
-		filter["$or"] = []bson.M{
-			{"content": bson.M{"$regex": query.SearchTerm, "$options": "i"}},
-			{"category": bson.M{"$regex": query.SearchTerm, "$options": "i"}},
-			{"tags": bson.M{"$regex": query.SearchTerm, "$options": "i"}},
-			{"metadata.keywords": bson.M{"$regex": query.SearchTerm, "$options": "i"}},
+	log.Printf("Found %d facts\n", len(facts))
+	return facts, nil
+}
+
+// normalizeFact fills in the fields every stored fact is expected to have so
+// that facts written by the API match those written by the pipeline
+func normalizeFact(fact *models.Fact) {
+	fact.SchemaVersion = models.CurrentFactSchemaVersion
+	fact.SetContentHash()
+
+	now := time.Now()
+	if fact.CreatedAt.IsZero() {
+		if !fact.ID.IsZero() {
+			fact.CreatedAt = fact.ID.Timestamp()
+		} else {
+			fact.CreatedAt = now
 		}
 	}
-
-	if query.Difficulty != "" {
-		filter["metadata.difficulty"] = query.Difficulty
+	if fact.UpdatedAt.IsZero() {
+		fact.UpdatedAt = fact.CreatedAt
 	}
 
-	if query.Language != "" {
-		filter["metadata.language"] = query.Language
+	if fact.Metadata.Language == "" {
+		fact.Metadata.Language = processors.DefaultLanguage
 	}
-
-	findOptions := options.Find()
-	if query.Limit > 0 {
-		findOptions.SetLimit(int64(query.Limit))
+	if fact.Tags == nil {
+		fact.Tags = []string{}
 	}
-	if query.Offset > 0 {
-		findOptions.SetSkip(int64(query.Offset))
+	if fact.RelatedURLs == nil {
+		fact.RelatedURLs = []string{}
 	}
-
-	log.Printf("Final filter: %+v\n", filter)
-	cursor, err := collection.Find(ctx, filter, findOptions)
-	if err != nil {
-		log.Printf("Error finding facts: %v\n", err)
-		return nil, err
+	if fact.Metadata.References == nil {
+		fact.Metadata.References = []string{}
 	}
-	defer cursor.Close(ctx)
-
-	var facts []models.Fact
-	if err := cursor.All(ctx, &facts); err != nil {
-		log.Printf("Error decoding facts: %v\n", err)
-		return nil, err
+	if fact.Metadata.Keywords == nil {
+		fact.Metadata.Keywords = []string{}
 	}
-
-	log.Printf("Found %d facts\n", len(facts))
-	return facts, nil
 }
 
 func (s *FactService) AddFact(ctx context.Context, fact *models.Fact) error {
-	collection := s.db.GetCollection("facts")
-	_, err := collection.InsertOne(ctx, fact)
-	return err
+	normalizeFact(fact)
+	s.indexKeywords(ctx, fact)
+	return s.facts.Insert(ctx, fact)
 }
 
 func (s *FactService) UpdateFact(ctx context.Context, fact *models.Fact) error {
-	collection := s.db.GetCollection("facts")
-	_, err := collection.ReplaceOne(ctx, bson.M{"_id": fact.ID}, fact)
-	return err
+	if existing, err := s.facts.Get(ctx, fact.ID); err == nil {
+		s.unindexKeywords(ctx, existing)
+		// Kept by the server rather than sent by clients
+		fact.Metadata.ServedDate = existing.Metadata.ServedDate
+		defer s.forgetScheduledFact(ctx, existing)
+	}
+	normalizeFact(fact)
+	fact.UpdatedAt = time.Now()
+	s.indexKeywords(ctx, fact)
+
+	return s.facts.Replace(ctx, fact)
 }
 
 func (s *FactService) DeleteFact(ctx context.Context, id primitive.ObjectID) error {
-	collection := s.db.GetCollection("facts")
-	_, err := collection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+	if existing, err := s.facts.Get(ctx, id); err == nil {
+		s.unindexKeywords(ctx, existing)
+		defer s.forgetScheduledFact(ctx, existing)
+	}
+
+	return s.facts.Delete(ctx, id)
 }
 
 // GetFactByID retrieves a fact by its string ID
@@ -217,18 +338,5 @@ func (s *FactService) GetFactByID(ctx context.Context, id string) (*models.Fact,
 		return nil, err
 	}
 	
-	collection := s.db.GetCollection("facts")
-	var fact models.Fact
-	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&fact)
-	if err != nil {
-		return nil, err
-	}
-	
-	return &fact, nil
+	return s.facts.Get(ctx, objID)
 }
-
-func (s *FactService) CollectFacts(ctx context.Context) error {
-    collection := s.db.GetCollection("facts")
-    scheduler := scheduler.NewScheduler(collection)
-    return scheduler.CollectFacts(ctx)
-}
\ No newline at end of file
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/calendar"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestService creates a fact service over in-memory storage and cache,
+// holding the given facts
+func newTestService(t *testing.T, facts ...models.Fact) (*FactService, *storage.MemoryFacts) {
+	t.Helper()
+	repo := storage.NewMemoryFacts()
+	for i := range facts {
+		if err := repo.Insert(context.Background(), &facts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewStorageFactService(repo, storage.NewMemoryChats(), database.NewMemoryCache(100)), repo
+}
+
+func getFact(t *testing.T, repo storage.FactRepository, id primitive.ObjectID) *models.Fact {
+	t.Helper()
+	fact, err := repo.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fact
+}
+
+func TestGetDailyFact(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService(t,
+		models.Fact{Content: "Venus spins backwards", Category: "Space", Verified: true},
+		models.Fact{Content: "Mars has two moons", Category: "Space", Verified: true},
+		models.Fact{Content: "Unverified", Category: "Space"},
+		models.Fact{Content: "Rome was founded", Category: "History", Verified: true},
+	)
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	daily, err := service.GetDailyFact(ctx, "Space", tokyo, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if daily.Category != "Space" || !daily.Verified {
+		t.Fatalf("GetDailyFact() = %+v, want a verified Space fact", daily.Fact)
+	}
+	if today, ends := calendar.Day(time.Now().In(tokyo)); daily.Date != today || !daily.NextRotation.Equal(ends) {
+		t.Errorf("date = %s rotating at %v, want %s rotating at %v", daily.Date, daily.NextRotation, today, ends)
+	}
+	if daily.Timezone != "Asia/Tokyo" {
+		t.Errorf("timezone = %q, want Asia/Tokyo", daily.Timezone)
+	}
+	if fact := getFact(t, repo, daily.ID); fact.Metadata.ServeCount != 1 || fact.Metadata.LastServed.IsZero() {
+		t.Errorf("serve count = %d, last served %v, want the serve recorded", fact.Metadata.ServeCount, fact.Metadata.LastServed)
+	}
+
+	// The cached fact is served for the rest of the day without counting again
+	again, err := service.GetDailyFact(ctx, "Space", tokyo, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != daily.ID {
+		t.Errorf("second request got %s, want the same fact %s", again.ID.Hex(), daily.ID.Hex())
+	}
+	if fact := getFact(t, repo, daily.ID); fact.Metadata.ServeCount != 1 {
+		t.Errorf("serve count = %d after a cached request, want 1", fact.Metadata.ServeCount)
+	}
+
+	// Test mode neither caches nor counts
+	test, err := service.GetDailyFact(ctx, "History", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fact := getFact(t, repo, test.ID); fact.Metadata.ServeCount != 0 {
+		t.Errorf("test mode counted a serve")
+	}
+
+	if _, err := service.GetDailyFact(ctx, "Sports", nil, false); !errors.Is(err, calendar.ErrNoFacts) {
+		t.Errorf("GetDailyFact(no facts) = %v, want ErrNoFacts", err)
+	}
+}
+
+func TestGetDailyFactCountsOncePerDay(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService(t, models.Fact{Content: "Venus spins backwards", Category: "Space", Verified: true})
+
+	// Two time zones on the same date each read the fact once, and a lost
+	// cache reads it again, but the serve is counted once
+	first, second := time.FixedZone("First", 0), time.FixedZone("Second", 0)
+	daily, err := service.GetDailyFact(ctx, "Space", first, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.GetDailyFact(ctx, "Space", second, false); err != nil {
+		t.Fatal(err)
+	}
+	service.cache = database.NewMemoryCache(100)
+	if _, err := service.GetDailyFact(ctx, "Space", first, false); err != nil {
+		t.Fatal(err)
+	}
+	if fact := getFact(t, repo, daily.ID); fact.Metadata.ServeCount != 1 {
+		t.Errorf("serve count = %d, want 1 for the day", fact.Metadata.ServeCount)
+	}
+
+	// The next day counts again
+	if err := service.countServe(ctx, calendar.AddDays(daily.Date, 1), "Space", daily.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if fact := getFact(t, repo, daily.ID); fact.Metadata.ServeCount != 2 {
+		t.Errorf("serve count = %d, want 2 after the next day", fact.Metadata.ServeCount)
+	}
+}
+
+func TestScheduledFactIsStablePerDate(t *testing.T) {
+	ctx := context.Background()
+	var facts []models.Fact
+	for _, content := range []string{"a", "b", "c", "d", "e", "f"} {
+		facts = append(facts, models.Fact{Content: content, Category: "Science", Verified: true})
+	}
+	service, _ := newTestService(t, facts...)
+
+	seen := make(map[primitive.ObjectID]bool)
+	for day := 1; day <= 20; day++ {
+		date := time.Date(2024, 6, day, 0, 0, 0, 0, time.UTC).Format(calendar.DateLayout)
+		first, err := service.scheduledFact(ctx, date, "Science")
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, _ := service.scheduledFact(ctx, date, "Science")
+		if first.ID != second.ID {
+			t.Fatalf("%s: got %s then %s, want the same fact", date, first.Content, second.Content)
+		}
+		seen[first.ID] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("20 days served %d distinct facts, want the fact to rotate", len(seen))
+	}
+}
+
+func TestPickAndRandomFact(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t,
+		models.Fact{Content: "Verified", Category: "Arts", Verified: true},
+		models.Fact{Content: "Unverified", Category: "Arts"},
+	)
+
+	for i := 0; i < 10; i++ {
+		fact, err := service.PickFact(ctx, "Arts")
+		if err != nil || fact.Content != "Verified" {
+			t.Fatalf("PickFact() = %v, %v, want the verified fact", fact, err)
+		}
+		if fact, err := service.GetRandomFact(ctx); err != nil || fact.Content != "Verified" {
+			t.Fatalf("GetRandomFact() = %v, %v, want the verified fact", fact, err)
+		}
+	}
+	if _, err := service.PickFact(ctx, "Sports"); !errors.Is(err, calendar.ErrNoFacts) {
+		t.Errorf("PickFact(no facts) = %v, want ErrNoFacts", err)
+	}
+
+	empty, _ := newTestService(t)
+	if _, err := empty.GetRandomFact(ctx); err == nil {
+		t.Error("GetRandomFact() without facts succeeded, want an error")
+	}
+}
+
+func TestSearchFacts(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t,
+		models.Fact{Content: "Octopuses have three hearts", Category: "Nature", Verified: true, Tags: []string{"animals"},
+			Metadata: models.FactMetadata{Difficulty: models.DifficultyEasy, Language: "en"}},
+		models.Fact{Content: "Honey never spoils", Category: "Nature", Verified: true,
+			Metadata: models.FactMetadata{Difficulty: models.DifficultyMedium, Language: "en"}},
+		models.Fact{Content: "Bananas are berries", Category: "Nature"},
+		models.Fact{Content: "The Moon drifts away", Category: "Space", Verified: true,
+			Metadata: models.FactMetadata{Keywords: []string{"moon"}, Language: "fr"}},
+	)
+
+	tests := []struct {
+		name  string
+		query models.FactQuery
+		want  int
+	}{
+		{"verified only", models.FactQuery{}, 3},
+		{"category", models.FactQuery{Category: "nat"}, 2},
+		{"search term", models.FactQuery{SearchTerm: "hearts"}, 1},
+		{"search keywords", models.FactQuery{SearchTerm: "MOON"}, 1},
+		{"tag", models.FactQuery{Tags: []string{"animals"}}, 1},
+		{"empty tag", models.FactQuery{Tags: []string{""}}, 3},
+		{"difficulty", models.FactQuery{Difficulty: models.DifficultyMedium}, 1},
+		{"language", models.FactQuery{Language: "fr"}, 1},
+		{"limit", models.FactQuery{Limit: 2}, 2},
+		{"offset", models.FactQuery{Offset: 2}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts, err := service.SearchFacts(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(facts) != tt.want {
+				t.Errorf("SearchFacts(%+v) found %d facts, want %d", tt.query, len(facts), tt.want)
+			}
+		})
+	}
+}
+
+func TestFactCRUD(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService(t)
+
+	fact := &models.Fact{Content: "Sharks are older than trees", Category: "Nature", Verified: true}
+	if err := service.AddFact(ctx, fact); err != nil {
+		t.Fatal(err)
+	}
+	if fact.ID.IsZero() || fact.CreatedAt.IsZero() || fact.SchemaVersion != models.CurrentFactSchemaVersion {
+		t.Fatalf("AddFact() = %+v, want an ID, creation time and schema version", fact)
+	}
+	if len(fact.Metadata.Keywords) == 0 {
+		t.Error("AddFact() extracted no keywords")
+	}
+
+	got, err := service.GetFactByID(ctx, fact.ID.Hex())
+	if err != nil || got.Content != fact.Content {
+		t.Fatalf("GetFactByID() = %v, %v, want the added fact", got, err)
+	}
+	if _, err := service.GetFactByID(ctx, "not-an-id"); err == nil {
+		t.Error("GetFactByID(invalid) succeeded, want an error")
+	}
+
+	update := &models.Fact{ID: fact.ID, Content: "Sharks predate trees", Category: "Nature", Verified: true}
+	if err := service.UpdateFact(ctx, update); err != nil {
+		t.Fatal(err)
+	}
+	if stored := getFact(t, repo, fact.ID); stored.Content != "Sharks predate trees" || stored.UpdatedAt.IsZero() {
+		t.Errorf("UpdateFact() stored %+v", stored)
+	}
+
+	if err := service.DeleteFact(ctx, fact.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.GetFactByID(ctx, fact.ID.Hex()); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetFactByID() after DeleteFact() = %v, want ErrNotFound", err)
+	}
+}
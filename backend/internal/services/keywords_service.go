@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+)
+
+// factLanguage returns the language used for keyword extraction
+func factLanguage(fact *models.Fact) string {
+	if fact.Metadata.Language != "" {
+		return fact.Metadata.Language
+	}
+	return processors.DefaultLanguage
+}
+
+// KeywordStats returns the corpus statistics keywords are extracted with, so
+// a scheduler collecting into the same storage can keep them up to date
+func (s *FactService) KeywordStats() processors.DocumentFrequencies {
+	return s.keywords
+}
+
+// indexKeywords fills in missing keywords and records the fact in the corpus
+// statistics. Errors are logged since keywords are not essential to a fact.
+func (s *FactService) indexKeywords(ctx context.Context, fact *models.Fact) {
+	language := factLanguage(fact)
+
+	if len(fact.Metadata.Keywords) == 0 {
+		keywords, err := processors.ExtractKeywords(ctx, s.keywords, fact.Content, language, processors.DefaultKeywordLimit)
+		if err != nil {
+			log.Printf("Error extracting keywords: %v", err)
+		}
+		for _, k := range keywords {
+			fact.Metadata.Keywords = append(fact.Metadata.Keywords, k.Term)
+		}
+	}
+
+	if err := s.keywords.Add(ctx, language, processors.Terms(fact.Content, language)); err != nil {
+		log.Printf("Error updating keyword statistics: %v", err)
+	}
+}
+
+// unindexKeywords removes a fact from the corpus statistics
+func (s *FactService) unindexKeywords(ctx context.Context, fact *models.Fact) {
+	language := factLanguage(fact)
+	if err := s.keywords.Remove(ctx, language, processors.Terms(fact.Content, language)); err != nil {
+		log.Printf("Error updating keyword statistics: %v", err)
+	}
+}
+
+// BackfillKeywords rebuilds the corpus statistics from every stored fact and
+// recomputes their keywords. If onlyMissing is set, facts that already have
+// keywords keep them. It returns the number of facts updated.
+func (s *FactService) BackfillKeywords(ctx context.Context, onlyMissing bool) (int, error) {
+	// First pass: count document frequencies over the whole corpus
+	frequencies := processors.NewMemoryDocumentFrequencies()
+	err := s.facts.Each(ctx, storage.FactFilter{}, func(fact *models.Fact) error {
+		language := factLanguage(fact)
+		return frequencies.Add(ctx, language, processors.Terms(fact.Content, language))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.keywords.Reset(ctx, frequencies); err != nil {
+		return 0, err
+	}
+
+	// Second pass: recompute keywords against the fresh statistics
+	updated := 0
+	err = s.facts.Each(ctx, storage.FactFilter{MissingKeywords: onlyMissing}, func(fact *models.Fact) error {
+		keywords, err := processors.ExtractKeywords(ctx, frequencies, fact.Content, factLanguage(fact), processors.DefaultKeywordLimit)
+		if err != nil {
+			return err
+		}
+
+		terms := make([]string, 0, len(keywords))
+		for _, k := range keywords {
+			terms = append(terms, k.Term)
+		}
+
+		if err := s.facts.Update(ctx, fact.ID, storage.FactUpdate{
+			Set: map[string]interface{}{"metadata.keywords": terms},
+		}); err != nil {
+			return err
+		}
+		updated++
+		return nil
+	})
+
+	return updated, err
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+)
+
+func TestBackfillKeywords(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService(t,
+		models.Fact{Content: "Octopuses have three hearts and blue blood"},
+		models.Fact{Content: "Honey found in ancient tombs is still edible", Metadata: models.FactMetadata{Keywords: []string{"kept"}}},
+	)
+
+	updated, err := service.BackfillKeywords(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != 1 {
+		t.Errorf("BackfillKeywords(only missing) updated %d facts, want 1", updated)
+	}
+	facts, _ := repo.Find(ctx, storage.FactFilter{}, storage.FindOptions{})
+	if len(facts[0].Metadata.Keywords) == 0 {
+		t.Error("fact without keywords still has none")
+	}
+	if len(facts[1].Metadata.Keywords) != 1 || facts[1].Metadata.Keywords[0] != "kept" {
+		t.Errorf("keywords = %v, want the existing ones kept", facts[1].Metadata.Keywords)
+	}
+
+	if updated, _ := service.BackfillKeywords(ctx, false); updated != 2 {
+		t.Errorf("BackfillKeywords(all) updated %d facts, want 2", updated)
+	}
+	if total, _, _ := service.keywords.Lookup(ctx, processors.DefaultLanguage, nil); total != 2 {
+		t.Errorf("corpus holds %d documents, want 2", total)
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MigrationResult summarizes a fact schema migration
+type MigrationResult struct {
+	Scanned         int `json:"scanned"`
+	Migrated        int `json:"migrated"`
+	Failed          int `json:"failed"`
+	MissingKeywords int `json:"missing_keywords"`
+}
+
+// MigrateFacts rewrites every fact stored with an older schema into the
+// current models.Fact shape. This covers facts written by the collection
+// pipeline before it shared the API's schema, whose metadata only held a
+// title. Missing difficulties are computed; missing keywords are left for the
+// backfill-keywords command, which needs the whole corpus. With dryRun set
+// nothing is written.
+func (s *FactService) MigrateFacts(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	collection := s.db.GetCollection("facts")
+
+	thresholds, err := processors.LoadDifficultyThresholds(ctx, s.db.GetCollection("processor_settings"))
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := collection.Find(ctx, bson.M{"$or": []bson.M{
+		{"schema_version": bson.M{"$exists": false}},
+		{"schema_version": bson.M{"$lt": models.CurrentFactSchemaVersion}},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := &MigrationResult{}
+	for cursor.Next(ctx) {
+		result.Scanned++
+
+		var fact models.Fact
+		if err := cursor.Decode(&fact); err != nil {
+			log.Printf("Error decoding fact %v: %v", cursor.Current.Lookup("_id"), err)
+			result.Failed++
+			continue
+		}
+
+		normalizeFact(&fact)
+		if fact.Metadata.Difficulty == "" {
+			fact.Metadata.Difficulty = thresholds.Classify(processors.MeasureReadability(fact.Content))
+			fact.Metadata.DifficultySource = models.DifficultySourceComputed
+		}
+		if len(fact.Metadata.Keywords) == 0 {
+			result.MissingKeywords++
+		}
+
+		if dryRun {
+			result.Migrated++
+			continue
+		}
+
+		if _, err := collection.ReplaceOne(ctx, bson.M{"_id": fact.ID}, fact); err != nil {
+			log.Printf("Error migrating fact %s: %v", fact.ID.Hex(), err)
+			result.Failed++
+			continue
+		}
+		result.Migrated++
+	}
+
+	return result, cursor.Err()
+}
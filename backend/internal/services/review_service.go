@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewDecision is an editor's decision on a fact held for review
+type ReviewDecision struct {
+	Approve  bool   `json:"approve"`
+	Category string `json:"category,omitempty"`
+}
+
+// RetrainClassifier trains the category classifier on every verified fact and
+// saves it for the processor to use on its next run
+func (s *FactService) RetrainClassifier(ctx context.Context) (*processors.CategoryModel, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	verified, needsReview := true, false
+	var docs []processors.CategoryDocument
+	err := s.facts.Each(ctx, storage.FactFilter{Verified: &verified, NeedsReview: &needsReview}, func(fact *models.Fact) error {
+		docs = append(docs, processors.CategoryDocument{
+			Content:  fact.Content,
+			Tags:     fact.Tags,
+			Category: fact.Category,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := processors.TrainCategoryModel(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := processors.SaveCategoryModel(ctx, s.db.GetCollection("processor_settings"), model); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// GetReviewQueue returns the facts held for review, oldest first
+func (s *FactService) GetReviewQueue(ctx context.Context, limit int) ([]models.Fact, error) {
+	needsReview := true
+	return s.facts.Find(ctx, storage.FactFilter{NeedsReview: &needsReview}, storage.FindOptions{
+		Sort:  storage.SortOldest,
+		Limit: limit,
+	})
+}
+
+// ResolveReview applies an editor's decision to a fact held for review.
+// Approved facts become verified, optionally with a corrected category, and
+// rejected facts are deleted. An approval is recorded as the fact's
+// verification alongside any automated checks.
+func (s *FactService) ResolveReview(ctx context.Context, id primitive.ObjectID, decision ReviewDecision) error {
+	if !decision.Approve {
+		return s.DeleteFact(ctx, id)
+	}
+
+	now := time.Now()
+	set := map[string]interface{}{
+		"verified":                true,
+		"needs_review":            false,
+		"verification.status":     models.VerificationApproved,
+		"verification.method":     models.VerificationMethodEditor,
+		"verification.checked_at": now,
+		"updated_at":              now,
+	}
+	if decision.Category != "" {
+		set["category"] = decision.Category
+	}
+
+	return s.facts.Update(ctx, id, storage.FactUpdate{
+		Set:   set,
+		Unset: []string{"review_reasons"},
+	})
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReviewQueue(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	service, repo := newTestService(t,
+		models.Fact{Content: "newer", NeedsReview: true, CreatedAt: base.Add(time.Hour), ReviewReasons: []string{"uncertain category"}},
+		models.Fact{Content: "older", NeedsReview: true, CreatedAt: base},
+		models.Fact{Content: "served", Verified: true, CreatedAt: base},
+	)
+
+	queue, err := service.GetReviewQueue(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queue) != 2 || queue[0].Content != "older" || queue[1].Content != "newer" {
+		t.Fatalf("GetReviewQueue() = %q, want the held facts oldest first", contents(queue))
+	}
+	if limited, _ := service.GetReviewQueue(ctx, 1); len(limited) != 1 {
+		t.Errorf("GetReviewQueue(1) returned %d facts", len(limited))
+	}
+
+	// Approving verifies the fact with the corrected category
+	approved := queue[1].ID
+	if err := service.ResolveReview(ctx, approved, ReviewDecision{Approve: true, Category: "Science"}); err != nil {
+		t.Fatal(err)
+	}
+	fact := getFact(t, repo, approved)
+	switch {
+	case !fact.Verified || fact.NeedsReview:
+		t.Errorf("approved fact verified = %v, needs review = %v", fact.Verified, fact.NeedsReview)
+	case fact.Category != "Science":
+		t.Errorf("category = %q, want the corrected Science", fact.Category)
+	case len(fact.ReviewReasons) != 0:
+		t.Errorf("review reasons = %v, want them cleared", fact.ReviewReasons)
+	case fact.Verification == nil || fact.Verification.Status != models.VerificationApproved:
+		t.Errorf("verification = %+v, want an editor approval", fact.Verification)
+	}
+
+	// Rejecting deletes the fact
+	rejected := queue[0].ID
+	if err := service.ResolveReview(ctx, rejected, ReviewDecision{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Get(ctx, rejected); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("rejected fact still stored: %v", err)
+	}
+
+	if err := service.ResolveReview(ctx, primitive.NewObjectID(), ReviewDecision{Approve: true}); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("ResolveReview(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func contents(facts []models.Fact) []string {
+	var out []string
+	for _, fact := range facts {
+		out = append(out, fact.Content)
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scheduler"
+)
+
+// ScheduleUpdate is an editor's change to the schedule of a source
+type ScheduleUpdate struct {
+	Cron    string `json:"cron"`
+	CatchUp string `json:"catch_up"`
+}
+
+// sourceScheduler returns the server's scheduler, or one over the database
+// for commands run outside the server
+func (s *FactService) sourceScheduler() (*scheduler.Scheduler, error) {
+	if s.scheduler != nil {
+		return s.scheduler, nil
+	}
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	return scheduler.NewScheduler(s.db), nil
+}
+
+// GetSourceSchedules returns the collection schedule of every source
+func (s *FactService) GetSourceSchedules(ctx context.Context) ([]scheduler.SourceSchedule, error) {
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, err
+	}
+	return sched.Schedules(ctx)
+}
+
+// UpdateSourceSchedule changes the cron expression and catch-up policy of a source
+func (s *FactService) UpdateSourceSchedule(ctx context.Context, source string, update ScheduleUpdate) (*scheduler.SourceSchedule, error) {
+	if update.CatchUp == "" {
+		update.CatchUp = scheduler.CatchUpOnce
+	}
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, err
+	}
+	return sched.UpdateSchedule(ctx, source, update.Cron, update.CatchUp)
+}
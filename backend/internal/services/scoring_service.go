@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/scoring"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrUnknownEngagementEvent is returned for an unsupported engagement event
+var ErrUnknownEngagementEvent = errors.New("unknown engagement event")
+
+// ErrNoScoringModel is returned when scores are explained before a model
+// has been trained
+var ErrNoScoringModel = errors.New("no scoring model has been trained")
+
+// engagementFields maps engagement events to the counters they increment
+var engagementFields = map[string]string{
+	models.EngagementLike:     "metadata.popularity",
+	models.EngagementChatOpen: "metadata.chat_opens",
+	models.EngagementShare:    "metadata.shares",
+}
+
+// RecordEngagement counts a user engagement event against a fact
+func (s *FactService) RecordEngagement(ctx context.Context, id primitive.ObjectID, event string) error {
+	field, ok := engagementFields[event]
+	if !ok {
+		return ErrUnknownEngagementEvent
+	}
+
+	return s.facts.Update(ctx, id, storage.FactUpdate{Inc: map[string]int{field: 1}})
+}
+
+// RetrainScoring retrains the scoring model from engagement and rescores
+// every stored fact, returning the model and the number of changed scores
+func (s *FactService) RetrainScoring(ctx context.Context) (*scoring.Model, int, error) {
+	sched, err := s.sourceScheduler()
+	if err != nil {
+		return nil, 0, err
+	}
+	return sched.RescoreFacts(ctx)
+}
+
+// GetScoreBreakdown explains a fact's learned score feature by feature
+func (s *FactService) GetScoreBreakdown(ctx context.Context, id primitive.ObjectID) (*scoring.Breakdown, error) {
+	if err := s.needsMongoDB(); err != nil {
+		return nil, err
+	}
+	model, err := scoring.LoadModel(ctx, s.db.GetCollection("processor_settings"))
+	if err != nil {
+		return nil, err
+	}
+	if model == nil {
+		return nil, ErrNoScoringModel
+	}
+
+	fact, err := s.facts.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.Explain(fact), nil
+}
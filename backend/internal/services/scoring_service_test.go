@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRecordEngagement(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService(t, models.Fact{Content: "fact", Verified: true})
+	facts, _ := repo.Find(ctx, storage.FactFilter{}, storage.FindOptions{})
+	id := facts[0].ID
+
+	for _, event := range []string{models.EngagementLike, models.EngagementLike, models.EngagementChatOpen, models.EngagementShare} {
+		if err := service.RecordEngagement(ctx, id, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	metadata := getFact(t, repo, id).Metadata
+	if metadata.Popularity != 2 || metadata.ChatOpens != 1 || metadata.Shares != 1 {
+		t.Errorf("likes, chat opens, shares = %d, %d, %d, want 2, 1, 1", metadata.Popularity, metadata.ChatOpens, metadata.Shares)
+	}
+
+	if err := service.RecordEngagement(ctx, id, "stare"); !errors.Is(err, ErrUnknownEngagementEvent) {
+		t.Errorf("RecordEngagement(unknown event) = %v, want ErrUnknownEngagementEvent", err)
+	}
+	if err := service.RecordEngagement(ctx, primitive.NewObjectID(), models.EngagementLike); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("RecordEngagement(missing fact) = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/storage"
+)
+
+// GetTagTaxonomy returns the tag taxonomy used by the processor. Without
+// MongoDB it is always the default one.
+func (s *FactService) GetTagTaxonomy(ctx context.Context) (*processors.TagTaxonomy, error) {
+	if s.db == nil {
+		return processors.DefaultTagTaxonomy(), nil
+	}
+	return processors.LoadTagTaxonomy(ctx, s.db.GetCollection("processor_settings"))
+}
+
+// UpdateTagTaxonomy validates and saves a new tag taxonomy. It applies to
+// facts collected from then on; stored facts are remapped by RetagFacts.
+func (s *FactService) UpdateTagTaxonomy(ctx context.Context, taxonomy *processors.TagTaxonomy) error {
+	if err := s.needsMongoDB(); err != nil {
+		return err
+	}
+	if _, err := processors.NewTagMapper(taxonomy); err != nil {
+		return err
+	}
+
+	taxonomy.UpdatedAt = time.Now()
+	return processors.SaveTagTaxonomy(ctx, s.db.GetCollection("processor_settings"), taxonomy)
+}
+
+// RetagFacts maps the tags of every stored fact through the current taxonomy
+// and returns how many facts changed
+func (s *FactService) RetagFacts(ctx context.Context) (int, error) {
+	taxonomy, err := s.GetTagTaxonomy(ctx)
+	if err != nil {
+		return 0, err
+	}
+	mapper, err := processors.NewTagMapper(taxonomy)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	err = s.facts.Each(ctx, storage.FactFilter{}, func(fact *models.Fact) error {
+		tags := mapper.Map(fact.Tags)
+		if equalTags(tags, fact.Tags) {
+			return nil
+		}
+
+		if err := s.facts.Update(ctx, fact.ID, storage.FactUpdate{
+			Set: map[string]interface{}{"tags": tags, "updated_at": time.Now()},
+		}); err != nil {
+			return err
+		}
+		updated++
+		return nil
+	})
+
+	return updated, err
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
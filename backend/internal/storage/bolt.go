@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Storage backends selectable with STORAGE_BACKEND
+const (
+	BackendMongo = "mongo"
+	BackendBolt  = "bolt"
+)
+
+// Buckets of the data file. Keys are the 12 bytes of an ObjectID, which sort
+// by creation time, and values are BSON documents as stored in MongoDB.
+var (
+	factsBucket    = []byte("facts")
+	sessionsBucket = []byte("chat_sessions")
+)
+
+// openTimeout is how long opening waits for another process to release the
+// data file
+const openTimeout = 5 * time.Second
+
+// BoltStore keeps facts and chat sessions in a single bbolt data file, so the
+// server can run without MongoDB. Filters are applied by scanning, which
+// suits the few thousand facts of a self-hosted or demo deployment.
+type BoltStore struct {
+	db    *bbolt.DB
+	facts *BoltFacts
+	chats *BoltChats
+}
+
+// OpenBolt opens the data file at path, creating it if needed. Only one
+// process can have it open at a time.
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{factsBucket, sessionsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("preparing %s: %w", path, err)
+	}
+
+	return &BoltStore{db: db, facts: &BoltFacts{db: db}, chats: &BoltChats{db: db}}, nil
+}
+
+// Facts returns the repository of the facts in the data file
+func (b *BoltStore) Facts() *BoltFacts {
+	return b.facts
+}
+
+// Chats returns the repository of the chat sessions in the data file
+func (b *BoltStore) Chats() *BoltChats {
+	return b.chats
+}
+
+// Close closes the data file
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
+
+// BoltFacts is a FactRepository in a bbolt data file
+type BoltFacts struct {
+	db *bbolt.DB
+}
+
+func (b *BoltFacts) Insert(ctx context.Context, fact *models.Fact) error {
+	if fact.ID.IsZero() {
+		fact.ID = primitive.NewObjectID()
+	}
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(factsBucket)
+		if bucket.Get(fact.ID[:]) != nil {
+			return fmt.Errorf("fact %s already exists", fact.ID.Hex())
+		}
+		return putDocument(bucket, fact.ID, fact)
+	})
+}
+
+func (b *BoltFacts) Get(ctx context.Context, id primitive.ObjectID) (*models.Fact, error) {
+	var fact models.Fact
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		return getDocument(tx.Bucket(factsBucket), id, &fact)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &fact, nil
+}
+
+func (b *BoltFacts) Replace(ctx context.Context, fact *models.Fact) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(factsBucket)
+		if bucket.Get(fact.ID[:]) == nil {
+			return nil
+		}
+		return putDocument(bucket, fact.ID, fact)
+	})
+}
+
+func (b *BoltFacts) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(factsBucket).Delete(id[:])
+	})
+}
+
+func (b *BoltFacts) Update(ctx context.Context, id primitive.ObjectID, update FactUpdate) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(factsBucket)
+		var fact models.Fact
+		if err := getDocument(bucket, id, &fact); err != nil {
+			return err
+		}
+		updated, err := applyUpdate(&fact, update)
+		if err != nil {
+			return err
+		}
+		return putDocument(bucket, id, updated)
+	})
+}
+
+func (b *BoltFacts) Find(ctx context.Context, filter FactFilter, opts FindOptions) ([]models.Fact, error) {
+	facts, err := b.matching(filter)
+	if err != nil {
+		return nil, err
+	}
+	return page(facts, opts), nil
+}
+
+// Each reads the matching facts before calling fn, so fn may update them
+func (b *BoltFacts) Each(ctx context.Context, filter FactFilter, fn func(*models.Fact) error) error {
+	facts, err := b.matching(filter)
+	if err != nil {
+		return err
+	}
+	for i := range facts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(&facts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *BoltFacts) Sample(ctx context.Context, filter FactFilter, n int) ([]models.Fact, error) {
+	facts, err := b.matching(filter)
+	if err != nil {
+		return nil, err
+	}
+	return sample(facts, n), nil
+}
+
+// matching returns the facts matching the filter, oldest ID first
+func (b *BoltFacts) matching(filter FactFilter) ([]models.Fact, error) {
+	match, err := newMatcher(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	facts := []models.Fact{}
+	err = b.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(factsBucket).ForEach(func(key, value []byte) error {
+			var fact models.Fact
+			if err := bson.Unmarshal(value, &fact); err != nil {
+				return fmt.Errorf("reading fact %x: %w", key, err)
+			}
+			if match(&fact) {
+				facts = append(facts, fact)
+			}
+			return nil
+		})
+	})
+	return facts, err
+}
+
+// BoltChats is a ChatRepository in a bbolt data file
+type BoltChats struct {
+	db *bbolt.DB
+}
+
+func (b *BoltChats) SaveSession(ctx context.Context, session *models.ChatSession) error {
+	prepareSession(session)
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return putDocument(tx.Bucket(sessionsBucket), session.ID, session)
+	})
+}
+
+func (b *BoltChats) GetSession(ctx context.Context, id primitive.ObjectID) (*models.ChatSession, error) {
+	var session models.ChatSession
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		return getDocument(tx.Bucket(sessionsBucket), id, &session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (b *BoltChats) ListSessions(ctx context.Context, filter ChatFilter) ([]models.ChatSession, error) {
+	sessions := []models.ChatSession{}
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(key, value []byte) error {
+			var session models.ChatSession
+			if err := bson.Unmarshal(value, &session); err != nil {
+				return fmt.Errorf("reading chat session %x: %w", key, err)
+			}
+			if matchSession(&session, filter) {
+				sessions = append(sessions, session)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortSessions(sessions, filter.Limit), nil
+}
+
+// getDocument decodes the document stored under id, or returns ErrNotFound
+func getDocument(bucket *bbolt.Bucket, id primitive.ObjectID, v interface{}) error {
+	data := bucket.Get(id[:])
+	if data == nil {
+		return ErrNotFound
+	}
+	return bson.Unmarshal(data, v)
+}
+
+// putDocument stores v under id as a BSON document
+func putDocument(bucket *bbolt.Bucket, id primitive.ObjectID, v interface{}) error {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(id[:], data)
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+)
+
+// CopyFacts copies every fact from one repository to another, replacing
+// facts with the same ID, and returns how many were copied. Facts that
+// duplicate another fact already copied or stored are skipped and counted:
+// MongoDB refuses two facts with the same source and title, or the same
+// content without a title, but other repositories may hold them.
+func CopyFacts(ctx context.Context, to, from FactRepository) (copied, skipped int, err error) {
+	err = from.Each(ctx, FactFilter{}, func(fact *models.Fact) error {
+		fact.SetContentHash()
+		duplicate, err := hasDuplicate(ctx, to, fact)
+		if err != nil {
+			return err
+		}
+		if duplicate {
+			skipped++
+			return nil
+		}
+
+		_, err = to.Get(ctx, fact.ID)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			err = to.Insert(ctx, fact)
+		case err == nil:
+			err = to.Replace(ctx, fact)
+		}
+		if err != nil {
+			return err
+		}
+		copied++
+		return nil
+	})
+	return copied, skipped, err
+}
+
+// hasDuplicate reports whether the repository holds a fact other than this
+// one with the same source and title, or the same content when neither has a
+// title
+func hasDuplicate(ctx context.Context, facts FactRepository, fact *models.Fact) (bool, error) {
+	filter := FactFilter{Content: fact.Content}
+	if fact.Metadata.Title != "" {
+		filter = FactFilter{Source: fact.Source, Title: fact.Metadata.Title}
+	}
+	found, err := facts.Find(ctx, filter, FindOptions{})
+	if err != nil {
+		return false, err
+	}
+	for _, other := range found {
+		if other.ID != fact.ID && other.Metadata.Title == fact.Metadata.Title {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CopyChats copies every chat session from one repository to another,
+// replacing sessions with the same ID, and returns how many were copied
+func CopyChats(ctx context.Context, to, from ChatRepository) (int, error) {
+	sessions, err := from.ListSessions(ctx, ChatFilter{})
+	if err != nil {
+		return 0, err
+	}
+	for i := range sessions {
+		if err := to.SaveSession(ctx, &sessions[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(sessions), nil
+}
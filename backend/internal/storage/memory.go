@@ -0,0 +1,409 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryFacts is a FactRepository kept in memory. Facts are copied in and
+// out, so callers cannot change stored facts without going through it.
+type MemoryFacts struct {
+	mutex sync.RWMutex
+	facts []models.Fact // In insertion order
+}
+
+// NewMemoryFacts creates an empty in-memory fact repository
+func NewMemoryFacts() *MemoryFacts {
+	return &MemoryFacts{}
+}
+
+func (m *MemoryFacts) Insert(ctx context.Context, fact *models.Fact) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if fact.ID.IsZero() {
+		fact.ID = primitive.NewObjectID()
+	} else if m.index(fact.ID) >= 0 {
+		return fmt.Errorf("fact %s already exists", fact.ID.Hex())
+	}
+	stored, err := copyFact(fact)
+	if err != nil {
+		return err
+	}
+	m.facts = append(m.facts, *stored)
+	return nil
+}
+
+func (m *MemoryFacts) Get(ctx context.Context, id primitive.ObjectID) (*models.Fact, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	i := m.index(id)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	return copyFact(&m.facts[i])
+}
+
+func (m *MemoryFacts) Replace(ctx context.Context, fact *models.Fact) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i := m.index(fact.ID)
+	if i < 0 {
+		return nil
+	}
+	stored, err := copyFact(fact)
+	if err != nil {
+		return err
+	}
+	m.facts[i] = *stored
+	return nil
+}
+
+func (m *MemoryFacts) Delete(ctx context.Context, id primitive.ObjectID) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if i := m.index(id); i >= 0 {
+		m.facts = append(m.facts[:i], m.facts[i+1:]...)
+	}
+	return nil
+}
+
+func (m *MemoryFacts) Update(ctx context.Context, id primitive.ObjectID, update FactUpdate) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i := m.index(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	updated, err := applyUpdate(&m.facts[i], update)
+	if err != nil {
+		return err
+	}
+	m.facts[i] = *updated
+	return nil
+}
+
+func (m *MemoryFacts) Find(ctx context.Context, filter FactFilter, opts FindOptions) ([]models.Fact, error) {
+	facts, err := m.matching(filter)
+	if err != nil {
+		return nil, err
+	}
+	return page(facts, opts), nil
+}
+
+func (m *MemoryFacts) Each(ctx context.Context, filter FactFilter, fn func(*models.Fact) error) error {
+	facts, err := m.matching(filter)
+	if err != nil {
+		return err
+	}
+	for i := range facts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(&facts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryFacts) Sample(ctx context.Context, filter FactFilter, n int) ([]models.Fact, error) {
+	facts, err := m.matching(filter)
+	if err != nil {
+		return nil, err
+	}
+	return sample(facts, n), nil
+}
+
+// index returns the position of a fact, or -1
+func (m *MemoryFacts) index(id primitive.ObjectID) int {
+	for i := range m.facts {
+		if m.facts[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// matching returns copies of the facts matching the filter
+func (m *MemoryFacts) matching(filter FactFilter) ([]models.Fact, error) {
+	match, err := newMatcher(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	facts := []models.Fact{}
+	for i := range m.facts {
+		if !match(&m.facts[i]) {
+			continue
+		}
+		fact, err := copyFact(&m.facts[i])
+		if err != nil {
+			return nil, err
+		}
+		facts = append(facts, *fact)
+	}
+	return facts, nil
+}
+
+// page orders and pages facts in storage order
+func page(facts []models.Fact, opts FindOptions) []models.Fact {
+	if opts.Sort == SortOldest {
+		sort.SliceStable(facts, func(i, j int) bool { return facts[i].CreatedAt.Before(facts[j].CreatedAt) })
+	}
+	if opts.Offset > 0 {
+		if opts.Offset >= len(facts) {
+			return []models.Fact{}
+		}
+		facts = facts[opts.Offset:]
+	}
+	if opts.Limit > 0 && opts.Limit < len(facts) {
+		facts = facts[:opts.Limit]
+	}
+	return facts
+}
+
+// sample returns up to n of the facts in random order
+func sample(facts []models.Fact, n int) []models.Fact {
+	rand.Shuffle(len(facts), func(i, j int) { facts[i], facts[j] = facts[j], facts[i] })
+	if n < len(facts) {
+		facts = facts[:n]
+	}
+	return facts
+}
+
+// newMatcher returns a function reporting whether a fact matches the filter
+func newMatcher(filter FactFilter) (func(*models.Fact) bool, error) {
+	var categoryPattern, search *regexp.Regexp
+	var err error
+	if filter.CategoryPattern != "" {
+		if categoryPattern, err = regexp.Compile("(?i)" + filter.CategoryPattern); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Search != "" {
+		if search, err = regexp.Compile("(?i)" + filter.Search); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(fact *models.Fact) bool {
+		switch {
+		case filter.Verified != nil && fact.Verified != *filter.Verified:
+			return false
+		case filter.NeedsReview != nil && fact.NeedsReview != *filter.NeedsReview:
+			return false
+		case filter.Category != "" && fact.Category != filter.Category:
+			return false
+		case filter.Category == "" && categoryPattern != nil && !categoryPattern.MatchString(fact.Category):
+			return false
+		case filter.Source != "" && fact.Source != filter.Source:
+			return false
+		case filter.Title != "" && fact.Metadata.Title != filter.Title:
+			return false
+		case filter.Content != "" && fact.Content != filter.Content:
+			return false
+		case len(filter.Tags) > 0 && !anyMatch(fact.Tags, func(tag string) bool { return contains(filter.Tags, tag) }):
+			return false
+		case filter.Difficulty != "" && fact.Metadata.Difficulty != filter.Difficulty:
+			return false
+		case filter.Language != "" && fact.Metadata.Language != filter.Language:
+			return false
+		case filter.EditorLabeled != nil && (fact.Metadata.DifficultySource == models.DifficultySourceEditor) != *filter.EditorLabeled:
+			return false
+		case filter.MissingKeywords && len(fact.Metadata.Keywords) > 0:
+			return false
+		}
+		if search != nil {
+			return search.MatchString(fact.Content) ||
+				search.MatchString(fact.Category) ||
+				anyMatch(fact.Tags, search.MatchString) ||
+				anyMatch(fact.Metadata.Keywords, search.MatchString)
+		}
+		return true
+	}, nil
+}
+
+func anyMatch(values []string, match func(string) bool) bool {
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	return anyMatch(values, func(v string) bool { return v == value })
+}
+
+// copyFact deep-copies a fact through its document form
+func copyFact(fact *models.Fact) (*models.Fact, error) {
+	data, err := bson.Marshal(fact)
+	if err != nil {
+		return nil, err
+	}
+	var copied models.Fact
+	if err := bson.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
+
+// applyUpdate returns a copy of a fact with an update applied to its
+// document form, the way MongoDB applies it
+func applyUpdate(fact *models.Fact, update FactUpdate) (*models.Fact, error) {
+	data, err := bson.Marshal(fact)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(data))
+	if err != nil {
+		return nil, err
+	}
+	decoder.DefaultDocumentM()
+	var doc bson.M
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	for path, value := range update.Set {
+		parent, field := documentField(doc, path)
+		parent[field] = value
+	}
+	for path, n := range update.Inc {
+		parent, field := documentField(doc, path)
+		current, err := toInt64(parent[field])
+		if err != nil {
+			return nil, fmt.Errorf("incrementing %s: %w", path, err)
+		}
+		parent[field] = current + int64(n)
+	}
+	for _, path := range update.Unset {
+		parent, field := documentField(doc, path)
+		delete(parent, field)
+	}
+
+	if data, err = bson.Marshal(doc); err != nil {
+		return nil, err
+	}
+	var updated models.Fact
+	if err := bson.Unmarshal(data, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// documentField returns the document holding the last element of a dotted
+// path, creating the documents on the way, and that element's name
+func documentField(doc bson.M, path string) (bson.M, string) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := doc[part].(bson.M)
+		if !ok {
+			child = bson.M{}
+			doc[part] = child
+		}
+		doc = child
+	}
+	return doc, parts[len(parts)-1]
+}
+
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("not an integer: %T", value)
+	}
+}
+
+// MemoryChats is a ChatRepository kept in memory
+type MemoryChats struct {
+	mutex    sync.RWMutex
+	sessions map[primitive.ObjectID]models.ChatSession
+}
+
+// NewMemoryChats creates an empty in-memory chat repository
+func NewMemoryChats() *MemoryChats {
+	return &MemoryChats{sessions: make(map[primitive.ObjectID]models.ChatSession)}
+}
+
+func (m *MemoryChats) SaveSession(ctx context.Context, session *models.ChatSession) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	prepareSession(session)
+	m.sessions[session.ID] = copySession(session)
+	return nil
+}
+
+func (m *MemoryChats) GetSession(ctx context.Context, id primitive.ObjectID) (*models.ChatSession, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	session = copySession(&session)
+	return &session, nil
+}
+
+func (m *MemoryChats) ListSessions(ctx context.Context, filter ChatFilter) ([]models.ChatSession, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	sessions := []models.ChatSession{}
+	for _, session := range m.sessions {
+		if matchSession(&session, filter) {
+			sessions = append(sessions, copySession(&session))
+		}
+	}
+	return sortSessions(sessions, filter.Limit), nil
+}
+
+// matchSession reports whether a session matches the filter
+func matchSession(session *models.ChatSession, filter ChatFilter) bool {
+	return (filter.FactID == "" || session.FactID == filter.FactID) &&
+		(filter.UserID == "" || session.UserID == filter.UserID)
+}
+
+// sortSessions orders sessions most recently updated first and keeps at most
+// limit of them, or all when limit is not positive
+func sortSessions(sessions []models.ChatSession, limit int) []models.ChatSession {
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt) })
+	if limit > 0 && limit < len(sessions) {
+		sessions = sessions[:limit]
+	}
+	return sessions
+}
+
+func copySession(session *models.ChatSession) models.ChatSession {
+	copied := *session
+	copied.Messages = append([]models.Message{}, session.Messages...)
+	return copied
+}
@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/database"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoFacts is a FactRepository over the facts collection
+type MongoFacts struct {
+	collection *mongo.Collection
+}
+
+// NewMongoFacts creates a repository of the facts collection
+func NewMongoFacts(db *database.Database) *MongoFacts {
+	return &MongoFacts{collection: db.GetCollection("facts")}
+}
+
+func (m *MongoFacts) Insert(ctx context.Context, fact *models.Fact) error {
+	if fact.ID.IsZero() {
+		fact.ID = primitive.NewObjectID()
+	}
+	_, err := m.collection.InsertOne(ctx, fact)
+	return err
+}
+
+func (m *MongoFacts) Get(ctx context.Context, id primitive.ObjectID) (*models.Fact, error) {
+	var fact models.Fact
+	if err := m.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&fact); err != nil {
+		return nil, err
+	}
+	return &fact, nil
+}
+
+func (m *MongoFacts) Replace(ctx context.Context, fact *models.Fact) error {
+	_, err := m.collection.ReplaceOne(ctx, bson.M{"_id": fact.ID}, fact)
+	return err
+}
+
+func (m *MongoFacts) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := m.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (m *MongoFacts) Update(ctx context.Context, id primitive.ObjectID, update FactUpdate) error {
+	doc := bson.M{}
+	if len(update.Set) > 0 {
+		doc["$set"] = bson.M(update.Set)
+	}
+	if len(update.Inc) > 0 {
+		inc := bson.M{}
+		for field, n := range update.Inc {
+			inc[field] = n
+		}
+		doc["$inc"] = inc
+	}
+	if len(update.Unset) > 0 {
+		unset := bson.M{}
+		for _, field := range update.Unset {
+			unset[field] = ""
+		}
+		doc["$unset"] = unset
+	}
+
+	result, err := m.collection.UpdateByID(ctx, id, doc)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *MongoFacts) Find(ctx context.Context, filter FactFilter, opts FindOptions) ([]models.Fact, error) {
+	findOptions := options.Find()
+	if opts.Sort == SortOldest {
+		findOptions.SetSort(bson.M{"created_at": 1})
+	}
+	if opts.Limit > 0 {
+		findOptions.SetLimit(int64(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		findOptions.SetSkip(int64(opts.Offset))
+	}
+
+	cursor, err := m.collection.Find(ctx, factQuery(filter), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	facts := []models.Fact{}
+	if err := cursor.All(ctx, &facts); err != nil {
+		return nil, err
+	}
+	return facts, nil
+}
+
+func (m *MongoFacts) Each(ctx context.Context, filter FactFilter, fn func(*models.Fact) error) error {
+	cursor, err := m.collection.Find(ctx, factQuery(filter))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var fact models.Fact
+		if err := cursor.Decode(&fact); err != nil {
+			return err
+		}
+		if err := fn(&fact); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (m *MongoFacts) Sample(ctx context.Context, filter FactFilter, n int) ([]models.Fact, error) {
+	cursor, err := m.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: factQuery(filter)}},
+		{{Key: "$sample", Value: bson.M{"size": n}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	facts := []models.Fact{}
+	if err := cursor.All(ctx, &facts); err != nil {
+		return nil, err
+	}
+	return facts, nil
+}
+
+// factQuery translates a filter into a MongoDB query
+func factQuery(filter FactFilter) bson.M {
+	query := bson.M{}
+	if filter.Verified != nil {
+		query["verified"] = *filter.Verified
+	}
+	if filter.NeedsReview != nil {
+		if *filter.NeedsReview {
+			query["needs_review"] = true
+		} else {
+			query["needs_review"] = bson.M{"$ne": true}
+		}
+	}
+	switch {
+	case filter.Category != "":
+		query["category"] = filter.Category
+	case filter.CategoryPattern != "":
+		query["category"] = bson.M{"$regex": primitive.Regex{Pattern: filter.CategoryPattern, Options: "i"}}
+	}
+	if filter.Source != "" {
+		query["source"] = filter.Source
+	}
+	if filter.Title != "" {
+		query["metadata.title"] = filter.Title
+	}
+	if filter.Content != "" {
+		query["content"] = filter.Content
+	}
+	if len(filter.Tags) > 0 {
+		query["tags"] = bson.M{"$in": filter.Tags}
+	}
+	if filter.Search != "" {
+		query["$or"] = []bson.M{
+			{"content": bson.M{"$regex": filter.Search, "$options": "i"}},
+			{"category": bson.M{"$regex": filter.Search, "$options": "i"}},
+			{"tags": bson.M{"$regex": filter.Search, "$options": "i"}},
+			{"metadata.keywords": bson.M{"$regex": filter.Search, "$options": "i"}},
+		}
+	}
+	if filter.Difficulty != "" {
+		query["metadata.difficulty"] = filter.Difficulty
+	}
+	if filter.Language != "" {
+		query["metadata.language"] = filter.Language
+	}
+	if filter.EditorLabeled != nil {
+		if *filter.EditorLabeled {
+			query["metadata.difficulty_source"] = models.DifficultySourceEditor
+		} else {
+			query["metadata.difficulty_source"] = bson.M{"$ne": models.DifficultySourceEditor}
+		}
+	}
+	if filter.MissingKeywords {
+		query["metadata.keywords"] = bson.M{"$in": bson.A{nil, bson.A{}}}
+	}
+	return query
+}
+
+// MongoChats is a ChatRepository over the chat_sessions collection
+type MongoChats struct {
+	collection *mongo.Collection
+}
+
+// NewMongoChats creates a repository of the chat_sessions collection
+func NewMongoChats(db *database.Database) *MongoChats {
+	return &MongoChats{collection: db.GetCollection("chat_sessions")}
+}
+
+func (m *MongoChats) SaveSession(ctx context.Context, session *models.ChatSession) error {
+	prepareSession(session)
+	_, err := m.collection.ReplaceOne(ctx, bson.M{"_id": session.ID}, session, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (m *MongoChats) GetSession(ctx context.Context, id primitive.ObjectID) (*models.ChatSession, error) {
+	var session models.ChatSession
+	if err := m.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (m *MongoChats) ListSessions(ctx context.Context, filter ChatFilter) ([]models.ChatSession, error) {
+	query := bson.M{}
+	if filter.FactID != "" {
+		query["fact_id"] = filter.FactID
+	}
+	if filter.UserID != "" {
+		query["user_id"] = filter.UserID
+	}
+	findOptions := options.Find().SetSort(bson.M{"updated_at": -1})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := m.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []models.ChatSession{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
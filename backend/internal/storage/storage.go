@@ -0,0 +1,115 @@
+// Package storage defines where facts and chat sessions are kept, with a
+// MongoDB implementation for production, an embedded one in a bbolt data
+// file for single-binary deployments and an in-memory one for tests and
+// development.
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNotFound is returned when a fact or chat session does not exist. It is
+// the driver's error, so callers can test for either.
+var ErrNotFound = mongo.ErrNoDocuments
+
+// FactFilter selects facts. Unset fields match every fact.
+type FactFilter struct {
+	Verified        *bool
+	NeedsReview     *bool  // False also matches facts without the field
+	Category        string // Exact category
+	Source          string
+	Title           string   // Title of the source page
+	Content         string   // Exact content
+	CategoryPattern string   // Case-insensitive regular expression
+	Tags            []string // Any of the tags
+	Search          string   // Case-insensitive regular expression over content, category, tags and keywords
+	Difficulty      string
+	Language        string
+	EditorLabeled   *bool // Difficulty set by an editor
+	MissingKeywords bool
+}
+
+// FactSort orders the facts returned by Find
+type FactSort int
+
+const (
+	SortNatural FactSort = iota // Storage order
+	SortOldest                  // Created first
+)
+
+// FindOptions pages and orders the facts returned by Find
+type FindOptions struct {
+	Sort   FactSort
+	Limit  int
+	Offset int
+}
+
+// FactUpdate changes fields of a fact, named by their dotted document path
+// such as metadata.serve_count
+type FactUpdate struct {
+	Set   map[string]interface{}
+	Inc   map[string]int
+	Unset []string
+}
+
+// FactRepository stores facts
+type FactRepository interface {
+	// Insert stores a new fact, giving it an ID when it has none
+	Insert(ctx context.Context, fact *models.Fact) error
+	// Get returns a fact by ID, or ErrNotFound
+	Get(ctx context.Context, id primitive.ObjectID) (*models.Fact, error)
+	// Replace overwrites the stored fact with the same ID, if any
+	Replace(ctx context.Context, fact *models.Fact) error
+	// Delete removes a fact, if it exists
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// Update changes fields of a fact, or returns ErrNotFound
+	Update(ctx context.Context, id primitive.ObjectID, update FactUpdate) error
+	// Find returns the facts matching the filter
+	Find(ctx context.Context, filter FactFilter, opts FindOptions) ([]models.Fact, error)
+	// Each calls fn with every fact matching the filter, stopping at the
+	// first error
+	Each(ctx context.Context, filter FactFilter, fn func(*models.Fact) error) error
+	// Sample returns up to n random facts matching the filter
+	Sample(ctx context.Context, filter FactFilter, n int) ([]models.Fact, error)
+}
+
+// ChatFilter selects chat sessions. Unset fields match every session.
+type ChatFilter struct {
+	FactID string
+	UserID string
+	Limit  int
+}
+
+// ChatRepository stores chat sessions
+type ChatRepository interface {
+	// SaveSession stores a session, filling in the ID and timestamps it lacks
+	SaveSession(ctx context.Context, session *models.ChatSession) error
+	// GetSession returns a session by ID, or ErrNotFound
+	GetSession(ctx context.Context, id primitive.ObjectID) (*models.ChatSession, error)
+	// ListSessions returns the sessions matching the filter, most recently
+	// updated first
+	ListSessions(ctx context.Context, filter ChatFilter) ([]models.ChatSession, error)
+}
+
+// prepareSession fills in the ID and timestamps of a session being saved.
+// Sessions keep their update time, so copies between stores keep their order.
+func prepareSession(session *models.ChatSession) {
+	now := time.Now()
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	if session.UpdatedAt.IsZero() {
+		session.UpdatedAt = now
+	}
+	if session.Messages == nil {
+		session.Messages = []models.Message{}
+	}
+}
@@ -0,0 +1,340 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// forEachStore runs a test against empty repositories of each embedded kind
+func forEachStore(t *testing.T, test func(t *testing.T, facts FactRepository, chats ChatRepository)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryFacts(), NewMemoryChats())
+	})
+	t.Run("bolt", func(t *testing.T) {
+		store := openBolt(t, filepath.Join(t.TempDir(), "facts.db"))
+		test(t, store.Facts(), store.Chats())
+	})
+}
+
+func openBolt(t *testing.T, path string) *BoltStore {
+	t.Helper()
+	store, err := OpenBolt(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func seedFacts(t *testing.T, repo FactRepository, facts ...models.Fact) []models.Fact {
+	t.Helper()
+	for i := range facts {
+		if err := repo.Insert(context.Background(), &facts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return facts
+}
+
+func contents(facts []models.Fact) []string {
+	var out []string
+	for _, fact := range facts {
+		out = append(out, fact.Content)
+	}
+	return out
+}
+
+func TestFactsFind(t *testing.T) {
+	forEachStore(t, func(t *testing.T, repo FactRepository, _ ChatRepository) {
+		ctx := context.Background()
+		base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		seedFacts(t, repo,
+			models.Fact{Content: "Saturn has rings", Category: "Space", Source: "Wikipedia", Verified: true, Tags: []string{"planets"}, CreatedAt: base.Add(2 * time.Hour),
+				Metadata: models.FactMetadata{Title: "Saturn"}},
+			models.Fact{Content: "Rome was founded", Category: "History", Verified: true, NeedsReview: false, CreatedAt: base.Add(time.Hour),
+				Metadata: models.FactMetadata{Keywords: []string{"rome"}, Difficulty: models.DifficultyEasy, DifficultySource: models.DifficultySourceEditor}},
+			models.Fact{Content: "Unchecked claim", Category: "Space Science", NeedsReview: true, CreatedAt: base},
+		)
+
+		verified, notVerified, review := true, false, true
+		tests := []struct {
+			name   string
+			filter FactFilter
+			opts   FindOptions
+			want   []string
+		}{
+			{"all", FactFilter{}, FindOptions{}, []string{"Saturn has rings", "Rome was founded", "Unchecked claim"}},
+			{"verified", FactFilter{Verified: &verified}, FindOptions{}, []string{"Saturn has rings", "Rome was founded"}},
+			{"not verified", FactFilter{Verified: &notVerified}, FindOptions{}, []string{"Unchecked claim"}},
+			{"needs review", FactFilter{NeedsReview: &review}, FindOptions{}, []string{"Unchecked claim"}},
+			{"exact category", FactFilter{Category: "Space"}, FindOptions{}, []string{"Saturn has rings"}},
+			{"category pattern", FactFilter{CategoryPattern: "space"}, FindOptions{}, []string{"Saturn has rings", "Unchecked claim"}},
+			{"source and title", FactFilter{Source: "Wikipedia", Title: "Saturn"}, FindOptions{}, []string{"Saturn has rings"}},
+			{"other title", FactFilter{Source: "Wikipedia", Title: "Jupiter"}, FindOptions{}, nil},
+			{"exact content", FactFilter{Content: "Rome was founded"}, FindOptions{}, []string{"Rome was founded"}},
+			{"tags", FactFilter{Tags: []string{"moons", "planets"}}, FindOptions{}, []string{"Saturn has rings"}},
+			{"search content", FactFilter{Search: "RINGS"}, FindOptions{}, []string{"Saturn has rings"}},
+			{"search keywords", FactFilter{Search: "^rome$"}, FindOptions{}, []string{"Rome was founded"}},
+			{"difficulty", FactFilter{Difficulty: models.DifficultyEasy}, FindOptions{}, []string{"Rome was founded"}},
+			{"editor labeled", FactFilter{EditorLabeled: &verified}, FindOptions{}, []string{"Rome was founded"}},
+			{"missing keywords", FactFilter{MissingKeywords: true}, FindOptions{}, []string{"Saturn has rings", "Unchecked claim"}},
+			{"oldest first", FactFilter{}, FindOptions{Sort: SortOldest}, []string{"Unchecked claim", "Rome was founded", "Saturn has rings"}},
+			{"paged", FactFilter{}, FindOptions{Sort: SortOldest, Offset: 1, Limit: 1}, []string{"Rome was founded"}},
+			{"past the end", FactFilter{}, FindOptions{Offset: 5}, nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				facts, err := repo.Find(ctx, tt.filter, tt.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := contents(facts)
+				if len(got) != len(tt.want) {
+					t.Fatalf("Find() = %q, want %q", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Fatalf("Find() = %q, want %q", got, tt.want)
+					}
+				}
+			})
+		}
+
+		if _, err := repo.Find(ctx, FactFilter{Search: "("}, FindOptions{}); err == nil {
+			t.Error("Find() with an invalid pattern succeeded, want an error")
+		}
+	})
+}
+
+func TestFactsCopies(t *testing.T) {
+	forEachStore(t, func(t *testing.T, repo FactRepository, _ ChatRepository) {
+		ctx := context.Background()
+		facts := seedFacts(t, repo, models.Fact{Content: "original", Tags: []string{"a"}})
+		if facts[0].ID.IsZero() {
+			t.Fatal("Insert() left the ID empty")
+		}
+
+		facts[0].Tags[0] = "changed"
+		got, err := repo.Get(ctx, facts[0].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.Content = "changed"
+
+		again, _ := repo.Get(ctx, facts[0].ID)
+		if again.Content != "original" || again.Tags[0] != "a" {
+			t.Errorf("stored fact = %q %v, want it unchanged by callers", again.Content, again.Tags)
+		}
+
+		if err := repo.Insert(ctx, &facts[0]); err == nil {
+			t.Error("Insert() of an existing ID succeeded, want an error")
+		}
+
+		// Replacing a missing fact does not store it
+		missing := models.Fact{ID: primitive.NewObjectID(), Content: "missing"}
+		if err := repo.Replace(ctx, &missing); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.Get(ctx, missing.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() after Replace() of a missing fact = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestFactsUpdate(t *testing.T) {
+	forEachStore(t, func(t *testing.T, repo FactRepository, _ ChatRepository) {
+		ctx := context.Background()
+		facts := seedFacts(t, repo, models.Fact{
+			Content:       "fact",
+			ReviewReasons: []string{"low confidence"},
+			Metadata:      models.FactMetadata{ServeCount: 2},
+		})
+		id := facts[0].ID
+
+		served := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+		err := repo.Update(ctx, id, FactUpdate{
+			Set:   map[string]interface{}{"verified": true, "metadata.last_served": served, "tags": []string{"x"}},
+			Inc:   map[string]int{"metadata.serve_count": 1, "metadata.shares": 2},
+			Unset: []string{"review_reasons"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, _ := repo.Get(ctx, id)
+		switch {
+		case !got.Verified:
+			t.Error("verified was not set")
+		case !got.Metadata.LastServed.Equal(served):
+			t.Errorf("last_served = %v, want %v", got.Metadata.LastServed, served)
+		case got.Metadata.ServeCount != 3 || got.Metadata.Shares != 2:
+			t.Errorf("counters = %d serves, %d shares, want 3 and 2", got.Metadata.ServeCount, got.Metadata.Shares)
+		case len(got.ReviewReasons) != 0:
+			t.Errorf("review_reasons = %v, want it unset", got.ReviewReasons)
+		case len(got.Tags) != 1 || got.Tags[0] != "x":
+			t.Errorf("tags = %v, want [x]", got.Tags)
+		}
+
+		if err := repo.Update(ctx, primitive.NewObjectID(), FactUpdate{}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Update() of a missing fact = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestFactsSampleEachDelete(t *testing.T) {
+	forEachStore(t, func(t *testing.T, repo FactRepository, _ ChatRepository) {
+		ctx := context.Background()
+		facts := seedFacts(t, repo,
+			models.Fact{Content: "a", Verified: true},
+			models.Fact{Content: "b", Verified: true},
+			models.Fact{Content: "c"},
+		)
+
+		verified := true
+		sample, err := repo.Sample(ctx, FactFilter{Verified: &verified}, 5)
+		if err != nil || len(sample) != 2 {
+			t.Fatalf("Sample() = %q, %v, want both verified facts", contents(sample), err)
+		}
+		if sample, _ := repo.Sample(ctx, FactFilter{}, 1); len(sample) != 1 {
+			t.Errorf("Sample(1) returned %d facts", len(sample))
+		}
+
+		stop := errors.New("stop")
+		seen := 0
+		err = repo.Each(ctx, FactFilter{}, func(fact *models.Fact) error {
+			seen++
+			return stop
+		})
+		if !errors.Is(err, stop) || seen != 1 {
+			t.Errorf("Each() = %v after %d facts, want it to stop at the first error", err, seen)
+		}
+
+		// Facts can be updated while iterating
+		err = repo.Each(ctx, FactFilter{Verified: &verified}, func(fact *models.Fact) error {
+			return repo.Update(ctx, fact.ID, FactUpdate{Inc: map[string]int{"metadata.serve_count": 1}})
+		})
+		if err != nil {
+			t.Errorf("Each() updating facts = %v", err)
+		}
+
+		if err := repo.Delete(ctx, facts[1].ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.Get(ctx, facts[1].ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() after Delete() = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(ctx, facts[1].ID); err != nil {
+			t.Errorf("Delete() of a missing fact = %v, want nil", err)
+		}
+	})
+}
+
+func TestChats(t *testing.T) {
+	forEachStore(t, func(t *testing.T, _ FactRepository, repo ChatRepository) {
+		ctx := context.Background()
+
+		first := &models.ChatSession{FactID: "f1", Messages: []models.Message{{Role: "user", Content: "hi"}}}
+		if err := repo.SaveSession(ctx, first); err != nil {
+			t.Fatal(err)
+		}
+		if first.ID.IsZero() || first.CreatedAt.IsZero() || first.UpdatedAt.IsZero() {
+			t.Fatal("SaveSession() did not fill in the ID and timestamps")
+		}
+		second := &models.ChatSession{FactID: "f2"}
+		repo.SaveSession(ctx, second)
+
+		first.Messages = append(first.Messages, models.Message{Role: "assistant", Content: "hello"})
+		first.UpdatedAt = second.UpdatedAt.Add(time.Second)
+		repo.SaveSession(ctx, first)
+
+		got, err := repo.GetSession(ctx, first.ID)
+		if err != nil || len(got.Messages) != 2 {
+			t.Fatalf("GetSession() = %+v, %v, want two messages", got, err)
+		}
+		if _, err := repo.GetSession(ctx, primitive.NewObjectID()); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetSession() of a missing session = %v, want ErrNotFound", err)
+		}
+
+		sessions, _ := repo.ListSessions(ctx, ChatFilter{})
+		if len(sessions) != 2 || sessions[0].ID != first.ID {
+			t.Errorf("ListSessions() = %d sessions, want the last updated first", len(sessions))
+		}
+		sessions, _ = repo.ListSessions(ctx, ChatFilter{FactID: "f2"})
+		if len(sessions) != 1 || sessions[0].ID != second.ID {
+			t.Errorf("ListSessions(f2) = %+v, want the second session", sessions)
+		}
+		if sessions, _ := repo.ListSessions(ctx, ChatFilter{Limit: 1}); len(sessions) != 1 {
+			t.Errorf("ListSessions(limit 1) = %d sessions", len(sessions))
+		}
+	})
+}
+
+func TestBoltStorePersists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "facts.db")
+	store, err := OpenBolt(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	facts := seedFacts(t, store.Facts(), models.Fact{Content: "kept", Verified: true})
+	session := &models.ChatSession{FactID: facts[0].ID.Hex()}
+	if err := store.Chats().SaveSession(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := openBolt(t, path)
+	if fact, err := reopened.Facts().Get(ctx, facts[0].ID); err != nil || fact.Content != "kept" {
+		t.Errorf("Get() after reopening = %v, %v, want the stored fact", fact, err)
+	}
+	if _, err := reopened.Chats().GetSession(ctx, session.ID); err != nil {
+		t.Errorf("GetSession() after reopening = %v", err)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	from, fromChats := NewMemoryFacts(), NewMemoryChats()
+	facts := seedFacts(t, from,
+		models.Fact{Content: "first", Verified: true, Metadata: models.FactMetadata{ServeCount: 4}},
+		models.Fact{Content: "second"},
+		// Duplicates MongoDB would refuse
+		models.Fact{Content: "Paris", Source: "Wikipedia", Metadata: models.FactMetadata{Title: "Paris"}},
+		models.Fact{Content: "Paris again", Source: "Wikipedia", Metadata: models.FactMetadata{Title: "Paris"}},
+		models.Fact{Content: "second"},
+	)
+	updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	session := &models.ChatSession{FactID: facts[0].ID.Hex(), UpdatedAt: updated}
+	fromChats.SaveSession(ctx, session)
+
+	store := openBolt(t, filepath.Join(t.TempDir(), "facts.db"))
+	// A stale copy of the first fact is replaced
+	stale := facts[0]
+	stale.Content = "stale"
+	seedFacts(t, store.Facts(), stale)
+
+	copied, skipped, err := CopyFacts(ctx, store.Facts(), from)
+	if err != nil || copied != 3 || skipped != 2 {
+		t.Fatalf("CopyFacts() = %d copied, %d skipped, %v, want 3 copied and 2 duplicates skipped", copied, skipped, err)
+	}
+	got, _ := store.Facts().Get(ctx, facts[0].ID)
+	if got.Content != "first" || got.Metadata.ServeCount != 4 {
+		t.Errorf("copied fact = %q served %d times, want the source fact", got.Content, got.Metadata.ServeCount)
+	}
+
+	copied, err = CopyChats(ctx, store.Chats(), fromChats)
+	if err != nil || copied != 1 {
+		t.Fatalf("CopyChats() = %d, %v, want 1 session", copied, err)
+	}
+	if got, _ := store.Chats().GetSession(ctx, session.ID); !got.UpdatedAt.Equal(updated) {
+		t.Errorf("copied session updated at %v, want %v", got.UpdatedAt, updated)
+	}
+}
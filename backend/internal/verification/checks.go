@@ -0,0 +1,90 @@
+package verification
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+)
+
+// Check is a deterministic consistency check between a claim and its source
+type Check func(claim, source string) models.CheckResult
+
+// DefaultChecks are the deterministic checks run before the judge
+var DefaultChecks = []Check{CheckNumbers, CheckDates}
+
+// CheckNumbers fails if the claim contains a number the source does not
+func CheckNumbers(claim, source string) models.CheckResult {
+	result := models.CheckResult{Name: "numbers", Passed: true}
+
+	sourceNumbers := make(map[string]bool)
+	for _, n := range processors.ExtractNumbers(source) {
+		sourceNumbers[n] = true
+	}
+
+	var missing []string
+	for _, n := range processors.ExtractNumbers(claim) {
+		if !sourceNumbers[n] {
+			missing = append(missing, n)
+		}
+	}
+
+	if len(missing) > 0 {
+		result.Passed = false
+		result.Detail = fmt.Sprintf("numbers not in source: %s", strings.Join(missing, ", "))
+	}
+	return result
+}
+
+const months = `(January|February|March|April|May|June|July|August|September|October|November|December)`
+
+var (
+	// "12 March 1950" and "12 March"
+	dayMonthPattern = regexp.MustCompile(`\b(\d{1,2})\s+` + months + `\b`)
+	// "March 12, 1950" and "March 12"
+	monthDayPattern = regexp.MustCompile(`\b` + months + `\s+(\d{1,2})\b`)
+	// "March 1950"
+	monthYearPattern = regexp.MustCompile(`\b` + months + `\s+(\d{3,4})\b`)
+)
+
+// extractDates returns the day-month and month-year dates mentioned in a text,
+// normalized so that "12 March" and "March 12" compare equal
+func extractDates(text string) []string {
+	var dates []string
+	for _, m := range dayMonthPattern.FindAllStringSubmatch(text, -1) {
+		dates = append(dates, strings.TrimLeft(m[1], "0")+" "+m[2])
+	}
+	for _, m := range monthDayPattern.FindAllStringSubmatch(text, -1) {
+		dates = append(dates, strings.TrimLeft(m[2], "0")+" "+m[1])
+	}
+	for _, m := range monthYearPattern.FindAllStringSubmatch(text, -1) {
+		dates = append(dates, m[1]+" "+m[2])
+	}
+	return dates
+}
+
+// CheckDates fails if the claim mentions a date the source does not. Bare
+// years are covered by CheckNumbers.
+func CheckDates(claim, source string) models.CheckResult {
+	result := models.CheckResult{Name: "dates", Passed: true}
+
+	sourceDates := make(map[string]bool)
+	for _, d := range extractDates(source) {
+		sourceDates[d] = true
+	}
+
+	var missing []string
+	for _, d := range extractDates(claim) {
+		if !sourceDates[d] {
+			missing = append(missing, d)
+		}
+	}
+
+	if len(missing) > 0 {
+		result.Passed = false
+		result.Detail = fmt.Sprintf("dates not in source: %s", strings.Join(missing, ", "))
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+)
+
+// DefaultMinConfidence is the judge confidence a supported verdict needs to pass
+const DefaultMinConfidence = 0.8
+
+// Judge verdicts
+const (
+	VerdictSupported    = "supported"
+	VerdictContradicted = "contradicted"
+	VerdictUnsupported  = "unsupported"
+)
+
+const judgeSystemPrompt = "You are a fact checker for the 'One Fact' app. You are given a SOURCE text and a CLAIM. " +
+	"Decide whether the claim is fully supported by the source alone, without using outside knowledge.\n\n" +
+	"Reply with a JSON object and nothing else:\n" +
+	"{\"verdict\": \"supported\" | \"contradicted\" | \"unsupported\", " +
+	"\"confidence\": <number between 0 and 1>, " +
+	"\"rationale\": \"<one sentence explaining the verdict>\"}\n\n" +
+	"Use \"contradicted\" if the source says something different and \"unsupported\" if the source does not mention it."
+
+// JudgeVerdict is the structured answer of the LLM judge
+type JudgeVerdict struct {
+	Verdict    string  `json:"verdict"`
+	Confidence float64 `json:"confidence"`
+	Rationale  string  `json:"rationale"`
+}
+
+// Verifier checks claims against their source text. Deterministic checks run
+// first; claims that pass them are sent to the LLM judge if one is configured.
+type Verifier struct {
+	judge         processors.Completer
+	checks        []Check
+	textRules     []processors.TextRule
+	minConfidence float64
+}
+
+// NewVerifier creates a verifier. The judge may be nil, in which case every
+// claim is left for an editor.
+func NewVerifier(judge processors.Completer) *Verifier {
+	return &Verifier{
+		judge:         judge,
+		checks:        DefaultChecks,
+		minConfidence: DefaultMinConfidence,
+	}
+}
+
+// SetTextRules sets the cleanup rules the processor applies to collected
+// text, so a claim that is only the cleaned up source is recognised as the
+// source itself
+func (v *Verifier) SetTextRules(rules []processors.TextRule) {
+	v.textRules = rules
+}
+
+// Verify checks a claim against its source text
+func (v *Verifier) Verify(ctx context.Context, claim, source string) models.Verification {
+	result := models.Verification{CheckedAt: time.Now()}
+
+	// A claim is only checked against a source other than itself, so the
+	// unedited extract it was collected as, cleaned up or not, is left for an
+	// editor
+	if strings.TrimSpace(source) == "" {
+		result.Status = models.VerificationUncertain
+		result.Rationale = "no source text to check against"
+		return result
+	}
+	if normalizeSpace(claim) == normalizeSpace(processors.NormalizeText(source, v.textRules)) ||
+		normalizeSpace(claim) == normalizeSpace(source) {
+		result.Status = models.VerificationUncertain
+		result.Method = models.VerificationMethodVerbatim
+		result.Rationale = "claim is the source text itself, so nothing independent supports it"
+		return result
+	}
+
+	result.Method = models.VerificationMethodChecks
+	for _, check := range v.checks {
+		outcome := check(claim, source)
+		result.Checks = append(result.Checks, outcome)
+		if !outcome.Passed {
+			result.Status = models.VerificationFailed
+			result.Confidence = 1
+			result.Rationale = outcome.Detail
+		}
+	}
+	if result.Status == models.VerificationFailed {
+		return result
+	}
+
+	if v.judge == nil {
+		result.Status = models.VerificationUncertain
+		result.Rationale = "no judge configured"
+		return result
+	}
+
+	result.Method = models.VerificationMethodJudge
+	verdict, err := v.askJudge(ctx, claim, source)
+	if err != nil {
+		result.Status = models.VerificationUncertain
+		result.Rationale = fmt.Sprintf("judge failed: %v", err)
+		return result
+	}
+
+	result.Confidence = verdict.Confidence
+	result.Rationale = verdict.Rationale
+	switch {
+	case verdict.Verdict == VerdictSupported && verdict.Confidence >= v.minConfidence:
+		result.Status = models.VerificationPassed
+	case verdict.Verdict == VerdictSupported:
+		result.Status = models.VerificationUncertain
+	default:
+		result.Status = models.VerificationFailed
+	}
+	return result
+}
+
+func (v *Verifier) askJudge(ctx context.Context, claim, source string) (*JudgeVerdict, error) {
+	prompt := fmt.Sprintf("SOURCE:\n%s\n\nCLAIM:\n%s", source, claim)
+	reply, err := v.judge.Complete(ctx, judgeSystemPrompt, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return ParseVerdict(reply)
+}
+
+// ParseVerdict parses the judge's JSON reply, tolerating a surrounding code fence
+func ParseVerdict(reply string) (*JudgeVerdict, error) {
+	reply = strings.TrimSpace(reply)
+	if start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}"); start >= 0 && end > start {
+		reply = reply[start : end+1]
+	}
+
+	var verdict JudgeVerdict
+	if err := json.Unmarshal([]byte(reply), &verdict); err != nil {
+		return nil, fmt.Errorf("parsing verdict: %w", err)
+	}
+
+	switch verdict.Verdict {
+	case VerdictSupported, VerdictContradicted, VerdictUnsupported:
+	default:
+		return nil, fmt.Errorf("unknown verdict %q", verdict.Verdict)
+	}
+	if verdict.Confidence < 0 || verdict.Confidence > 1 {
+		return nil, fmt.Errorf("confidence %v out of range", verdict.Confidence)
+	}
+
+	return &verdict, nil
+}
+
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Stage verifies processed facts against their source text. Only facts that
+// pass are marked verified; the rest are held for review.
+type Stage struct {
+	verifier *Verifier
+}
+
+// NewStage creates a verification stage
+func NewStage(verifier *Verifier) *Stage {
+	return &Stage{verifier: verifier}
+}
+
+// Name returns the stage name
+func (s *Stage) Name() string {
+	return "verification"
+}
+
+// Apply verifies the fact content against the extract it was collected from
+func (s *Stage) Apply(ctx context.Context, fact *models.Fact) error {
+	result := s.verifier.Verify(ctx, fact.Content, fact.Metadata.OriginalContent)
+	fact.Verification = &result
+
+	if result.Status != models.VerificationPassed {
+		fact.SendToReview(fmt.Sprintf("verification %s: %s", result.Status, result.Rationale))
+		return nil
+	}
+
+	// Facts held back by an earlier stage stay unverified
+	fact.Verified = !fact.NeedsReview
+	return nil
+}
@@ -0,0 +1,136 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ZigaoWang/one-fact-app/backend/internal/collectors"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/models"
+	"github.com/ZigaoWang/one-fact-app/backend/internal/processors"
+)
+
+const source = "Marie Curie was born on 7 November 1867 in Warsaw. She won the Nobel Prize in Physics in 1903 and in Chemistry in 1911."
+
+type fakeJudge struct {
+	reply string
+	err   error
+}
+
+func (f fakeJudge) Complete(ctx context.Context, system, prompt string) (string, error) {
+	return f.reply, f.err
+}
+
+func TestVerifyVerbatim(t *testing.T) {
+	// A claim is not evidence for itself, even with a judge that would agree
+	judge := fakeJudge{reply: `{"verdict": "supported", "confidence": 1, "rationale": "ok"}`}
+	result := NewVerifier(judge).Verify(context.Background(), source, " "+source)
+	if result.Status != models.VerificationUncertain || result.Method != models.VerificationMethodVerbatim {
+		t.Errorf("Expected the source compared with itself to stay uncertain, got %+v", result)
+	}
+
+	result = NewVerifier(judge).Verify(context.Background(), source, "")
+	if result.Status != models.VerificationUncertain {
+		t.Errorf("Expected a claim without source to stay uncertain, got %+v", result)
+	}
+}
+
+func TestVerifyDeterministicChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		claim string
+	}{
+		{"wrong year", "Marie Curie won her first Nobel Prize in 1904."},
+		{"wrong date", "Marie Curie was born on November 8 in Warsaw."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The judge would pass the claim, but the checks fail first
+			verifier := NewVerifier(fakeJudge{reply: `{"verdict": "supported", "confidence": 1, "rationale": "ok"}`})
+			result := verifier.Verify(context.Background(), tt.claim, source)
+			if result.Status != models.VerificationFailed || result.Method != models.VerificationMethodChecks {
+				t.Errorf("Expected checks to fail, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestVerifyJudge(t *testing.T) {
+	claim := "Marie Curie, born in Warsaw on November 7, won Nobel Prizes in 1903 and 1911."
+
+	tests := []struct {
+		name   string
+		judge  processors.Completer
+		status string
+	}{
+		{"no judge", nil, models.VerificationUncertain},
+		{"supported", fakeJudge{reply: "```json\n{\"verdict\": \"supported\", \"confidence\": 0.95, \"rationale\": \"stated in source\"}\n```"}, models.VerificationPassed},
+		{"low confidence", fakeJudge{reply: `{"verdict": "supported", "confidence": 0.5, "rationale": "probably"}`}, models.VerificationUncertain},
+		{"contradicted", fakeJudge{reply: `{"verdict": "contradicted", "confidence": 0.9, "rationale": "wrong prize"}`}, models.VerificationFailed},
+		{"malformed", fakeJudge{reply: "yes"}, models.VerificationUncertain},
+		{"error", fakeJudge{err: errors.New("unavailable")}, models.VerificationUncertain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewVerifier(tt.judge).Verify(context.Background(), claim, source)
+			if result.Status != tt.status {
+				t.Errorf("Expected status %s, got %+v", tt.status, result)
+			}
+		})
+	}
+}
+
+func TestStageHoldsUnverifiedFacts(t *testing.T) {
+	fact := &models.Fact{
+		Content:  "Marie Curie won her first Nobel Prize in 1904.",
+		Metadata: models.FactMetadata{OriginalContent: source},
+	}
+
+	if err := NewStage(NewVerifier(nil)).Apply(context.Background(), fact); err != nil {
+		t.Fatalf("Error applying stage: %v", err)
+	}
+
+	if fact.Verified || !fact.NeedsReview || fact.Verification == nil {
+		t.Errorf("Expected fact to be held for review with a verdict, got %+v", fact)
+	}
+}
+
+func TestStageLeavesUneditedFactsToEditors(t *testing.T) {
+	processor := processors.NewProcessor()
+	processor.Use(NewStage(NewVerifier(nil)))
+
+	fact, err := processor.Process(context.Background(), collectors.RawFact{Content: source, Category: "Science"})
+	if err != nil || fact == nil {
+		t.Fatalf("Process() = %v, %v", fact, err)
+	}
+	if fact.Metadata.OriginalContent != source {
+		t.Errorf("OriginalContent = %q, want the collected text", fact.Metadata.OriginalContent)
+	}
+	if fact.Verified || !fact.NeedsReview {
+		t.Errorf("Expected the unedited extract to be held for review, got %+v", fact)
+	}
+}
+
+func TestStageLeavesCleanedUpFactsToEditors(t *testing.T) {
+	// Cleanup drops the birth date aside, so the content differs from the
+	// collected text but is still nothing but that text
+	collected := "Marie Curie (born 7 November 1867) won the Nobel Prize in Physics in 1903 and in Chemistry in 1911."
+	verifier := NewVerifier(fakeJudge{reply: `{"verdict": "supported", "confidence": 1, "rationale": "ok"}`})
+	verifier.SetTextRules(processors.DefaultTextRules)
+	processor := processors.NewProcessor()
+	processor.SetTextRules(processors.DefaultTextRules)
+	processor.Use(NewStage(verifier))
+
+	fact, err := processor.Process(context.Background(), collectors.RawFact{Content: collected, Category: "Science"})
+	if err != nil || fact == nil {
+		t.Fatalf("Process() = %v, %v", fact, err)
+	}
+	if fact.Content == collected {
+		t.Fatalf("Content = %q, want the birth date removed", fact.Content)
+	}
+	if fact.Verified || fact.Verification.Method != models.VerificationMethodVerbatim {
+		t.Errorf("Expected the cleaned up extract to be held for review as verbatim, got %+v", fact.Verification)
+	}
+}
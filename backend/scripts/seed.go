@@ -13,63 +13,69 @@ import (
 
 var sampleFacts = []models.Fact{
 	{
-		ID:        primitive.NewObjectID(),
-		Content:   "The first computer programmer was a woman named Ada Lovelace. She wrote the first algorithm intended to be processed by a machine in the 1840s.",
-		Category:  "Technology",
-		Source:    "Computer History Museum",
-		Tags:      []string{"programming", "history", "women in tech"},
-		Verified:  true,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            primitive.NewObjectID(),
+		SchemaVersion: models.CurrentFactSchemaVersion,
+		Content:       "The first computer programmer was a woman named Ada Lovelace. She wrote the first algorithm intended to be processed by a machine in the 1840s.",
+		Category:      "Technology",
+		Source:        "Computer History Museum",
+		Tags:          []string{"programming", "history", "women in tech"},
+		Verified:      true,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 		RelatedURLs: []string{
 			"https://www.computerhistory.org/babbage/adalovelace/",
 		},
 		Metadata: models.FactMetadata{
-			Language:   "English",
-			Difficulty: "Easy",
-			Keywords:   []string{"Ada Lovelace", "programming", "computer history"},
-			References: []string{"Computer History Museum Archives"},
-			Popularity: 100,
+			Language:         "English",
+			Difficulty:       "Easy",
+			DifficultySource: models.DifficultySourceEditor,
+			Keywords:         []string{"Ada Lovelace", "programming", "computer history"},
+			References:       []string{"Computer History Museum Archives"},
+			Popularity:       100,
 		},
 	},
 	{
-		ID:        primitive.NewObjectID(),
-		Content:   "The Great Wall of China is not visible from space with the naked eye, contrary to popular belief. This myth has been debunked by many astronauts.",
-		Category:  "History",
-		Source:    "NASA",
-		Tags:      []string{"architecture", "space", "myths"},
-		Verified:  true,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            primitive.NewObjectID(),
+		SchemaVersion: models.CurrentFactSchemaVersion,
+		Content:       "The Great Wall of China is not visible from space with the naked eye, contrary to popular belief. This myth has been debunked by many astronauts.",
+		Category:      "History",
+		Source:        "NASA",
+		Tags:          []string{"architecture", "space", "myths"},
+		Verified:      true,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 		RelatedURLs: []string{
 			"https://www.nasa.gov/vision/space/workinginspace/great_wall.html",
 		},
 		Metadata: models.FactMetadata{
-			Language:   "English",
-			Difficulty: "Medium",
-			Keywords:   []string{"Great Wall", "China", "space"},
-			References: []string{"NASA Reports", "Astronaut Observations"},
-			Popularity: 95,
+			Language:         "English",
+			Difficulty:       "Medium",
+			DifficultySource: models.DifficultySourceEditor,
+			Keywords:         []string{"Great Wall", "China", "space"},
+			References:       []string{"NASA Reports", "Astronaut Observations"},
+			Popularity:       95,
 		},
 	},
 	{
-		ID:        primitive.NewObjectID(),
-		Content:   "Quantum computers use quantum bits or 'qubits' that can exist in multiple states simultaneously, unlike classical bits that are either 0 or 1.",
-		Category:  "Technology",
-		Source:    "IBM Quantum Computing",
-		Tags:      []string{"quantum", "computing", "physics"},
-		Verified:  true,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            primitive.NewObjectID(),
+		SchemaVersion: models.CurrentFactSchemaVersion,
+		Content:       "Quantum computers use quantum bits or 'qubits' that can exist in multiple states simultaneously, unlike classical bits that are either 0 or 1.",
+		Category:      "Technology",
+		Source:        "IBM Quantum Computing",
+		Tags:          []string{"quantum", "computing", "physics"},
+		Verified:      true,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 		RelatedURLs: []string{
 			"https://www.ibm.com/quantum-computing/",
 		},
 		Metadata: models.FactMetadata{
-			Language:   "English",
-			Difficulty: "Hard",
-			Keywords:   []string{"quantum computing", "qubits", "technology"},
-			References: []string{"IBM Research Papers", "Quantum Computing Basics"},
-			Popularity: 90,
+			Language:         "English",
+			Difficulty:       "Hard",
+			DifficultySource: models.DifficultySourceEditor,
+			Keywords:         []string{"quantum computing", "qubits", "technology"},
+			References:       []string{"IBM Research Papers", "Quantum Computing Basics"},
+			Popularity:       90,
 		},
 	},
 }